@@ -5,11 +5,27 @@ import (
 	"fmt"
 	"image/color"
 	"os"
+	"unicode"
 
 	"gopkg.in/yaml.v2"
 
 	"github.com/bjatkin/flappy_boot/cmd/image_gen/internal/gba/gbacol"
+	"github.com/bjatkin/flappy_boot/cmd/image_gen/internal/gba/pcm"
 	"github.com/bjatkin/flappy_boot/cmd/image_gen/internal/gba/tile"
+	"github.com/bjatkin/flappy_boot/cmd/image_gen/internal/wav"
+)
+
+const (
+	// DefaultSampleRate is the sample rate sounds are converted to if no rate is set. It matches the
+	// engines mixer so sounds at this rate are played back without being resampled
+	DefaultSampleRate = 18157
+
+	// MinSampleRate and MaxSampleRate are the lowest and highest sample rates a sound can be converted to
+	MinSampleRate = 4000
+	MaxSampleRate = 32768
+
+	// MaxSoundLength is the most samples a converted sound can have
+	MaxSoundLength = 1 << 18
 )
 
 // Config is a config file for the command
@@ -17,6 +33,8 @@ type Config struct {
 	Palettes       []Palette `yaml:"Paletts"`
 	TileSets       []TileSet `yaml:"TileSets"`
 	TileMaps       []TileMap `yaml:"TileMaps"`
+	Fonts          []Font    `yaml:"Fonts"`
+	Sounds         []Sound   `yaml:"Sounds"`
 	OutDir         string    `yaml:"OutDir"`
 	SetTransparent string    `yaml:"SetTransparent"`
 }
@@ -37,6 +55,7 @@ type TileSet struct {
 	Size        string `yaml:"Size"`
 	Description string `yaml:"Description"`
 	Transparent string `yaml:"Transparent"`
+	Ordered     bool   `yaml:"Ordered"`
 }
 
 // TileMap is a named tile map
@@ -49,6 +68,45 @@ type TileMap struct {
 	Transparent string `yaml:"Transparent"`
 }
 
+// Font is a named 1 bit per pixel font with 8x8 glyphs
+type Font struct {
+	Name        string `yaml:"Name"`
+	File        string `yaml:"File"`
+	Chars       string `yaml:"Chars"`
+	Description string `yaml:"Description"`
+	Transparent string `yaml:"Transparent"`
+}
+
+// Sound is a named 8 bit PCM sound that is converted from a WAV file
+type Sound struct {
+	Name        string `yaml:"Name"`
+	File        string `yaml:"File"`
+	Rate        int    `yaml:"Rate"`
+	Volume      int    `yaml:"Volume"`
+	Loop        bool   `yaml:"Loop"`
+	LoopStart   int    `yaml:"LoopStart"`
+	LoopEnd     int    `yaml:"LoopEnd"`
+	Description string `yaml:"Description"`
+}
+
+// SampleRate returns the sample rate the sound is converted to
+func (s Sound) SampleRate() int {
+	if s.Rate == 0 {
+		return DefaultSampleRate
+	}
+
+	return s.Rate
+}
+
+// Scale returns the sounds volume as a fraction, the volume is a percentage and defaults to 100
+func (s Sound) Scale() float64 {
+	if s.Volume == 0 {
+		return 1
+	}
+
+	return float64(s.Volume) / 100
+}
+
 // NewConfigFromFile reads in the yaml file at the provided file location and then marshalls it into a new config struct
 func NewConfigFromFile(file string) (*Config, error) {
 	raw, err := os.ReadFile(file)
@@ -147,6 +205,35 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	for _, font := range c.Fonts {
+		if font.Chars == "" {
+			return fmt.Errorf("font %s must have at least one char", font.Name)
+		}
+
+		seen := make(map[rune]bool)
+		for _, r := range font.Chars {
+			if r > unicode.MaxASCII {
+				return fmt.Errorf("font %s char %q is not an ascii char", font.Name, r)
+			}
+			if seen[r] {
+				return fmt.Errorf("font %s char %q is used more than once", font.Name, r)
+			}
+			seen[r] = true
+		}
+
+		err := validateColor(font.Transparent)
+		if err != nil {
+			return fmt.Errorf("could not validate transparent color %s | %w", font.Transparent, err)
+		}
+	}
+
+	for _, sound := range c.Sounds {
+		err := validateSound(sound)
+		if err != nil {
+			return fmt.Errorf("invalid sound %s | %w", sound.Name, err)
+		}
+	}
+
 	err := validateColor(c.SetTransparent)
 	if err != nil {
 		return err
@@ -155,6 +242,57 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// validateSound checks the sounds settings and reads the sounds file to make sure it will fit once it's converted
+func validateSound(sound Sound) error {
+	rate := sound.SampleRate()
+	if rate < MinSampleRate || rate > MaxSampleRate {
+		return fmt.Errorf("sample rate %d must be between %d and %d", rate, MinSampleRate, MaxSampleRate)
+	}
+
+	if sound.Volume < 0 {
+		return fmt.Errorf("volume %d can not be negative", sound.Volume)
+	}
+
+	if !sound.Loop && (sound.LoopStart != 0 || sound.LoopEnd != 0) {
+		return errors.New("loop points can only be set on looping sounds")
+	}
+
+	if sound.LoopStart < 0 || sound.LoopEnd < 0 {
+		return fmt.Errorf("loop points %d - %d can not be negative", sound.LoopStart, sound.LoopEnd)
+	}
+
+	if sound.LoopEnd != 0 && sound.LoopEnd <= sound.LoopStart {
+		return fmt.Errorf("loop end %d must be after loop start %d", sound.LoopEnd, sound.LoopStart)
+	}
+
+	f, err := os.Open(sound.File)
+	if err != nil {
+		return fmt.Errorf("failed to read sound file %w", err)
+	}
+	defer f.Close()
+
+	wavSound, err := wav.Decode(f)
+	if err != nil {
+		return fmt.Errorf("failed to decode sound file %s | %w", sound.File, err)
+	}
+
+	samples := len(wavSound.Samples)
+	if samples == 0 {
+		return fmt.Errorf("sound file %s has no samples", sound.File)
+	}
+
+	if sound.LoopStart >= samples || sound.LoopEnd > samples {
+		return fmt.Errorf("loop points %d - %d are past the end of the sound (%d samples)", sound.LoopStart, sound.LoopEnd, samples)
+	}
+
+	length := pcm.Length(samples, wavSound.Rate, rate)
+	if length > MaxSoundLength {
+		return fmt.Errorf("sound is %d samples at %dHz, sounds can be at most %d samples", length, rate, MaxSoundLength)
+	}
+
+	return nil
+}
+
 func validateColor(hex string) error {
 	if hex == "" {
 		return nil
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeWAV writes an 8 bit mono WAV file with n silent samples and returns it's path
+func writeWAV(t *testing.T, file string, rate, n int) string {
+	data := make([]byte, 44+n)
+	copy(data[0:], "RIFF")
+	binary.LittleEndian.PutUint32(data[4:], uint32(36+n))
+	copy(data[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(data[16:], 16)
+	binary.LittleEndian.PutUint16(data[20:], 1)
+	binary.LittleEndian.PutUint16(data[22:], 1)
+	binary.LittleEndian.PutUint32(data[24:], uint32(rate))
+	binary.LittleEndian.PutUint32(data[28:], uint32(rate))
+	binary.LittleEndian.PutUint16(data[32:], 1)
+	binary.LittleEndian.PutUint16(data[34:], 8)
+	copy(data[36:], "data")
+	binary.LittleEndian.PutUint32(data[40:], uint32(n))
+	for i := 44; i < len(data); i++ {
+		data[i] = 128
+	}
+
+	if err := os.WriteFile(file, data, 0o0666); err != nil {
+		t.Fatalf("failed to write wav file %v", err)
+	}
+
+	return file
+}
+
+func TestConfig_Validate_sounds(t *testing.T) {
+	dir := t.TempDir()
+	short := writeWAV(t, filepath.Join(dir, "short.wav"), 8000, 100)
+	long := writeWAV(t, filepath.Join(dir, "long.wav"), 8000, MaxSoundLength)
+
+	tests := []struct {
+		name    string
+		sound   Sound
+		wantErr bool
+	}{
+		{"default rate", Sound{Name: "a", File: short}, false},
+		{"rate set", Sound{Name: "a", File: short, Rate: 16000}, false},
+		{"rate too low", Sound{Name: "a", File: short, Rate: 100}, true},
+		{"rate too high", Sound{Name: "a", File: short, Rate: 44100}, true},
+		{"negative volume", Sound{Name: "a", File: short, Volume: -10}, true},
+		{"loop", Sound{Name: "a", File: short, Loop: true, LoopStart: 10, LoopEnd: 90}, false},
+		{"loop points without loop", Sound{Name: "a", File: short, LoopStart: 10}, true},
+		{"loop end before start", Sound{Name: "a", File: short, Loop: true, LoopStart: 50, LoopEnd: 20}, true},
+		{"loop past the end", Sound{Name: "a", File: short, Loop: true, LoopEnd: 200}, true},
+		{"missing file", Sound{Name: "a", File: filepath.Join(dir, "missing.wav")}, true},
+		{"too long", Sound{Name: "a", File: long, Rate: 16000}, true},
+		{"long but downsampled", Sound{Name: "a", File: long, Rate: 4000}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{OutDir: dir, Sounds: []Sound{tt.sound}}
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
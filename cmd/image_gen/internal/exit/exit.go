@@ -12,6 +12,8 @@ const (
 	InvalidPalette
 	InvalidTileSet
 	InvalidTileMap
+	InvalidFont
+	InvalidSound
 	FileWriteFailed
 )
 
@@ -0,0 +1,74 @@
+package pcm
+
+import "math"
+
+// Resample converts samples recorded at the from sample rate into samples at the to sample rate.
+// When the rate is lowered each new sample is the average of the samples it covers so high
+// frequencies that can't be played at the new rate are filtered out. When the rate is raised
+// the samples are linearly interpolated
+func Resample(samples []float64, from, to int) []float64 {
+	if from == to || len(samples) == 0 {
+		return append([]float64{}, samples...)
+	}
+
+	out := make([]float64, Length(len(samples), from, to))
+	step := float64(from) / float64(to)
+	for i := range out {
+		start := float64(i) * step
+		if step <= 1 {
+			out[i] = lerp(samples, start)
+			continue
+		}
+
+		// average every sample that starts inside the window
+		first := int(math.Ceil(start))
+		last := int(math.Ceil(start + step))
+		if last > len(samples) {
+			last = len(samples)
+		}
+
+		var sum float64
+		for j := first; j < last; j++ {
+			sum += samples[j]
+		}
+		if last > first {
+			out[i] = sum / float64(last-first)
+		}
+	}
+
+	return out
+}
+
+// Length returns the number of samples a sound with n samples at the from sample rate will have at the to sample rate
+func Length(n, from, to int) int {
+	return int(int64(n) * int64(to) / int64(from))
+}
+
+// lerp returns the linearly interpolated sample at the fractional position pos
+func lerp(samples []float64, pos float64) float64 {
+	i := int(pos)
+	if i+1 >= len(samples) {
+		return samples[len(samples)-1]
+	}
+
+	frac := pos - float64(i)
+	return samples[i]*(1-frac) + samples[i+1]*frac
+}
+
+// Quantize converts samples between -1 and 1 into signed 8 bit PCM samples, the samples are scaled by volume
+// first. Samples outside of the 8 bit range are clipped
+func Quantize(samples []float64, volume float64) []int8 {
+	out := make([]int8, len(samples))
+	for i, s := range samples {
+		v := math.Round(s * volume * 127)
+		if v > 127 {
+			v = 127
+		}
+		if v < -128 {
+			v = -128
+		}
+		out[i] = int8(v)
+	}
+
+	return out
+}
@@ -0,0 +1,87 @@
+package pcm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResample(t *testing.T) {
+	type args struct {
+		samples []float64
+		from    int
+		to      int
+	}
+	tests := []struct {
+		name string
+		args args
+		want []float64
+	}{
+		{
+			"same rate",
+			args{[]float64{0, 0.5, 1}, 8000, 8000},
+			[]float64{0, 0.5, 1},
+		},
+		{
+			"half rate",
+			args{[]float64{0, 0.5, 1, 0, -1, -0.5}, 16000, 8000},
+			[]float64{0.25, 0.5, -0.75},
+		},
+		{
+			"double rate",
+			args{[]float64{0, 1, 0}, 8000, 16000},
+			[]float64{0, 0.5, 1, 0.5, 0, 0},
+		},
+		{
+			"empty",
+			args{nil, 8000, 16000},
+			[]float64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Resample(tt.args.samples, tt.args.from, tt.args.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resample() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		from, to int
+		want     int
+	}{
+		{"same rate", 100, 8000, 8000, 100},
+		{"engine rate", 22050, 22050, 18157, 18157},
+		{"double rate", 3, 8000, 16000, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Length(tt.n, tt.from, tt.to); got != tt.want {
+				t.Errorf("Length() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuantize(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []float64
+		volume  float64
+		want    []int8
+	}{
+		{"full volume", []float64{0, 1, -1, 0.5}, 1, []int8{0, 127, -127, 64}},
+		{"half volume", []float64{1, -1}, 0.5, []int8{64, -64}},
+		{"clipped", []float64{1, -1}, 2, []int8{127, -128}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Quantize(tt.samples, tt.volume); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Quantize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
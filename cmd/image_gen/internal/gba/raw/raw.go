@@ -129,3 +129,40 @@ func getIndex(i, dx, dy, pitch int) int {
 func paddedPitch(dx int) int {
 	return ((255 + dx) / 256) * 32
 }
+
+// Glyphs converts the first count 8x8 glyphs in the image into a raw byte slice for a .fnt font.
+// glyphs are read from left to right and then top to bottom. Each glyph is 8 bytes, one byte per row,
+// with the left most pixel in the lowest bit. Pixels that match the transparent color, or that are
+// completely transparent, are left unset
+func Glyphs(m image.Image, count int, transparent *gbacol.RGB15) ([]byte, error) {
+	bounds := m.Bounds()
+	if bounds.Dx()%8 != 0 || bounds.Dy()%8 != 0 {
+		return nil, fmt.Errorf("font image size must be a multiple of 8 but it's %dx%d", bounds.Dx(), bounds.Dy())
+	}
+
+	columns := bounds.Dx() / 8
+	if cells := columns * (bounds.Dy() / 8); count > cells {
+		return nil, fmt.Errorf("font image only has %d glyphs but %d are needed", cells, count)
+	}
+
+	raw := make([]byte, count*8)
+	for i := 0; i < count; i++ {
+		glyphX := bounds.Min.X + (i%columns)*8
+		glyphY := bounds.Min.Y + (i/columns)*8
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 8; x++ {
+				c := m.At(glyphX+x, glyphY+y)
+				if _, _, _, a := c.RGBA(); a == 0 {
+					continue
+				}
+				if transparent != nil && gbacol.NewRGB15(c) == *transparent {
+					continue
+				}
+
+				raw[i*8+y] |= 1 << x
+			}
+		}
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,114 @@
+package raw
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/cmd/image_gen/internal/gba/gbacol"
+)
+
+var (
+	white = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	black = color.RGBA{0x00, 0x00, 0x00, 0xFF}
+)
+
+// newGlyphImage creates a white image where the given pixels are set to black
+func newGlyphImage(width, height int, pixels []image.Point) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, white)
+		}
+	}
+
+	for _, p := range pixels {
+		img.Set(p.X, p.Y, black)
+	}
+
+	return img
+}
+
+func TestGlyphs(t *testing.T) {
+	whiteRGB15 := gbacol.NewRGB15(white)
+
+	type args struct {
+		m           image.Image
+		count       int
+		transparent *gbacol.RGB15
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []byte
+		wantErr bool
+	}{
+		{
+			"single glyph",
+			args{
+				newGlyphImage(8, 8, []image.Point{{0, 0}, {7, 0}, {3, 7}}),
+				1,
+				&whiteRGB15,
+			},
+			[]byte{0x81, 0, 0, 0, 0, 0, 0, 0x08},
+			false,
+		},
+		{
+			"glyphs wrap to the next row",
+			args{
+				newGlyphImage(16, 16, []image.Point{{1, 0}, {9, 1}, {2, 8}}),
+				3,
+				&whiteRGB15,
+			},
+			[]byte{
+				0x02, 0, 0, 0, 0, 0, 0, 0,
+				0, 0x02, 0, 0, 0, 0, 0, 0,
+				0x04, 0, 0, 0, 0, 0, 0, 0,
+			},
+			false,
+		},
+		{
+			"transparent pixels",
+			args{
+				image.NewRGBA(image.Rect(0, 0, 8, 8)),
+				1,
+				nil,
+			},
+			[]byte{0, 0, 0, 0, 0, 0, 0, 0},
+			false,
+		},
+		{
+			"invalid image size",
+			args{
+				newGlyphImage(10, 8, nil),
+				1,
+				&whiteRGB15,
+			},
+			nil,
+			true,
+		},
+		{
+			"too many glyphs",
+			args{
+				newGlyphImage(16, 8, nil),
+				3,
+				&whiteRGB15,
+			},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Glyphs(tt.args.m, tt.args.count, tt.args.transparent)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Glyphs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Glyphs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -317,3 +317,21 @@ func Unique(tiles []*Meta) []*Meta {
 
 	return uniqueTiles
 }
+
+// UniqueOrdered returns only the unique tiles in the slice of meta tiles in the order they are first found.
+// Like Unique, tiles that are mirrors of one another are not considered to be unique from each other
+func UniqueOrdered(tiles []*Meta) []*Meta {
+	seen := make(map[[md5.Size]byte]bool)
+	var uniqueTiles []*Meta
+	for _, tile := range tiles {
+		hash := tile.Hash()
+		if seen[hash] {
+			continue
+		}
+
+		seen[hash] = true
+		uniqueTiles = append(uniqueTiles, tile)
+	}
+
+	return uniqueTiles
+}
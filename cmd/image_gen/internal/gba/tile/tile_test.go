@@ -32,6 +32,16 @@ func newImage(width, height int, pixels []color.Color) image.Image {
 	return img
 }
 
+// solidImage creates an 8x8 image that is a single color
+func solidImage(c color.Color) image.Image {
+	pixels := make([]color.Color, 64)
+	for i := range pixels {
+		pixels[i] = c
+	}
+
+	return newImage(8, 8, pixels)
+}
+
 func TestNewMeta(t *testing.T) {
 	img8x8 := newImage(8, 8, []color.Color{
 		red, white, red, white, red, white, red, white,
@@ -243,6 +253,64 @@ func TestUnique(t *testing.T) {
 	}
 }
 
+func TestUniqueOrdered(t *testing.T) {
+	pal := color.Palette{red, green, blue, white}
+
+	imgA := newImage(8, 8, []color.Color{
+		red, green, red, green, red, green, red, green,
+		blue, white, blue, white, blue, white, blue, white,
+		red, green, red, green, red, green, red, green,
+		blue, white, blue, white, blue, white, blue, white,
+		red, green, red, green, red, green, red, green,
+		blue, white, blue, white, blue, white, blue, white,
+		red, green, red, green, red, green, red, green,
+		blue, white, blue, white, blue, white, blue, white,
+	})
+
+	tests := []struct {
+		name  string
+		tiles []*Meta
+		want  []*Meta
+	}{
+		{
+			"first found order",
+			[]*Meta{
+				NewMeta(solidImage(white), pal, S8x8),
+				NewMeta(solidImage(red), pal, S8x8),
+				NewMeta(solidImage(blue), pal, S8x8),
+			},
+			[]*Meta{
+				NewMeta(solidImage(white), pal, S8x8),
+				NewMeta(solidImage(red), pal, S8x8),
+				NewMeta(solidImage(blue), pal, S8x8),
+			},
+		},
+		{
+			"duplicate tiles",
+			[]*Meta{
+				NewMeta(solidImage(green), pal, S8x8),
+				NewMeta(imgA, pal, S8x8),
+				NewMeta(solidImage(green), pal, S8x8),
+				NewMeta(gbaimg.Flip(imgA, true, false), pal, S8x8),
+				NewMeta(solidImage(red), pal, S8x8),
+			},
+			[]*Meta{
+				NewMeta(solidImage(green), pal, S8x8),
+				NewMeta(imgA, pal, S8x8),
+				NewMeta(solidImage(red), pal, S8x8),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UniqueOrdered(tt.tiles)
+			if diff := deep.Equal(got, tt.want); len(diff) > 0 {
+				t.Errorf("UniqueOrdered() diffs(%d): %s\n", len(diff), strings.Join(diff, "\n"))
+			}
+		})
+	}
+}
+
 func TestMeta_Hash(t *testing.T) {
 	img := newImage(8, 8, []color.Color{
 		white, blue, white, blue, red, green, red, green,
@@ -10,8 +10,10 @@ import (
 
 	"github.com/bjatkin/flappy_boot/cmd/image_gen/internal/config"
 	"github.com/bjatkin/flappy_boot/cmd/image_gen/internal/gba/gbacol"
+	"github.com/bjatkin/flappy_boot/cmd/image_gen/internal/gba/pcm"
 	"github.com/bjatkin/flappy_boot/cmd/image_gen/internal/gba/raw"
 	"github.com/bjatkin/flappy_boot/cmd/image_gen/internal/gba/tile"
+	"github.com/bjatkin/flappy_boot/cmd/image_gen/internal/wav"
 )
 
 // File is an interface that can be used to create both raw data files and coresponding go files
@@ -133,6 +135,9 @@ func NewTileSetData(tileSet config.TileSet, setTransparent *gbacol.RGB15, palett
 	pal.Shared++
 	tiles := tile.NewMetaSlice(img, pal.Palette, size)
 	uniqueTiles := tile.Unique(tiles)
+	if tileSet.Ordered {
+		uniqueTiles = tile.UniqueOrdered(tiles)
+	}
 
 	return &TileSetData{
 		Name:        tileSet.Name,
@@ -296,6 +301,139 @@ func (t *TileMapData) Go() ([]byte, error) {
 	return b.Bytes(), nil
 }
 
+// FontData contains metadata for a specific font
+type FontData struct {
+	Name        string
+	Chars       string
+	Glyphs      []byte
+	Bytes       int
+	Description string
+}
+
+// NewFontData creates new FontData from a font configuration
+func NewFontData(font config.Font, setTransparent *gbacol.RGB15) (*FontData, error) {
+	imgFile, err := os.Open(font.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image file %s | %w", font.File, err)
+	}
+
+	img, _, err := image.Decode(imgFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image file %s | %w", font.File, err)
+	}
+
+	transparent := setTransparent
+	if font.Transparent != "" {
+		transparent, err = config.ParseHexColor(font.Transparent)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transparent hex color %w", err)
+		}
+	}
+
+	// glyphs are stored in the same order as the chars in the config
+	glyphs, err := raw.Glyphs(img, len(font.Chars), transparent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create glyphs from image %s | %w", font.File, err)
+	}
+
+	return &FontData{
+		Name:        font.Name,
+		Chars:       font.Chars,
+		Glyphs:      glyphs,
+		Bytes:       len(glyphs),
+		Description: font.Description,
+	}, nil
+}
+
+// Raw returns the raw glyph data for the font
+func (f *FontData) Raw() ([]byte, error) {
+	return f.Glyphs, nil
+}
+
+// Go returns a go file that contains the font
+func (f *FontData) Go() ([]byte, error) {
+	b := &bytes.Buffer{}
+	err := goTemplates.ExecuteTemplate(b, "font.go.tmpl", f)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// SoundData contains metadata for a specific sound
+type SoundData struct {
+	Name        string
+	Samples     []int8
+	Length      int
+	Loop        int
+	Rate        int
+	Description string
+}
+
+// NewSoundData creates new SoundData from a sound configuration. The sound is resampled to the configured
+// sample rate and quantized to signed 8 bit samples
+func NewSoundData(sound config.Sound) (*SoundData, error) {
+	soundFile, err := os.Open(sound.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sound file %s | %w", sound.File, err)
+	}
+	defer soundFile.Close()
+
+	wavSound, err := wav.Decode(soundFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode sound file %s | %w", sound.File, err)
+	}
+
+	// loop points are set in samples of the wav file, the sound is cut off at the loop end
+	samples := wavSound.Samples
+	loop := -1
+	if sound.Loop {
+		if sound.LoopEnd > 0 {
+			samples = samples[:sound.LoopEnd]
+		}
+		loop = pcm.Length(sound.LoopStart, wavSound.Rate, sound.SampleRate())
+	}
+
+	resampled := pcm.Resample(samples, wavSound.Rate, sound.SampleRate())
+	if len(resampled) == 0 {
+		return nil, fmt.Errorf("sound file %s is too short to convert to %dHz", sound.File, sound.SampleRate())
+	}
+	if loop >= len(resampled) {
+		loop = len(resampled) - 1
+	}
+
+	return &SoundData{
+		Name:        sound.Name,
+		Samples:     pcm.Quantize(resampled, sound.Scale()),
+		Length:      len(resampled),
+		Loop:        loop,
+		Rate:        sound.SampleRate(),
+		Description: sound.Description,
+	}, nil
+}
+
+// Raw returns the raw 8 bit PCM data for the sound
+func (s *SoundData) Raw() ([]byte, error) {
+	raw := make([]byte, len(s.Samples))
+	for i, sample := range s.Samples {
+		raw[i] = byte(sample)
+	}
+
+	return raw, nil
+}
+
+// Go returns a go file that contains the sound
+func (s *SoundData) Go() ([]byte, error) {
+	b := &bytes.Buffer{}
+	err := goTemplates.ExecuteTemplate(b, "sound.go.tmpl", s)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
 func WriteAssetFile(dir string) error {
 	assetFile := filepath.Join(dir, "assets.go")
 	f, err := os.Create(assetFile)
@@ -0,0 +1,150 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	// formatPCM is the WAV format for integer PCM samples
+	formatPCM = 1
+
+	// formatFloat is the WAV format for 32 bit float samples
+	formatFloat = 3
+
+	// formatExtensible is the WAV format that stores the real format in the extension
+	formatExtensible = 0xFFFE
+)
+
+// Sound is a decoded mono WAV file
+type Sound struct {
+	// Rate is the sample rate of the sound in Hz
+	Rate int
+
+	// Samples are the sounds samples between -1 and 1. Stereo sounds are mixed down into a single channel
+	Samples []float64
+}
+
+// format is the fmt chunk of a WAV file
+type format struct {
+	format   int
+	channels int
+	rate     int
+	bits     int
+}
+
+// Decode reads a WAV file. 8, 16, 24 and 32 bit PCM and 32 bit float WAV files are supported
+func Decode(r io.Reader) (*Sound, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, errors.New("missing RIFF WAVE header")
+	}
+
+	var fmtChunk *format
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		offset += 8
+		if offset+size > len(data) {
+			return nil, fmt.Errorf("chunk %q is longer than the file", id)
+		}
+		chunk := data[offset : offset+size]
+
+		// chunks are padded to an even length
+		offset += size + size&1
+
+		switch id {
+		case "fmt ":
+			fmtChunk, err = parseFormat(chunk)
+			if err != nil {
+				return nil, err
+			}
+		case "data":
+			if fmtChunk == nil {
+				return nil, errors.New("data chunk found before fmt chunk")
+			}
+
+			return &Sound{
+				Rate:    fmtChunk.rate,
+				Samples: decodeSamples(chunk, fmtChunk),
+			}, nil
+		}
+	}
+
+	return nil, errors.New("missing data chunk")
+}
+
+// parseFormat parses the fmt chunk and checks that the format is supported
+func parseFormat(chunk []byte) (*format, error) {
+	if len(chunk) < 16 {
+		return nil, fmt.Errorf("fmt chunk is too short (%d bytes)", len(chunk))
+	}
+
+	f := &format{
+		format:   int(binary.LittleEndian.Uint16(chunk[0:])),
+		channels: int(binary.LittleEndian.Uint16(chunk[2:])),
+		rate:     int(binary.LittleEndian.Uint32(chunk[4:])),
+		bits:     int(binary.LittleEndian.Uint16(chunk[14:])),
+	}
+
+	// the extensible format stores the real format at the start of the sub format guid
+	if f.format == formatExtensible && len(chunk) >= 26 {
+		f.format = int(binary.LittleEndian.Uint16(chunk[24:]))
+	}
+
+	switch {
+	case f.channels < 1:
+		return nil, fmt.Errorf("invalid channel count %d", f.channels)
+	case f.rate < 1:
+		return nil, fmt.Errorf("invalid sample rate %d", f.rate)
+	case f.format == formatPCM && (f.bits == 8 || f.bits == 16 || f.bits == 24 || f.bits == 32):
+	case f.format == formatFloat && f.bits == 32:
+	default:
+		return nil, fmt.Errorf("unsupported format %d with %d bit samples", f.format, f.bits)
+	}
+
+	return f, nil
+}
+
+// decodeSamples converts the data chunk into mono samples between -1 and 1
+func decodeSamples(chunk []byte, f *format) []float64 {
+	width := f.bits / 8
+	frames := len(chunk) / (width * f.channels)
+	samples := make([]float64, frames)
+	for i := range samples {
+		var sum float64
+		for c := 0; c < f.channels; c++ {
+			offset := (i*f.channels + c) * width
+			sum += decodeSample(chunk[offset:offset+width], f.format)
+		}
+		samples[i] = sum / float64(f.channels)
+	}
+
+	return samples
+}
+
+// decodeSample converts a single sample into a value between -1 and 1. 8 bit samples are unsigned
+// and all other samples are signed
+func decodeSample(b []byte, format int) float64 {
+	switch len(b) {
+	case 1:
+		return (float64(b[0]) - 128) / 128
+	case 2:
+		return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+	case 3:
+		v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+		return float64(v) / (1 << 23)
+	default:
+		if format == formatFloat {
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		}
+		return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+	}
+}
@@ -0,0 +1,113 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// newWAV creates a WAV file with the given format and raw sample data
+func newWAV(format, channels, rate, bits int, data []byte) []byte {
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk[0:], uint16(format))
+	binary.LittleEndian.PutUint16(fmtChunk[2:], uint16(channels))
+	binary.LittleEndian.PutUint32(fmtChunk[4:], uint32(rate))
+	binary.LittleEndian.PutUint32(fmtChunk[8:], uint32(rate*channels*bits/8))
+	binary.LittleEndian.PutUint16(fmtChunk[12:], uint16(channels*bits/8))
+	binary.LittleEndian.PutUint16(fmtChunk[14:], uint16(bits))
+
+	b := &bytes.Buffer{}
+	b.WriteString("RIFF")
+	binary.Write(b, binary.LittleEndian, uint32(4+8+len(fmtChunk)+8+len(data)))
+	b.WriteString("WAVE")
+
+	// an unknown chunk with an odd length to check padding is skipped
+	b.WriteString("LIST")
+	binary.Write(b, binary.LittleEndian, uint32(3))
+	b.Write([]byte{1, 2, 3, 0})
+
+	b.WriteString("fmt ")
+	binary.Write(b, binary.LittleEndian, uint32(len(fmtChunk)))
+	b.Write(fmtChunk)
+	b.WriteString("data")
+	binary.Write(b, binary.LittleEndian, uint32(len(data)))
+	b.Write(data)
+
+	return b.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	float := make([]byte, 8)
+	binary.LittleEndian.PutUint32(float, math.Float32bits(0.25))
+	binary.LittleEndian.PutUint32(float[4:], math.Float32bits(-1))
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    *Sound
+		wantErr bool
+	}{
+		{
+			"8 bit",
+			newWAV(formatPCM, 1, 8000, 8, []byte{128, 255, 0, 192}),
+			&Sound{Rate: 8000, Samples: []float64{0, 127.0 / 128, -1, 0.5}},
+			false,
+		},
+		{
+			"16 bit",
+			newWAV(formatPCM, 1, 22050, 16, []byte{0x00, 0x40, 0x00, 0x80}),
+			&Sound{Rate: 22050, Samples: []float64{0.5, -1}},
+			false,
+		},
+		{
+			"24 bit",
+			newWAV(formatPCM, 1, 44100, 24, []byte{0x00, 0x00, 0xC0}),
+			&Sound{Rate: 44100, Samples: []float64{-0.5}},
+			false,
+		},
+		{
+			"32 bit float",
+			newWAV(formatFloat, 1, 48000, 32, float),
+			&Sound{Rate: 48000, Samples: []float64{0.25, -1}},
+			false,
+		},
+		{
+			"stereo",
+			newWAV(formatPCM, 2, 8000, 8, []byte{255, 1, 128, 192}),
+			&Sound{Rate: 8000, Samples: []float64{0, 0.25}},
+			false,
+		},
+		{
+			"not a wav",
+			[]byte("RIFF0000AVI "),
+			nil,
+			true,
+		},
+		{
+			"unsupported format",
+			newWAV(2, 1, 8000, 4, []byte{0}),
+			nil,
+			true,
+		},
+		{
+			"missing data",
+			newWAV(formatPCM, 1, 8000, 8, nil)[:40],
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(bytes.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -77,6 +77,26 @@ func main() {
 		tileMaps[tileMap.Name] = tileMapData
 	}
 
+	fonts := make(map[string]*generate.FontData)
+	for _, font := range cfg.Fonts {
+		fontData, err := generate.NewFontData(font, setTransparent)
+		if err != nil {
+			exit.Error(exit.InvalidFont, fmt.Errorf("failed to generate font %s | %w", font.Name, err))
+			return
+		}
+		fonts[font.Name] = fontData
+	}
+
+	sounds := make(map[string]*generate.SoundData)
+	for _, sound := range cfg.Sounds {
+		soundData, err := generate.NewSoundData(sound)
+		if err != nil {
+			exit.Error(exit.InvalidSound, fmt.Errorf("failed to generate sound %s | %w", sound.Name, err))
+			return
+		}
+		sounds[sound.Name] = soundData
+	}
+
 	err = generate.WriteAssetFile(cfg.OutDir)
 	if err != nil {
 		exit.Error(exit.FileWriteFailed, fmt.Errorf("failed to write base asset.go file %w", err))
@@ -114,6 +134,22 @@ func main() {
 			return
 		}
 	}
+
+	for _, font := range fonts {
+		err := writeFiles(font, cfg.OutDir, font.Name+".fnt", font.Name+"Font.go")
+		if err != nil {
+			exit.Error(exit.InvalidFont, err)
+			return
+		}
+	}
+
+	for _, sound := range sounds {
+		err := writeFiles(sound, cfg.OutDir, sound.Name+".pcm", sound.Name+"Sound.go")
+		if err != nil {
+			exit.Error(exit.InvalidSound, err)
+			return
+		}
+	}
 }
 
 func writeFiles(f generate.File, dir, rawName, goName string) error {
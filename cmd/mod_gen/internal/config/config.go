@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config is a config file for the command
+type Config struct {
+	Songs  []Song `yaml:"Songs"`
+	OutDir string `yaml:"OutDir"`
+}
+
+// Song is a named tracker song
+type Song struct {
+	Name        string `yaml:"Name"`
+	File        string `yaml:"File"`
+	Description string `yaml:"Description"`
+}
+
+// NewConfigFromFile reads in the yaml file at the provided file location and then marshalls it into a new config struct
+func NewConfigFromFile(file string) (*Config, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	err = yaml.Unmarshal(raw, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Validate ensure the configuration file has a valid format
+func (c *Config) Validate() error {
+	if c.OutDir == "" {
+		return errors.New("output directory must be set")
+	}
+
+	if _, err := os.Stat(c.OutDir); err != nil {
+		return fmt.Errorf("failed to access output directory %s | %w", c.OutDir, err)
+	}
+
+	names := make(map[string]bool)
+	for _, song := range c.Songs {
+		if song.Name == "" || !unicode.IsLetter([]rune(song.Name)[0]) {
+			return fmt.Errorf("song name %q must start with a letter", song.Name)
+		}
+
+		if names[song.Name] {
+			return fmt.Errorf("song name %s is used more than once", song.Name)
+		}
+		names[song.Name] = true
+
+		switch strings.ToLower(filepath.Ext(song.File)) {
+		case ".mod", ".xm":
+		default:
+			return fmt.Errorf("song %s must be a .mod or .xm file, got %s", song.Name, song.File)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bjatkin/flappy_boot/cmd/mod_gen/internal/config"
+	"github.com/bjatkin/flappy_boot/cmd/mod_gen/internal/song"
+)
+
+// chunk is a slice of the raw song file
+type chunk struct {
+	Offset int
+	Count  int
+}
+
+// instrument is an instrument and the chunk of the raw song file that holds it's samples
+type instrument struct {
+	chunk
+	Loop   int
+	Volume int
+	Tune   int
+}
+
+// SongData holds the converted song and the layout of it's raw file
+type SongData struct {
+	Name        string
+	Description string
+	Song        *song.Song
+	Patterns    []chunk
+	Instruments []instrument
+	raw         []byte
+}
+
+// NewSongData reads and converts the song in the song config
+func NewSongData(cfg config.Song) (*SongData, error) {
+	data, err := os.ReadFile(cfg.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read song file %w", err)
+	}
+
+	var s *song.Song
+	switch strings.ToLower(filepath.Ext(cfg.File)) {
+	case ".xm":
+		s, err = song.ParseXM(data)
+	default:
+		s, err = song.ParseMOD(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse song file %s | %w", cfg.File, err)
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("song %s can not be played | %w", cfg.File, err)
+	}
+
+	songData := &SongData{
+		Name:        cfg.Name,
+		Description: cfg.Description,
+		Song:        s,
+	}
+
+	// the notes are stored first, followed by the samples for each instrument
+	for _, pattern := range s.Patterns {
+		songData.Patterns = append(songData.Patterns, chunk{Offset: len(songData.raw), Count: len(pattern)})
+		for _, note := range pattern {
+			songData.raw = append(songData.raw, note.Key, note.Instrument, note.Effect, note.Param)
+		}
+	}
+
+	// MOD files always have 31 instruments so unused instruments at the end are dropped
+	instruments := s.Instruments
+	for len(instruments) > 0 && len(instruments[len(instruments)-1].Samples) == 0 {
+		instruments = instruments[:len(instruments)-1]
+	}
+
+	for _, inst := range instruments {
+		songData.Instruments = append(songData.Instruments, instrument{
+			chunk:  chunk{Offset: len(songData.raw), Count: len(inst.Samples)},
+			Loop:   inst.Loop,
+			Volume: inst.Volume,
+			Tune:   inst.Tune,
+		})
+		for _, sample := range inst.Samples {
+			songData.raw = append(songData.raw, byte(sample))
+		}
+	}
+
+	return songData, nil
+}
+
+// Raw returns the raw note and sample data for the song
+func (s *SongData) Raw() ([]byte, error) {
+	return s.raw, nil
+}
+
+// Go returns a go file that contains the song
+func (s *SongData) Go() ([]byte, error) {
+	b := &bytes.Buffer{}
+	err := goTemplates.ExecuteTemplate(b, "song.go.tmpl", s)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
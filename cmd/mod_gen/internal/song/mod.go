@@ -0,0 +1,140 @@
+package song
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// modSamples is the number of samples in a ProTracker MOD
+	modSamples = 31
+
+	// modRows is the number of rows in each ProTracker MOD pattern
+	modRows = 64
+
+	// modHeaderLen is the length of the MOD header before the pattern data
+	modHeaderLen = 1084
+)
+
+// modChannels maps each MOD signature to the number of channels in the song
+var modChannels = map[string]int{
+	"M.K.": 4,
+	"M!K!": 4,
+	"FLT4": 4,
+	"4CHN": 4,
+	"6CHN": 6,
+	"8CHN": 8,
+}
+
+// ParseMOD parses a ProTracker MOD file. MOD samples play at 8287Hz on C-2 so MOD keys are converted using their amiga period
+func ParseMOD(data []byte) (*Song, error) {
+	if len(data) < modHeaderLen {
+		return nil, fmt.Errorf("file is too short to be a MOD (%d bytes)", len(data))
+	}
+
+	sig := string(data[1080:1084])
+	channels, ok := modChannels[sig]
+	if !ok {
+		return nil, fmt.Errorf("unsupported MOD signature %q", sig)
+	}
+
+	song := &Song{
+		Channels: channels,
+		Speed:    6,
+		Tempo:    125,
+	}
+
+	// the sample headers start after the 20 byte title
+	type header struct {
+		length, loop, loopLen int
+	}
+	headers := make([]header, modSamples)
+	for i := range headers {
+		h := data[20+i*30 : 20+(i+1)*30]
+		headers[i] = header{
+			length:  int(binary.BigEndian.Uint16(h[22:])) * 2,
+			loop:    int(binary.BigEndian.Uint16(h[26:])) * 2,
+			loopLen: int(binary.BigEndian.Uint16(h[28:])) * 2,
+		}
+
+		// the finetune is a signed nibble in 1/8ths of a semitone
+		finetune := int(h[24] & 0xF)
+		if finetune > 7 {
+			finetune -= 16
+		}
+
+		volume := int(h[25])
+		if volume > 64 {
+			volume = 64
+		}
+
+		song.Instruments = append(song.Instruments, Instrument{
+			Volume: volume,
+			Tune:   tune(finetune * 16),
+			Loop:   -1,
+		})
+	}
+
+	length := int(data[950])
+	if length == 0 || length > 128 {
+		return nil, fmt.Errorf("invalid song length %d", length)
+	}
+	song.Order = append([]uint8{}, data[952:952+length]...)
+
+	// the restart position is often set to 127 by trackers that don't support it
+	if restart := int(data[951]); restart < length {
+		song.Restart = restart
+	}
+
+	// every pattern in the order table is stored, even the ones past the end of the song
+	var patterns int
+	for _, p := range data[952:1080] {
+		if int(p)+1 > patterns {
+			patterns = int(p) + 1
+		}
+	}
+
+	patternLen := modRows * channels * 4
+	offset := modHeaderLen
+	if len(data) < offset+patterns*patternLen {
+		return nil, fmt.Errorf("file is too short for %d patterns", patterns)
+	}
+
+	for p := 0; p < patterns; p++ {
+		notes := make([]Note, modRows*channels)
+		for i := range notes {
+			n := data[offset+i*4 : offset+(i+1)*4]
+			notes[i] = Note{
+				Key:        periodToKey(int(n[0]&0xF)<<8 | int(n[1])),
+				Instrument: n[0]&0xF0 | n[2]>>4,
+				Effect:     n[2] & 0xF,
+				Param:      n[3],
+			}
+		}
+
+		song.Patterns = append(song.Patterns, notes)
+		offset += patternLen
+	}
+
+	for i, h := range headers {
+		if offset+h.length > len(data) {
+			return nil, fmt.Errorf("file is too short for sample %d", i+1)
+		}
+
+		samples := make([]int8, h.length)
+		for j := range samples {
+			samples[j] = int8(data[offset+j])
+		}
+		offset += h.length
+
+		// loops that are 2 bytes or shorter are used to mark samples that do not loop
+		inst := &song.Instruments[i]
+		if h.loopLen > 2 && h.loop+h.loopLen <= h.length {
+			samples = unrollLoop(samples[:h.loop+h.loopLen], h.loop, inst.Tune)
+			inst.Loop = h.loop
+		}
+		inst.Samples = samples
+	}
+
+	return song, nil
+}
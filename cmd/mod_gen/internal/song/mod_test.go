@@ -0,0 +1,128 @@
+package song
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// modSample is a sample in a test MOD file
+type modSample struct {
+	data     []int8
+	finetune byte
+	volume   byte
+	loop     int
+	loopLen  int
+}
+
+// newMOD creates a 4 channel MOD file with a single pattern. notes are the raw 4 byte notes at the start of the pattern
+func newMOD(sig string, order []byte, notes [][4]byte, samples []modSample) []byte {
+	data := make([]byte, modHeaderLen)
+	for i, s := range samples {
+		h := data[20+i*30 : 20+(i+1)*30]
+		binary.BigEndian.PutUint16(h[22:], uint16(len(s.data)/2))
+		h[24] = s.finetune
+		h[25] = s.volume
+		binary.BigEndian.PutUint16(h[26:], uint16(s.loop/2))
+		binary.BigEndian.PutUint16(h[28:], uint16(s.loopLen/2))
+	}
+
+	data[950] = byte(len(order))
+	data[951] = 127
+	copy(data[952:], order)
+	copy(data[1080:], sig)
+
+	var patterns int
+	for _, p := range order {
+		if int(p)+1 > patterns {
+			patterns = int(p) + 1
+		}
+	}
+
+	pattern := make([]byte, patterns*modRows*4*4)
+	for i, n := range notes {
+		copy(pattern[i*4:], n[:])
+	}
+	data = append(data, pattern...)
+
+	for _, s := range samples {
+		for _, v := range s.data {
+			data = append(data, byte(v))
+		}
+	}
+
+	return data
+}
+
+func TestParseMOD(t *testing.T) {
+	samples := []modSample{
+		{data: []int8{0, 10, 20, 30}, finetune: 0, volume: 64, loop: 0, loopLen: 2},
+		{data: []int8{-1, -2, -3, -4, -5, -6, -7, -8}, finetune: 0xF, volume: 80, loop: 2, loopLen: 4},
+	}
+	data := newMOD("M.K.", []byte{0, 0}, [][4]byte{
+		// C-2 with sample 1 and no effect
+		{0x01, 0xAC, 0x10, 0x00},
+		// no note with sample 2 and a set volume effect
+		{0x00, 0x00, 0x2C, 0x20},
+		// A-3 with no sample and a speed effect
+		{0x00, 0x7F, 0x0F, 0x04},
+	}, samples)
+
+	got, err := ParseMOD(data)
+	if err != nil {
+		t.Fatalf("ParseMOD() error = %v", err)
+	}
+
+	if got.Channels != 4 || got.Speed != 6 || got.Tempo != 125 || got.Restart != 0 {
+		t.Errorf("ParseMOD() channels, speed, tempo, restart = %d, %d, %d, %d, want 4, 6, 125, 0",
+			got.Channels, got.Speed, got.Tempo, got.Restart)
+	}
+	if !reflect.DeepEqual(got.Order, []uint8{0, 0}) {
+		t.Errorf("ParseMOD() order = %v, want [0 0]", got.Order)
+	}
+	if len(got.Patterns) != 1 || len(got.Patterns[0]) != modRows*4 {
+		t.Fatalf("ParseMOD() has %d patterns, want 1 pattern of %d notes", len(got.Patterns), modRows*4)
+	}
+
+	wantNotes := []Note{
+		{Key: 25, Instrument: 1},
+		{Instrument: 2, Effect: 0xC, Param: 0x20},
+		{Key: 46, Effect: 0xF, Param: 0x04},
+		{},
+	}
+	if !reflect.DeepEqual(got.Patterns[0][:4], wantNotes) {
+		t.Errorf("ParseMOD() notes = %v, want %v", got.Patterns[0][:4], wantNotes)
+	}
+
+	if len(got.Instruments) != modSamples {
+		t.Fatalf("ParseMOD() has %d instruments, want %d", len(got.Instruments), modSamples)
+	}
+
+	wantInsts := []Instrument{
+		{Samples: []int8{0, 10, 20, 30}, Loop: -1, Volume: 64, Tune: 256},
+		// the 4 sample loop is unrolled so the highest key can't step past it
+		{Samples: []int8{-1, -2, -3, -4, -5, -6, -3, -4, -5, -6}, Loop: 2, Volume: 64, Tune: tune(-16)},
+	}
+	if !reflect.DeepEqual(got.Instruments[:2], wantInsts) {
+		t.Errorf("ParseMOD() instruments = %v, want %v", got.Instruments[:2], wantInsts)
+	}
+}
+
+func TestParseMOD_errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"too short", make([]byte, 100)},
+		{"unknown signature", newMOD("ABCD", []byte{0}, nil, nil)},
+		{"empty order", newMOD("M.K.", nil, nil, nil)},
+		{"missing samples", newMOD("M.K.", []byte{0}, nil, []modSample{{data: make([]int8, 8)}})[:modHeaderLen+modRows*16+4]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseMOD(tt.data); err == nil {
+				t.Errorf("ParseMOD() error = nil, want an error")
+			}
+		})
+	}
+}
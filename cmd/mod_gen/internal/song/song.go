@@ -0,0 +1,140 @@
+package song
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// MaxChannels is the most channels a song can have, each channel uses one of the engines music voices
+	MaxChannels = 4
+
+	// MaxKey is the highest key a note can play (B-5)
+	MaxKey = 72
+
+	// amigaClock, minPeriod and sampleRate match the engines music player, they set how far through an
+	// instrument the highest key moves each time the mixer plays a sample
+	amigaClock = 3_546_895
+	minPeriod  = 28
+	sampleRate = 18157
+)
+
+// periods are the amiga periods for each key in octave 0, each octave up halves the period
+var periods = [12]int{1712, 1616, 1525, 1440, 1357, 1281, 1209, 1141, 1077, 1017, 961, 907}
+
+// Note is a single note in a pattern, it matches the layout of assets.Note
+type Note struct {
+	Key        uint8
+	Instrument uint8
+	Effect     uint8
+	Param      uint8
+}
+
+// Instrument is a sampled instrument
+type Instrument struct {
+	Samples []int8
+	// Loop is the sample the instrument loops back to, it's negative if the instrument does not loop
+	Loop int
+	// Volume is the default volume of the instrument (0 - 64)
+	Volume int
+	// Tune is the pitch of the instrument, 256 is the normal pitch
+	Tune int
+}
+
+// Song is a tracker song that has been converted into the format used by the engine
+type Song struct {
+	Channels    int
+	Speed       int
+	Tempo       int
+	Restart     int
+	Order       []uint8
+	Patterns    [][]Note
+	Instruments []Instrument
+}
+
+// Validate checks that the song can be played by the engine
+func (s *Song) Validate() error {
+	if s.Channels < 1 || s.Channels > MaxChannels {
+		return fmt.Errorf("songs must have between 1 and %d channels, song has %d", MaxChannels, s.Channels)
+	}
+
+	if len(s.Order) == 0 {
+		return fmt.Errorf("song has an empty order")
+	}
+
+	if s.Restart < 0 || s.Restart >= len(s.Order) {
+		return fmt.Errorf("restart position %d is outside the order", s.Restart)
+	}
+
+	for i, pattern := range s.Order {
+		if int(pattern) >= len(s.Patterns) {
+			return fmt.Errorf("order position %d uses pattern %d which does not exist", i, pattern)
+		}
+	}
+
+	for i, pattern := range s.Patterns {
+		if len(pattern) == 0 || len(pattern)%s.Channels != 0 {
+			return fmt.Errorf("pattern %d has %d notes which is not a whole number of rows", i, len(pattern))
+		}
+
+		for _, note := range pattern {
+			if note.Key > MaxKey {
+				return fmt.Errorf("pattern %d has key %d which is higher than the highest key %d", i, note.Key, MaxKey)
+			}
+			if int(note.Instrument) > len(s.Instruments) {
+				return fmt.Errorf("pattern %d uses instrument %d which does not exist", i, note.Instrument)
+			}
+		}
+	}
+
+	return nil
+}
+
+// periodToKey returns the key with the closest amiga period, 0 is returned for a period of 0
+func periodToKey(period int) uint8 {
+	if period == 0 {
+		return 0
+	}
+
+	best, bestDiff := 0, -1
+	for key := 1; key <= MaxKey; key++ {
+		diff := period - keyPeriod(key)
+		if diff < 0 {
+			diff = -diff
+		}
+
+		if bestDiff < 0 || diff < bestDiff {
+			best, bestDiff = key, diff
+		}
+	}
+
+	return uint8(best)
+}
+
+// keyPeriod returns the amiga period of the key, keys start with C-0 at 1
+func keyPeriod(key int) int {
+	return periods[(key-1)%12] >> ((key - 1) / 12)
+}
+
+// tune converts a finetune in 1/128ths of a semitone into an instrument tune
+func tune(finetune int) int {
+	return int(math.Round(256 * math.Pow(2, float64(finetune)/(12*128))))
+}
+
+// maxStep returns the most samples an instrument with the given tune can move forward each time the mixer plays
+// a sample. The rate is worked out the same way as the engine, in 1/256ths of a sample
+func maxStep(tune int) int {
+	rate := amigaClock / minPeriod * tune / sampleRate
+	return (rate + 255) / 256
+}
+
+// unrollLoop repeats the loop at the end of the samples until the loop is at least as long as the largest step
+// the instrument can take. Very short loops played at high keys would otherwise step past the whole loop at once
+func unrollLoop(samples []int8, loop, tune int) []int8 {
+	loopLen := len(samples) - loop
+	for n := loopLen; n < maxStep(tune); n += loopLen {
+		samples = append(samples, samples[loop:loop+loopLen]...)
+	}
+
+	return samples
+}
@@ -0,0 +1,113 @@
+package song
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSong_Validate(t *testing.T) {
+	valid := func() *Song {
+		return &Song{
+			Channels:    1,
+			Speed:       6,
+			Tempo:       125,
+			Order:       []uint8{0},
+			Patterns:    [][]Note{{{Key: 25, Instrument: 1}, {}}},
+			Instruments: []Instrument{{Samples: []int8{0, 1}, Loop: -1, Volume: 64, Tune: 256}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(s *Song)
+		wantErr bool
+	}{
+		{"valid", func(s *Song) {}, false},
+		{"no channels", func(s *Song) { s.Channels = 0 }, true},
+		{"too many channels", func(s *Song) { s.Channels = MaxChannels + 1 }, true},
+		{"empty order", func(s *Song) { s.Order = nil }, true},
+		{"restart past the end", func(s *Song) { s.Restart = 1 }, true},
+		{"missing pattern", func(s *Song) { s.Order = []uint8{1} }, true},
+		{"partial row", func(s *Song) { s.Channels = 3 }, true},
+		{"key too high", func(s *Song) { s.Patterns[0][0].Key = MaxKey + 1 }, true},
+		{"missing instrument", func(s *Song) { s.Patterns[0][0].Instrument = 2 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.edit(s)
+			if err := s.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Song.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_periodToKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		period int
+		want   uint8
+	}{
+		{"no note", 0, 0},
+		{"C-0", 1712, 1},
+		{"C-2", 428, 25},
+		{"finetuned C-2", 425, 25},
+		{"B-5", 28, 72},
+		{"too low", 2000, 1},
+		{"too high", 10, 72},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := periodToKey(tt.period); got != tt.want {
+				t.Errorf("periodToKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_tune(t *testing.T) {
+	tests := []struct {
+		name     string
+		finetune int
+		want     int
+	}{
+		{"normal pitch", 0, 256},
+		{"octave up", 12 * 128, 512},
+		{"octave down", -12 * 128, 128},
+		{"semitone up", 128, 271},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tune(tt.finetune); got != tt.want {
+				t.Errorf("tune() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_unrollLoop(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []int8
+		loop    int
+		tune    int
+		want    []int8
+	}{
+		{"long loop", []int8{1, 2, 3, 4, 5, 6, 7, 8, 9}, 1, 256, []int8{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{"single sample loop", []int8{1, 2}, 1, 256, []int8{1, 2, 2, 2, 2, 2, 2, 2}},
+		{"short loop", []int8{1, 2, 3, 4}, 1, 256, []int8{1, 2, 3, 4, 2, 3, 4, 2, 3, 4}},
+		{"octave up", []int8{1, 2, 3, 4}, 2, 512, []int8{1, 2, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unrollLoop(tt.samples, tt.loop, tt.tune)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unrollLoop() = %v, want %v", got, tt.want)
+			}
+			if loopLen := len(got) - tt.loop; loopLen < maxStep(tt.tune) {
+				t.Errorf("unrollLoop() loop is %d samples, want at least %d", loopLen, maxStep(tt.tune))
+			}
+		})
+	}
+}
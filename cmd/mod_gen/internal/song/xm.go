@@ -0,0 +1,254 @@
+package song
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// xmID is the text every XM file starts with
+	xmID = "Extended Module: "
+
+	// xmKeyOff is the XM note that releases the current note
+	xmKeyOff = 97
+
+	// xmKeyOffset converts an XM note into a key. XM samples play at 8363Hz on C-4 while
+	// MOD samples play at the same rate on C-2
+	xmKeyOffset = 24
+
+	// noteCut is the extended effect that cuts the note on the tick in the low nibble of the param
+	noteCut = 0xC0
+)
+
+// reader reads little endian values from an XM file, once an error occurs all reads return 0
+type reader struct {
+	data []byte
+	err  error
+}
+
+// bytes returns the n bytes starting at offset
+func (r *reader) bytes(offset, n int) []byte {
+	if r.err != nil {
+		return make([]byte, n)
+	}
+
+	if offset < 0 || offset+n > len(r.data) {
+		r.err = fmt.Errorf("unexpected end of file reading %d bytes at %d", n, offset)
+		return make([]byte, n)
+	}
+
+	return r.data[offset : offset+n]
+}
+
+func (r *reader) u8(offset int) int {
+	return int(r.bytes(offset, 1)[0])
+}
+
+func (r *reader) u16(offset int) int {
+	return int(binary.LittleEndian.Uint16(r.bytes(offset, 2)))
+}
+
+func (r *reader) u32(offset int) int {
+	return int(binary.LittleEndian.Uint32(r.bytes(offset, 4)))
+}
+
+// ParseXM parses a FastTracker 2 XM file. Only the first sample of each instrument is used, envelopes, panning
+// and the volume column effects other than set volume are ignored. XM notes are converted into amiga periods so
+// songs that use linear frequency slides will slide at a slightly different rate
+func ParseXM(data []byte) (*Song, error) {
+	r := &reader{data: data}
+	if string(r.bytes(0, len(xmID))) != xmID {
+		return nil, fmt.Errorf("missing XM id text")
+	}
+
+	headerLen := r.u32(60)
+	length := r.u16(64)
+	song := &Song{
+		Restart:  r.u16(66),
+		Channels: r.u16(68),
+		Speed:    r.u16(76),
+		Tempo:    r.u16(78),
+	}
+	patterns := r.u16(70)
+	instruments := r.u16(72)
+
+	if length == 0 || length > 256 {
+		return nil, fmt.Errorf("invalid song length %d", length)
+	}
+	song.Order = append([]uint8{}, r.bytes(80, length)...)
+	if song.Restart >= length {
+		song.Restart = 0
+	}
+
+	offset := 60 + headerLen
+	for p := 0; p < patterns && r.err == nil; p++ {
+		var notes []Note
+		notes, offset = parseXMPattern(r, offset, song.Channels)
+		song.Patterns = append(song.Patterns, notes)
+	}
+
+	for i := 0; i < instruments && r.err == nil; i++ {
+		var inst Instrument
+		inst, offset = parseXMInstrument(r, offset)
+		song.Instruments = append(song.Instruments, inst)
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return song, nil
+}
+
+// parseXMPattern parses the pattern at offset and returns the pattern and the offset of the data after it
+func parseXMPattern(r *reader, offset, channels int) ([]Note, int) {
+	headerLen := r.u32(offset)
+	rows := r.u16(offset + 5)
+	packedLen := r.u16(offset + 7)
+	offset += headerLen
+
+	notes := make([]Note, rows*channels)
+	if packedLen == 0 {
+		// empty patterns are not stored
+		return notes, offset
+	}
+
+	end := offset + packedLen
+	for i := range notes {
+		if offset >= end || r.err != nil {
+			break
+		}
+
+		// a note is either 5 bytes or a flag byte followed by the bytes that are set
+		flags := r.u8(offset)
+		if flags&0x80 == 0 {
+			flags = 0x1F
+		} else {
+			offset++
+		}
+
+		var key, inst, vol, effect, param int
+		for bit, v := range []*int{&key, &inst, &vol, &effect, &param} {
+			if flags&(1<<bit) > 0 {
+				*v = r.u8(offset)
+				offset++
+			}
+		}
+
+		notes[i] = xmNote(key, inst, vol, effect, param)
+	}
+
+	return notes, end
+}
+
+// xmNote converts the values in an XM note into a Note
+func xmNote(key, inst, vol, effect, param int) Note {
+	note := Note{
+		Instrument: uint8(inst),
+	}
+
+	// only the standard effects are supported
+	if effect <= 0xF {
+		note.Effect = uint8(effect)
+		note.Param = uint8(param)
+	}
+
+	empty := note.Effect == 0 && note.Param == 0
+	switch {
+	case key == xmKeyOff && empty:
+		note.Effect, note.Param = 0xE, noteCut
+	case key > xmKeyOffset && key < xmKeyOff:
+		note.Key = uint8(key - xmKeyOffset)
+	}
+
+	// the set volume column is converted into the set volume effect if the effect is not already used
+	if vol >= 0x10 && vol <= 0x50 && note.Effect == 0 && note.Param == 0 {
+		note.Effect, note.Param = 0xC, uint8(vol-0x10)
+	}
+
+	return note
+}
+
+// parseXMInstrument parses the instrument at offset and returns the instrument and the offset of the data after it
+func parseXMInstrument(r *reader, offset int) (Instrument, int) {
+	inst := Instrument{Loop: -1, Tune: 256}
+
+	headerLen := r.u32(offset)
+	samples := r.u16(offset + 27)
+	if samples == 0 {
+		return inst, offset + headerLen
+	}
+
+	sampleHeaderLen := r.u32(offset + 29)
+	offset += headerLen
+
+	type header struct {
+		length, loop, loopLen, volume, finetune, typ, relative int
+	}
+	headers := make([]header, samples)
+	for i := range headers {
+		h := offset + i*sampleHeaderLen
+		headers[i] = header{
+			length:   r.u32(h),
+			loop:     r.u32(h + 4),
+			loopLen:  r.u32(h + 8),
+			volume:   r.u8(h + 12),
+			finetune: int(int8(r.u8(h + 13))),
+			typ:      r.u8(h + 14),
+			relative: int(int8(r.u8(h + 16))),
+		}
+	}
+	offset += samples * sampleHeaderLen
+
+	// only the first sample is used but the sample data for every sample needs to be skipped
+	for i, h := range headers {
+		if i > 0 {
+			offset += h.length
+			continue
+		}
+
+		data := r.bytes(offset, h.length)
+		offset += h.length
+
+		// samples are delta encoded and 16 bit samples are stored in bytes, lengths and loops included
+		width := 1
+		if h.typ&0x10 > 0 {
+			width = 2
+		}
+
+		var old int
+		pcm := make([]int8, h.length/width)
+		for j := range pcm {
+			if width == 2 {
+				old = int(int16(uint16(old) + binary.LittleEndian.Uint16(data[j*2:])))
+				pcm[j] = int8(old >> 8)
+				continue
+			}
+
+			old = int(int8(uint8(old) + data[j]))
+			pcm[j] = int8(old)
+		}
+
+		// the mixer can only play forward loops
+		if h.typ&3 == 2 && r.err == nil {
+			r.err = fmt.Errorf("ping-pong sample loops are not supported, change the loop to a forward loop")
+		}
+
+		inst.Tune = tune(h.relative*128 + h.finetune)
+		loop, loopLen := h.loop/width, h.loopLen/width
+		if h.typ&3 != 0 && loopLen > 0 && loop+loopLen <= len(pcm) {
+			pcm = unrollLoop(pcm[:loop+loopLen], loop, inst.Tune)
+			inst.Loop = loop
+		}
+
+		volume := h.volume
+		if volume > 64 {
+			volume = 64
+		}
+
+		inst.Samples = pcm
+		inst.Volume = volume
+	}
+
+	return inst, offset
+}
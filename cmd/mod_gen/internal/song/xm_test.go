@@ -0,0 +1,146 @@
+package song
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// xmSample is the first sample of an instrument in a test XM file, data is already delta encoded
+type xmSample struct {
+	data     []byte
+	loop     int
+	loopLen  int
+	volume   byte
+	finetune int8
+	typ      byte
+	relative int8
+}
+
+// newXM creates an XM file with the given order, packed patterns and instruments
+func newXM(channels, speed, tempo int, order []byte, patterns [][]byte, rows int, samples []*xmSample) []byte {
+	data := make([]byte, 80+256)
+	copy(data, xmID)
+	binary.LittleEndian.PutUint32(data[60:], 276)
+	binary.LittleEndian.PutUint16(data[64:], uint16(len(order)))
+	binary.LittleEndian.PutUint16(data[68:], uint16(channels))
+	binary.LittleEndian.PutUint16(data[70:], uint16(len(patterns)))
+	binary.LittleEndian.PutUint16(data[72:], uint16(len(samples)))
+	binary.LittleEndian.PutUint16(data[76:], uint16(speed))
+	binary.LittleEndian.PutUint16(data[78:], uint16(tempo))
+	copy(data[80:], order)
+
+	for _, p := range patterns {
+		header := make([]byte, 9)
+		binary.LittleEndian.PutUint32(header, 9)
+		binary.LittleEndian.PutUint16(header[5:], uint16(rows))
+		binary.LittleEndian.PutUint16(header[7:], uint16(len(p)))
+		data = append(data, header...)
+		data = append(data, p...)
+	}
+
+	for _, s := range samples {
+		if s == nil {
+			header := make([]byte, 29)
+			binary.LittleEndian.PutUint32(header, 29)
+			data = append(data, header...)
+			continue
+		}
+
+		header := make([]byte, 263)
+		binary.LittleEndian.PutUint32(header, 263)
+		binary.LittleEndian.PutUint16(header[27:], 1)
+		binary.LittleEndian.PutUint32(header[29:], 40)
+		data = append(data, header...)
+
+		sample := make([]byte, 40)
+		binary.LittleEndian.PutUint32(sample, uint32(len(s.data)))
+		binary.LittleEndian.PutUint32(sample[4:], uint32(s.loop))
+		binary.LittleEndian.PutUint32(sample[8:], uint32(s.loopLen))
+		sample[12] = s.volume
+		sample[13] = byte(s.finetune)
+		sample[14] = s.typ
+		sample[16] = byte(s.relative)
+		data = append(data, sample...)
+		data = append(data, s.data...)
+	}
+
+	return data
+}
+
+func TestParseXM(t *testing.T) {
+	pattern := []byte{
+		// C-4 with instrument 1 unpacked
+		49, 1, 0, 0, 0,
+		// key off
+		0x81, xmKeyOff,
+		// instrument 2 with the volume column set to 32
+		0x86, 2, 0x30,
+		// A-5 with a speed effect
+		0x99, 70, 0x0F, 0x03,
+	}
+	samples := []*xmSample{
+		// 8 bit delta encoded 1, 2, 3, 4 with a forward loop
+		{data: []byte{1, 1, 1, 1}, loop: 1, loopLen: 2, volume: 40, typ: 1},
+		// 16 bit delta encoded 0x0100, 0x0300
+		{data: []byte{0x00, 0x01, 0x00, 0x02}, volume: 70, typ: 0x10, relative: 12},
+		nil,
+	}
+	data := newXM(2, 3, 140, []byte{0, 1}, [][]byte{pattern, nil}, 2, samples)
+
+	got, err := ParseXM(data)
+	if err != nil {
+		t.Fatalf("ParseXM() error = %v", err)
+	}
+
+	if got.Channels != 2 || got.Speed != 3 || got.Tempo != 140 || got.Restart != 0 {
+		t.Errorf("ParseXM() channels, speed, tempo, restart = %d, %d, %d, %d, want 2, 3, 140, 0",
+			got.Channels, got.Speed, got.Tempo, got.Restart)
+	}
+	if !reflect.DeepEqual(got.Order, []uint8{0, 1}) {
+		t.Errorf("ParseXM() order = %v, want [0 1]", got.Order)
+	}
+
+	wantPatterns := [][]Note{
+		{
+			{Key: 25, Instrument: 1},
+			{Effect: 0xE, Param: noteCut},
+			{Instrument: 2, Effect: 0xC, Param: 0x20},
+			{Key: 46, Effect: 0xF, Param: 0x03},
+		},
+		make([]Note, 4),
+	}
+	if !reflect.DeepEqual(got.Patterns, wantPatterns) {
+		t.Errorf("ParseXM() patterns = %v, want %v", got.Patterns, wantPatterns)
+	}
+
+	wantInsts := []Instrument{
+		// the 2 sample loop is unrolled so the highest key can't step past it
+		{Samples: []int8{1, 2, 3, 2, 3, 2, 3, 2, 3}, Loop: 1, Volume: 40, Tune: 256},
+		{Samples: []int8{1, 3}, Loop: -1, Volume: 64, Tune: 512},
+		{Loop: -1, Tune: 256},
+	}
+	if !reflect.DeepEqual(got.Instruments, wantInsts) {
+		t.Errorf("ParseXM() instruments = %v, want %v", got.Instruments, wantInsts)
+	}
+}
+
+func TestParseXM_errors(t *testing.T) {
+	valid := newXM(1, 6, 125, []byte{0}, [][]byte{{49, 1, 0, 0, 0}}, 1, []*xmSample{{data: make([]byte, 16)}})
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"missing id", []byte("not an xm")},
+		{"empty order", newXM(1, 6, 125, nil, nil, 1, nil)},
+		{"truncated", valid[:len(valid)-8]},
+		{"ping-pong loop", newXM(1, 6, 125, []byte{0}, [][]byte{{49, 1, 0, 0, 0}}, 1, []*xmSample{{data: make([]byte, 16), loop: 2, loopLen: 8, typ: 2}})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseXM(tt.data); err == nil {
+				t.Errorf("ParseXM() error = nil, want an error")
+			}
+		})
+	}
+}
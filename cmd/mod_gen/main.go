@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bjatkin/flappy_boot/cmd/mod_gen/internal/config"
+	"github.com/bjatkin/flappy_boot/cmd/mod_gen/internal/exit"
+	"github.com/bjatkin/flappy_boot/cmd/mod_gen/internal/generate"
+)
+
+func main() {
+	// add this first so any other defers are run before we exit
+	defer exit.Final()
+
+	// look for an input yaml file
+	if len(os.Args) != 2 {
+		exit.Error(exit.InvalidArguments, fmt.Errorf("invalid usage, missing config file: %v", os.Args))
+		return
+	}
+
+	file := os.Args[1]
+	cfg, err := config.NewConfigFromFile(file)
+	if err != nil {
+		exit.Error(exit.InvalidConfig, fmt.Errorf("failed to read in config %w", err))
+		return
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		exit.Error(exit.InvalidConfig, fmt.Errorf("failed to validate config %w", err))
+		return
+	}
+
+	for _, song := range cfg.Songs {
+		songData, err := generate.NewSongData(song)
+		if err != nil {
+			exit.Error(exit.InvalidSong, fmt.Errorf("failed to generate song %s | %w", song.Name, err))
+			return
+		}
+
+		err = writeFiles(songData, cfg.OutDir, song.Name+".song", song.Name+"Song.go")
+		if err != nil {
+			exit.Error(exit.FileWriteFailed, err)
+			return
+		}
+	}
+}
+
+// file is a generated asset with both a raw data file and a go file
+type file interface {
+	Raw() ([]byte, error)
+	Go() ([]byte, error)
+}
+
+func writeFiles(f file, dir, rawName, goName string) error {
+	rawFile := filepath.Join(dir, rawName)
+	rawBytes, err := f.Raw()
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(rawFile, rawBytes, 0o0666)
+	if err != nil {
+		return fmt.Errorf("failed to save file %s | %w", rawFile, err)
+	}
+
+	goFile := filepath.Join(dir, goName)
+	goRaw, err := f.Go()
+	if err != nil {
+		return fmt.Errorf("failed to generate go file %s | %w", goFile, err)
+	}
+
+	err = os.WriteFile(goFile, goRaw, 0o0666)
+	if err != nil {
+		return fmt.Errorf("failed to save go file %s | %w", goFile, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+//go:build standalone && headless
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bjatkin/flappy_boot/gameplay"
+	"github.com/bjatkin/flappy_boot/internal/emu/capture"
+	"github.com/bjatkin/flappy_boot/internal/game"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+var (
+	replayFlag  = flag.String("replay", "", "the replay file to render")
+	gifFlag     = flag.String("gif", "", "render the replay to a gif file at this path")
+	pngFlag     = flag.String("png", "", "save the screen at the start frame to a png file at this path")
+	startFlag   = flag.Int("start", 1, "the engine frame to start capturing at")
+	secondsFlag = flag.Float64("seconds", 0, "the number of seconds to record to the gif, 0 records until the replay ends")
+)
+
+// noKeys is the key input register value when no buttons are pressed
+const noKeys = memmap.Input(0xFFFF)
+
+func main() {
+	flag.Parse()
+	if *replayFlag == "" || (*gifFlag == "" && *pngFlag == "") {
+		fmt.Println("invalid usage, a replay file and a gif or png file are required")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	replay, err := game.ReadReplay(*replayFlag)
+	if err != nil {
+		fmt.Printf("failed to read replay: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := render(replay); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// render plays the replay in the headless harness and captures the frames set by the flags
+func render(replay *game.Replay) error {
+	h := game.NewHarness()
+	h.E.Play(replay)
+	h.Init(gameplay.NewManager(h.E))
+
+	var gif *capture.GIF
+	var saved bool
+	for h.E.Playing() {
+		h.Step(noKeys)
+
+		frame := h.E.Frame()
+		if frame < *startFlag {
+			continue
+		}
+
+		if frame == *startFlag && *pngFlag != "" {
+			if err := capture.WritePNG(*pngFlag, h.PPU.Screen); err != nil {
+				return fmt.Errorf("failed to save png: %w", err)
+			}
+			saved = true
+		}
+
+		if *gifFlag == "" {
+			continue
+		}
+		if gif == nil {
+			gif = capture.NewGIF()
+		}
+		gif.Add(h.PPU.Screen)
+		if *secondsFlag > 0 && gif.Frames() >= capture.Frames(*secondsFlag) {
+			break
+		}
+	}
+
+	if (*pngFlag != "" && !saved) || (*gifFlag != "" && gif == nil) {
+		return fmt.Errorf("the replay ends before frame %d", *startFlag)
+	}
+	if gif == nil {
+		return nil
+	}
+
+	if err := gif.WriteFile(*gifFlag); err != nil {
+		return fmt.Errorf("failed to save gif: %w", err)
+	}
+	return nil
+}
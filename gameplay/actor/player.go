@@ -10,6 +10,7 @@ type Player struct {
 	Sprite *game.Sprite
 	dy     math.Fix8
 	maxDy  math.Fix8
+	spin   math.Fix8
 
 	dead    bool
 	started bool
@@ -24,6 +25,7 @@ func NewPlayer(pos math.V2, sprite *game.Sprite) *Player {
 	return &Player{
 		Sprite: sprite,
 		maxDy:  math.FixOne * 8,
+		spin:   math.FixOne / 32,
 	}
 }
 
@@ -44,6 +46,9 @@ func (p *Player) Init(pos math.V2) {
 	p.dead = false
 	p.Sprite.Pos = pos
 	p.Sprite.HFlip = false
+	p.Sprite.Affine = false
+	p.Sprite.Rotation = 0
+	p.Sprite.DoubleSize = false
 	p.Sprite.TileIndex = 16
 	p.Sprite.PlayAnimation(glideAni)
 }
@@ -117,8 +122,11 @@ func (p *Player) Update(gravity, jump math.Fix8) {
 	}
 
 	if p.dead {
+		// the player tumbles as they fall off the screen
 		p.Sprite.StopAnimation()
-		p.Sprite.HFlip = true
+		p.Sprite.Affine = true
+		p.Sprite.DoubleSize = true
+		p.Sprite.Rotation += p.spin
 		p.Sprite.TileIndex = 0
 	}
 
@@ -0,0 +1,26 @@
+//go:build standalone
+
+package actor
+
+import (
+	"github.com/bjatkin/flappy_boot/internal/emu/snapshot"
+	"github.com/bjatkin/flappy_boot/internal/math"
+)
+
+// SaveState writes the players physics, the players sprite is saved by the engine
+func (p *Player) SaveState(w *snapshot.Writer) {
+	w.Int(int(p.dy))
+	w.Int(int(p.maxDy))
+	w.Int(int(p.spin))
+	w.Bool(p.dead)
+	w.Bool(p.started)
+}
+
+// LoadState reads the player state written by SaveState
+func (p *Player) LoadState(r *snapshot.Reader) {
+	p.dy = math.Fix8(r.Int())
+	p.maxDy = math.Fix8(r.Int())
+	p.spin = math.Fix8(r.Int())
+	p.dead = r.Bool()
+	p.started = r.Bool()
+}
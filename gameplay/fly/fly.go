@@ -4,9 +4,12 @@ import (
 	"github.com/bjatkin/flappy_boot/gameplay/actor"
 	"github.com/bjatkin/flappy_boot/gameplay/pillar"
 	"github.com/bjatkin/flappy_boot/gameplay/score"
+	"github.com/bjatkin/flappy_boot/gameplay/sfx"
 	"github.com/bjatkin/flappy_boot/gameplay/state"
+	"github.com/bjatkin/flappy_boot/internal/assets"
 	"github.com/bjatkin/flappy_boot/internal/game"
 	"github.com/bjatkin/flappy_boot/internal/key"
+	"github.com/bjatkin/flappy_boot/internal/lut"
 	"github.com/bjatkin/flappy_boot/internal/math"
 )
 
@@ -15,6 +18,9 @@ const (
 	main   = state.B
 )
 
+// hazeTop is the first scan line of the heat haze on the horizon
+const hazeTop = 112
+
 var sceneFrames = map[state.State]int{
 	fadeIn: 30,
 }
@@ -24,6 +30,7 @@ type Scene struct {
 	GameOver bool
 
 	sky         *game.Background
+	haze        *game.Raster
 	clouds      *game.Background
 	pillars     *pillar.BG
 	player      *actor.Player
@@ -47,6 +54,7 @@ func NewScene(e *game.Engine, sky, clouds *game.Background, pillars *pillar.BG,
 
 		pillars: pillars,
 		sky:     sky,
+		haze:    e.NewHScrollRaster(sky),
 		clouds:  clouds,
 		player:  player,
 		score:   score,
@@ -64,6 +72,8 @@ func (s *Scene) Init(e *game.Engine) error {
 	s.pillars.Init()
 	s.score.Set(0)
 	s.state.Init()
+	s.clearPixelate(e)
+	e.PlayMusic(assets.FlySong)
 
 	err := s.pillars.Show()
 	if err != nil {
@@ -75,6 +85,11 @@ func (s *Scene) Init(e *game.Engine) error {
 		return err
 	}
 
+	err = s.haze.Show()
+	if err != nil {
+		return err
+	}
+
 	err = s.clouds.Show()
 	if err != nil {
 		return err
@@ -96,6 +111,11 @@ func (s *Scene) Update(e *game.Engine) error {
 	s.state.Update()
 	if s.state.Is(fadeIn) {
 		e.PalFade(game.White, math.FixOne-s.state.Frac())
+		s.pixelate(e, math.FixOne-s.state.Frac())
+	}
+	if s.state.Is(main) && s.state.Frame() == 0 {
+		// the fade in just ended
+		s.clearPixelate(e)
 	}
 
 	var jump math.Fix8
@@ -103,11 +123,12 @@ func (s *Scene) Update(e *game.Engine) error {
 		s.pillars.Start()
 		s.player.Start()
 		jump = -math.FixOne * 3
+		e.PlaySound(sfx.Flap)
 	}
 
 	s.player.Update(s.gravity, jump)
 	if s.player.Rect().Y2 >= s.ground.Int() {
-		s.GameOver = true
+		s.crash(e)
 	}
 
 	s.sky.HScroll += s.scrollSpeed / 3
@@ -115,6 +136,7 @@ func (s *Scene) Update(e *game.Engine) error {
 	if err != nil {
 		return err
 	}
+	s.heatHaze(e)
 
 	s.clouds.HScroll += s.scrollSpeed / 2
 	err = s.clouds.Show()
@@ -130,13 +152,55 @@ func (s *Scene) Update(e *game.Engine) error {
 
 	if s.pillars.CheckPoint(s.player.Rect()) {
 		s.score.Show()
+		e.PlaySound(sfx.Score)
 	}
 
 	s.score.Update()
 
 	if s.pillars.CollisionCheck(s.player.Rect()) {
-		s.GameOver = true
+		s.crash(e)
 	}
 
 	return nil
 }
+
+// crash ends the game, stopping the music and playing the crash sound. The sound is only played the first time the player crashes
+func (s *Scene) crash(e *game.Engine) {
+	if !s.GameOver {
+		e.StopMusic()
+		e.PlaySound(sfx.Crash)
+	}
+	s.GameOver = true
+}
+
+// Hide hides the effects that are only used by the fly scene
+func (s *Scene) Hide() {
+	s.haze.Hide()
+}
+
+// heatHaze makes the hills on the horizon shimmer by offsetting each scan line of the sky with a sine wave
+func (s *Scene) heatHaze(e *game.Engine) {
+	for y := hazeTop; y < len(s.haze.Lines); y++ {
+		t := math.Fix8(e.Frame()*4 + y*32)
+		s.haze.Lines[y] = (lut.Sin(t) * 2).Int()
+	}
+}
+
+// pixelate pixelates the scene using the mosaic effect. At t=0 the screen is unchanged
+// and at t=1 the screen is made up of 8x8 blocks
+func (s *Scene) pixelate(e *game.Engine, t math.Fix8) {
+	s.sky.Mosaic = true
+	s.clouds.Mosaic = true
+	s.player.Sprite.Mosaic = true
+
+	size := 1 + (t * 7).Int()
+	e.SetMosaic(size, size)
+}
+
+// clearPixelate turns the mosaic effect off for the scene
+func (s *Scene) clearPixelate(e *game.Engine) {
+	s.sky.Mosaic = false
+	s.clouds.Mosaic = false
+	s.player.Sprite.Mosaic = false
+	e.SetMosaic(1, 1)
+}
@@ -0,0 +1,31 @@
+//go:build standalone
+
+package fly
+
+import (
+	"github.com/bjatkin/flappy_boot/internal/emu/snapshot"
+	"github.com/bjatkin/flappy_boot/internal/math"
+)
+
+// SaveState writes the state of the scene, the pillars are saved here since the fly scene is where they're
+// created and updated
+func (s *Scene) SaveState(w *snapshot.Writer) {
+	w.Bool(s.GameOver)
+	w.Int(int(s.scrollSpeed))
+	w.Int(int(s.gravity))
+	w.Int(int(s.ground))
+	w.Int(int(s.jumpHeight))
+	s.state.SaveState(w)
+	s.pillars.SaveState(w)
+}
+
+// LoadState reads the scene state written by SaveState
+func (s *Scene) LoadState(r *snapshot.Reader) {
+	s.GameOver = r.Bool()
+	s.scrollSpeed = math.Fix8(r.Int())
+	s.gravity = math.Fix8(r.Int())
+	s.ground = math.Fix8(r.Int())
+	s.jumpHeight = math.Fix8(r.Int())
+	s.state.LoadState(r)
+	s.pillars.LoadState(r)
+}
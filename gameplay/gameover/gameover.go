@@ -4,6 +4,7 @@ import (
 	"github.com/bjatkin/flappy_boot/gameplay/actor"
 	"github.com/bjatkin/flappy_boot/gameplay/pillar"
 	"github.com/bjatkin/flappy_boot/gameplay/score"
+	"github.com/bjatkin/flappy_boot/gameplay/sfx"
 	"github.com/bjatkin/flappy_boot/gameplay/state"
 	"github.com/bjatkin/flappy_boot/internal/assets"
 	"github.com/bjatkin/flappy_boot/internal/game"
@@ -247,11 +248,13 @@ func (m *menu) Update(e *game.Engine, s state.State) {
 	m.arrow.Update()
 
 	if s == easeIn || s == main {
-		if e.KeyJustPressed(key.Down) {
+		if e.KeyJustPressed(key.Down) && m.arrow.Pos.Y == m.pos.Y {
 			m.arrow.Pos.Y = m.pos.Y + math.FixOne*12
+			e.PlaySFX(sfx.Move)
 		}
-		if e.KeyJustPressed(key.Up) {
+		if e.KeyJustPressed(key.Up) && m.arrow.Pos.Y > m.pos.Y {
 			m.arrow.Pos.Y = m.pos.Y
+			e.PlaySFX(sfx.Move)
 		}
 	}
 
@@ -259,10 +262,12 @@ func (m *menu) Update(e *game.Engine, s state.State) {
 		if e.KeyJustPressed(key.A) && m.arrow.Pos.Y == m.pos.Y {
 			m.restart = true
 			m.arrow.PlayAnimation(arrowBlinkAnim)
+			e.PlaySFX(sfx.Confirm)
 		}
 		if e.KeyJustPressed(key.A) && m.arrow.Pos.Y > m.pos.Y {
 			m.quit = true
 			m.arrow.PlayAnimation(arrowBlinkAnim)
+			e.PlaySFX(sfx.Confirm)
 		}
 	}
 }
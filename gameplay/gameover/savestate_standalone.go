@@ -0,0 +1,35 @@
+//go:build standalone
+
+package gameover
+
+import (
+	"github.com/bjatkin/flappy_boot/internal/emu/snapshot"
+	"github.com/bjatkin/flappy_boot/internal/math"
+)
+
+// SaveState writes the state of the scene and it's menu
+func (s *Scene) SaveState(w *snapshot.Writer) {
+	w.Int(int(s.gravity))
+	w.Int(int(s.deathJump))
+	s.state.SaveState(w)
+	w.Bool(s.Restart)
+	w.Bool(s.Quit)
+
+	w.Int(int(s.menu.pos.X))
+	w.Int(int(s.menu.pos.Y))
+	w.Bool(s.menu.restart)
+	w.Bool(s.menu.quit)
+}
+
+// LoadState reads the scene state written by SaveState
+func (s *Scene) LoadState(r *snapshot.Reader) {
+	s.gravity = math.Fix8(r.Int())
+	s.deathJump = math.Fix8(r.Int())
+	s.state.LoadState(r)
+	s.Restart = r.Bool()
+	s.Quit = r.Bool()
+
+	s.menu.pos = math.V2{X: math.Fix8(r.Int()), Y: math.Fix8(r.Int())}
+	s.menu.restart = r.Bool()
+	s.menu.quit = r.Bool()
+}
@@ -0,0 +1,238 @@
+//go:build standalone && headless
+
+package gameplay
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/game"
+	hw_display "github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/key"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	hw_sprite "github.com/bjatkin/flappy_boot/internal/hardware/sprite"
+	"github.com/go-test/deep"
+)
+
+var update = flag.Bool("update", false, "update the golden frame files in testdata")
+
+var wav = flag.String("wav", "", "write the audio from the scripted game to a wav file at this path")
+
+// noKeys is the key input register value when no buttons are pressed
+const noKeys = memmap.Input(0xFFFF)
+
+// press is a button press that is held down for the given frames
+type press struct {
+	start, end int
+	key        memmap.Input
+}
+
+// capture is a frame that is compared against a golden png file in testdata
+type capture struct {
+	frame int
+	name  string
+}
+
+// keysAt returns the key input register value for the given frame of the script
+func keysAt(frame int, presses []press) memmap.Input {
+	keys := noKeys
+	for _, p := range presses {
+		if frame >= p.start && frame <= p.end {
+			keys &= ^p.key
+		}
+	}
+
+	return keys
+}
+
+func TestGolden(t *testing.T) {
+	// the script starts on the title screen, starts the game, flaps a few times and then
+	// falls to the ground so the game over screen is shown
+	presses := []press{
+		{start: 60, end: 61, key: key.StartMask},
+		{start: 200, end: 201, key: key.AMask},
+		{start: 225, end: 226, key: key.AMask},
+		{start: 250, end: 251, key: key.AMask},
+	}
+	captures := []capture{
+		{frame: 15, name: "title_fade"},
+		{frame: 45, name: "title"},
+		{frame: 110, name: "title_pixelate"},
+		{frame: 140, name: "fly_pixelate"},
+		{frame: 190, name: "fly_ready"},
+		{frame: 240, name: "fly"},
+		{frame: 300, name: "gameover_tumble"},
+		{frame: 400, name: "gameover"},
+	}
+
+	// the script is played back as a replay so the golden frames also check that replays are played exactly
+	replay := &game.Replay{Seed: 1}
+	for frame := 1; frame <= captures[len(captures)-1].frame; frame++ {
+		replay.Inputs = append(replay.Inputs, keysAt(frame, presses))
+	}
+	data, err := replay.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode the replay: %v", err)
+	}
+	if err := replay.UnmarshalBinary(data); err != nil {
+		t.Fatalf("failed to decode the replay: %v", err)
+	}
+
+	h := game.NewHarness()
+	defer h.Close()
+	h.E.Play(replay)
+	h.E.Record()
+	h.Init(NewManager(h.E))
+	if *wav != "" {
+		h.RecordAudio()
+	}
+
+	// a state is saved just before the pillars start so they can be played again once the script is done
+	const saveFrame, loadCapture = 190, 5
+	var state []byte
+
+	var next int
+	for frame := 1; next < len(captures); frame++ {
+		h.Step(noKeys)
+		if frame == saveFrame {
+			var err error
+			if state, err = h.SaveState(); err != nil {
+				t.Fatalf("failed to save state: %v", err)
+			}
+		}
+
+		if captures[next].frame != frame {
+			continue
+		}
+		if captures[next].name == "fly_ready" {
+			// the fly scene has finished fading in so the mosaic effect should be turned off
+			checkNoMosaic(t)
+		}
+
+		checkGolden(t, captures[next].name, h.PPU.Screen)
+		next++
+	}
+
+	if diff := deep.Equal(h.E.Recording(), replay); diff != nil {
+		t.Errorf("the recording does not match the replay %v", diff)
+	}
+
+	// loading the state should play the pillars exactly the same way from the game over screen
+	if err := h.LoadState(state); err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	for frame := saveFrame + 1; frame <= captures[loadCapture].frame; frame++ {
+		h.Step(noKeys)
+	}
+	checkGolden(t, captures[loadCapture].name, h.PPU.Screen)
+
+	if diff := deep.Equal(h.E.Recording().Inputs, replay.Inputs[:captures[loadCapture].frame]); diff != nil {
+		t.Errorf("the recording does not match the replay after loading a state %v", diff)
+	}
+
+	if *wav != "" {
+		if err := h.WriteWAV(*wav); err != nil {
+			t.Errorf("failed to write audio to %s: %v", *wav, err)
+		}
+	}
+}
+
+// checkNoMosaic checks that the mosaic effect is turned off for every background and sprite
+func checkNoMosaic(t *testing.T) {
+	t.Helper()
+
+	if size := memmap.GetReg(hw_display.MosaicSize); size != 0 {
+		t.Errorf("the mosaic size is %04x, want 0", size)
+	}
+	for i, bg := range []*memmap.BGControll{hw_display.BG0Controll, hw_display.BG1Controll, hw_display.BG2Controll, hw_display.BG3Controll} {
+		if memmap.GetReg(bg)&hw_display.Mosaic != 0 {
+			t.Errorf("background %d has the mosaic effect turned on", i)
+		}
+	}
+	for i, attrs := range hw_sprite.OAM {
+		if attrs.Attr0&hw_sprite.Mosaic != 0 {
+			t.Errorf("sprite %d has the mosaic effect turned on", i)
+		}
+	}
+}
+
+// checkGolden compares the frame against the golden png with the given name. If the -update flag
+// is set the golden png is overwritten with the frame instead
+func checkGolden(t *testing.T, name string, frame *image.RGBA) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".png")
+
+	if *update {
+		if err := writePNG(path, frame); err != nil {
+			t.Fatalf("failed to update golden frame %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := readPNG(path)
+	if err != nil {
+		t.Fatalf("failed to read golden frame %s: %v", path, err)
+	}
+
+	if !want.Bounds().Eq(frame.Bounds()) {
+		t.Errorf("%s: frame bounds = %v, want %v", name, frame.Bounds(), want.Bounds())
+		return
+	}
+
+	var diff int
+	var first image.Point
+	for y := frame.Bounds().Min.Y; y < frame.Bounds().Max.Y; y++ {
+		for x := frame.Bounds().Min.X; x < frame.Bounds().Max.X; x++ {
+			gr, gg, gb, ga := frame.At(x, y).RGBA()
+			wr, wg, wb, wa := want.At(x, y).RGBA()
+			if gr == wr && gg == wg && gb == wb && ga == wa {
+				continue
+			}
+
+			if diff == 0 {
+				first = image.Pt(x, y)
+			}
+			diff++
+		}
+	}
+
+	if diff > 0 {
+		actual := filepath.Join(t.TempDir(), name+".png")
+		if err := writePNG(actual, frame); err != nil {
+			t.Logf("failed to write actual frame: %v", err)
+		}
+		t.Errorf("%s: %d pixels do not match the golden frame, first mismatch at %v (actual frame written to %s)", name, diff, first, actual)
+	}
+}
+
+// readPNG reads the png image at path
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode png: %w", err)
+	}
+
+	return img, nil
+}
+
+// writePNG writes the image to path as a png
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return png.Encode(f, img)
+}
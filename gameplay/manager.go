@@ -10,6 +10,7 @@ import (
 	"github.com/bjatkin/flappy_boot/internal/assets"
 	"github.com/bjatkin/flappy_boot/internal/game"
 	"github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/key"
 	"github.com/bjatkin/flappy_boot/internal/math"
 )
 
@@ -26,6 +27,9 @@ type Manager struct {
 
 	activeScene game.Runable
 	initErr     error
+
+	// profiling is true while the profiler bar is shown, it's toggled with the select button in debug builds
+	profiling bool
 }
 
 // scoreToSaveData converts a score into a byte slice that can be saved in FRAM
@@ -43,6 +47,9 @@ func NewManager(e *game.Engine) *Manager {
 	clouds := e.NewBackground(assets.CloudsTileMap, display.Priority2)
 	player := actor.NewPlayer(math.V2{X: math.FixOne * 32, Y: math.FixOne * 62}, e.NewSprite(assets.PlayerAnimTileSet))
 	pillars := pillar.NewBG(100, e.NewBackground(assets.PillarsTileMap, display.Priority1))
+	if seed, ok := e.Seed(); ok {
+		pillars.Seed(seed)
+	}
 	roundScore := score.NewCounter(97, 28, e)
 
 	highScore := score.NewCounter(240, 0, e)
@@ -85,10 +92,18 @@ func (s *Manager) Init(e *game.Engine) error {
 	}
 
 	s.activeScene = s.titleScreen
+	e.SetScene("title")
 	return nil
 }
 
 func (s *Manager) Update(e *game.Engine) error {
+	if profilerEnabled && e.KeyJustPressed(key.Select) {
+		s.profiling = !s.profiling
+		if err := e.ShowProfiler(s.profiling); err != nil {
+			return err
+		}
+	}
+
 	err := s.activeScene.Update(e)
 	if err != nil {
 		return err
@@ -97,7 +112,9 @@ func (s *Manager) Update(e *game.Engine) error {
 	switch s.activeScene {
 	case s.fly:
 		if s.fly.GameOver {
+			s.fly.Hide()
 			s.activeScene = s.gameOver
+			e.SetScene("game over")
 			if err = s.gameOver.Init(e); err != nil {
 				return err
 			}
@@ -111,6 +128,7 @@ func (s *Manager) Update(e *game.Engine) error {
 		if s.gameOver.Restart {
 			s.gameOver.Hide()
 			s.activeScene = s.fly
+			e.SetScene("fly")
 			if err = s.fly.Init(e); err != nil {
 				return err
 			}
@@ -118,6 +136,7 @@ func (s *Manager) Update(e *game.Engine) error {
 		if s.gameOver.Quit {
 			s.gameOver.Hide()
 			s.activeScene = s.titleScreen
+			e.SetScene("title")
 			if err = s.titleScreen.Init(e); err != nil {
 				return err
 			}
@@ -126,6 +145,7 @@ func (s *Manager) Update(e *game.Engine) error {
 		if s.titleScreen.Done {
 			s.titleScreen.Hide()
 			s.activeScene = s.fly
+			e.SetScene("fly")
 			err := s.fly.Init(e)
 			if err != nil {
 				return err
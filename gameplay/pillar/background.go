@@ -11,6 +11,7 @@ import (
 type BG struct {
 	bg          *game.Background
 	rand        *rand.Rand
+	source      *source
 	nextPillar  int
 	pillarEvery int
 	gapSize     int
@@ -18,6 +19,8 @@ type BG struct {
 	meta        meta
 	scrollSpeed math.Fix8
 
+	// seeded is true if the random numbers were seeded with Seed
+	seeded  bool
 	started bool
 }
 
@@ -38,13 +41,25 @@ func NewBG(pillarEvery int, bg *game.Background) *BG {
 func (p *BG) Init() {
 	p.started = false
 	p.bg.HScroll = 0
-	p.rand = nil
+
+	// a seeded background keeps using the same random numbers so every round after the first is different
+	if !p.seeded {
+		p.rand = nil
+		p.source = nil
+	}
 
 	for i := range p.meta.pillars {
 		p.deletePillar(i)
 	}
 }
 
+// Seed seeds the random numbers that place the pillar gaps. If the background is not seeded the
+// random numbers are seeded with the horizontal scroll when the first pillar is added
+func (p *BG) Seed(seed int64) {
+	p.seedRand(seed)
+	p.seeded = true
+}
+
 // CheckPoint checks to see if the current math.Rect has passed through a new pillar gap
 func (p *BG) CheckPoint(check math.Rect) bool {
 	buffer := 4
@@ -154,6 +169,12 @@ func (p *BG) deletePillar(i int) {
 	p.meta.Delete(i)
 }
 
+// seedRand seeds the random numbers that place the pillar gaps
+func (p *BG) seedRand(seed int64) {
+	p.source = newSource(seed)
+	p.rand = rand.New(p.source)
+}
+
 // Update updates the background including scrolling, adding new pillars, and removing old pillars
 func (p *BG) Update() {
 	p.bg.HScroll += p.scrollSpeed
@@ -162,8 +183,7 @@ func (p *BG) Update() {
 		return
 	}
 	if p.rand == nil {
-		p.rand = rand.New(rand.NewSource(int64(p.bg.HScroll)))
-
+		p.seedRand(int64(p.bg.HScroll))
 	}
 
 	// add pillars to the right just off screen
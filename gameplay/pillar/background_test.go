@@ -0,0 +1,66 @@
+//go:build standalone && headless
+
+package pillar
+
+import (
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/assets"
+	"github.com/bjatkin/flappy_boot/internal/game"
+	"github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/go-test/deep"
+)
+
+// gaps plays rounds of the background and returns the top of each pillar gap that was added in each round
+func gaps(p *BG, rounds int) [][]int {
+	var gaps [][]int
+	for r := 0; r < rounds; r++ {
+		p.Init()
+		p.meta = meta{}
+		p.Start()
+		for i := 0; i < 50; i++ {
+			p.Update()
+		}
+
+		var round []int
+		for i := 0; i < p.meta.i; i++ {
+			round = append(round, p.meta.pillars[i].Y1)
+		}
+		gaps = append(gaps, round)
+	}
+
+	return gaps
+}
+
+func TestBG_Seed(t *testing.T) {
+	e := game.NewEngine()
+	newBG := func(seed int64, seeded bool) *BG {
+		p := NewBG(10, e.NewBackground(assets.PillarsTileMap, display.Priority1))
+		if seeded {
+			p.Seed(seed)
+		}
+		return p
+	}
+
+	seven := gaps(newBG(7, true), 2)
+	if len(seven[0]) != 5 {
+		t.Fatalf("BG.Update() added %d pillars, want 5", len(seven[0]))
+	}
+
+	if diff := deep.Equal(gaps(newBG(7, true), 2), seven); diff != nil {
+		t.Errorf("BG.Seed() the same seed placed different gaps %v", diff)
+	}
+	if diff := deep.Equal(gaps(newBG(8, true), 2), seven); diff == nil {
+		t.Errorf("BG.Seed() a different seed placed the same gaps")
+	}
+
+	// seeded backgrounds keep their random numbers between rounds, unseeded backgrounds are
+	// re-seeded with the horizontal scroll each round
+	if diff := deep.Equal(seven[0], seven[1]); diff == nil {
+		t.Errorf("BG.Init() a seeded background placed the same gaps in both rounds")
+	}
+	unseeded := gaps(newBG(0, false), 2)
+	if diff := deep.Equal(unseeded[0], unseeded[1]); diff != nil {
+		t.Errorf("BG.Init() an unseeded background placed different gaps %v", diff)
+	}
+}
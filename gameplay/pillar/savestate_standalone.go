@@ -0,0 +1,74 @@
+//go:build standalone
+
+package pillar
+
+import (
+	"github.com/bjatkin/flappy_boot/internal/emu/snapshot"
+	"github.com/bjatkin/flappy_boot/internal/math"
+)
+
+// maxDraws is the most random numbers a loaded background can have drawn, a pillar only draws one
+// number so this is far more than a round could ever use
+const maxDraws = 1 << 20
+
+// SaveState writes the state of the background, the random numbers are saved as their seed and the
+// number of values that have been drawn
+func (p *BG) SaveState(w *snapshot.Writer) {
+	w.Bool(p.source != nil)
+	if p.source != nil {
+		w.Int64(p.source.seed)
+		w.Int(p.source.draws)
+	}
+
+	w.Int(p.nextPillar)
+	w.Int(p.pillarEvery)
+	w.Int(p.gapSize)
+	w.Int(p.lastPoint)
+
+	for _, r := range p.meta.pillars {
+		w.Int(r.X1)
+		w.Int(r.Y1)
+		w.Int(r.X2)
+		w.Int(r.Y2)
+	}
+	for _, set := range p.meta.set {
+		w.Bool(set)
+	}
+	w.Int(p.meta.i)
+
+	w.Int(int(p.scrollSpeed))
+	w.Bool(p.seeded)
+	w.Bool(p.started)
+}
+
+// LoadState reads the background state written by SaveState
+func (p *BG) LoadState(r *snapshot.Reader) {
+	p.rand = nil
+	p.source = nil
+	if r.Bool() {
+		p.seedRand(r.Int64())
+		for draws := r.Len(maxDraws); draws > 0; draws-- {
+			p.source.Int63()
+		}
+	}
+
+	p.nextPillar = r.Int()
+	p.pillarEvery = r.Int()
+	p.gapSize = r.Int()
+	if p.gapSize < 0 || p.gapSize >= 15 {
+		r.Fail()
+	}
+	p.lastPoint = r.Int()
+
+	for i := range p.meta.pillars {
+		p.meta.pillars[i] = math.Rect{X1: r.Int(), Y1: r.Int(), X2: r.Int(), Y2: r.Int()}
+	}
+	for i := range p.meta.set {
+		p.meta.set[i] = r.Bool()
+	}
+	p.meta.i = r.Len(len(p.meta.pillars) - 1)
+
+	p.scrollSpeed = math.Fix8(r.Int())
+	p.seeded = r.Bool()
+	p.started = r.Bool()
+}
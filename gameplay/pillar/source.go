@@ -0,0 +1,32 @@
+package pillar
+
+import "math/rand"
+
+// source is a random number source that counts how many numbers it has generated. Save states use the seed
+// and the count to put the source back where it was since the state of a rand.Source can't be read
+type source struct {
+	src   rand.Source
+	seed  int64
+	draws int
+}
+
+// newSource creates a new source with the seed
+func newSource(seed int64) *source {
+	return &source{
+		src:  rand.NewSource(seed),
+		seed: seed,
+	}
+}
+
+// Int63 returns the next random number from the source
+func (s *source) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+// Seed reseeds the source and resets the count
+func (s *source) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.seed = seed
+	s.draws = 0
+}
@@ -0,0 +1,43 @@
+package pillar
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name  string
+		seed  int64
+		draws int
+	}{
+		{name: "no draws", seed: 1, draws: 0},
+		{name: "one draw", seed: 7, draws: 1},
+		{name: "many draws", seed: -42, draws: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := rand.New(rand.NewSource(tt.seed))
+			src := newSource(tt.seed)
+			got := rand.New(src)
+			for i := 0; i < tt.draws; i++ {
+				if g, w := got.Intn(8), want.Intn(8); g != w {
+					t.Fatalf("Intn() draw %d = %d, want %d", i, g, w)
+				}
+			}
+
+			if src.draws != tt.draws {
+				t.Errorf("draws = %d, want %d", src.draws, tt.draws)
+			}
+
+			restored := newSource(src.seed)
+			for i := 0; i < src.draws; i++ {
+				restored.Int63()
+			}
+			if g, w := restored.Int63(), src.Int63(); g != w {
+				t.Errorf("restored Int63() = %d, want %d", g, w)
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+//go:build debug
+
+package gameplay
+
+// profilerEnabled is true when the profiler bar can be toggled with the select button
+const profilerEnabled = true
@@ -0,0 +1,6 @@
+//go:build !debug
+
+package gameplay
+
+// profilerEnabled is false in release builds so the select button is free for the game to use
+const profilerEnabled = false
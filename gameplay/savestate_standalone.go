@@ -0,0 +1,54 @@
+//go:build standalone
+
+package gameplay
+
+import (
+	"github.com/bjatkin/flappy_boot/internal/emu/snapshot"
+	"github.com/bjatkin/flappy_boot/internal/game"
+)
+
+// scenes returns the managers scenes in the order they're saved in a save state
+func (s *Manager) scenes() []game.Runable {
+	return []game.Runable{s.titleScreen, s.fly, s.gameOver}
+}
+
+// SaveState writes the state of the manager and all of it's scenes
+func (s *Manager) SaveState(w *snapshot.Writer) {
+	active := -1
+	for i, scene := range s.scenes() {
+		if scene == s.activeScene {
+			active = i
+		}
+	}
+	w.Int(active)
+	w.Bool(s.profiling)
+
+	s.player.SaveState(w)
+	s.roundScore.SaveState(w)
+	s.highScore.SaveState(w)
+	s.titleScreen.SaveState(w)
+	s.fly.SaveState(w)
+	s.gameOver.SaveState(w)
+}
+
+// LoadState reads the manager state written by SaveState
+func (s *Manager) LoadState(r *snapshot.Reader) {
+	scenes := s.scenes()
+	active := r.Int()
+	switch {
+	case active == -1:
+		s.activeScene = nil
+	case active >= 0 && active < len(scenes):
+		s.activeScene = scenes[active]
+	default:
+		r.Fail()
+	}
+	s.profiling = r.Bool()
+
+	s.player.LoadState(r)
+	s.roundScore.LoadState(r)
+	s.highScore.LoadState(r)
+	s.titleScreen.LoadState(r)
+	s.fly.LoadState(r)
+	s.gameOver.LoadState(r)
+}
@@ -0,0 +1,115 @@
+//go:build standalone && headless
+
+package gameplay
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/game"
+	"github.com/bjatkin/flappy_boot/internal/hardware/key"
+)
+
+func TestManager_SaveState(t *testing.T) {
+	presses := []press{
+		{start: 60, end: 61, key: key.StartMask},
+		{start: 200, end: 201, key: key.AMask},
+		{start: 225, end: 226, key: key.AMask},
+		{start: 250, end: 251, key: key.AMask},
+	}
+	const lastFrame = 400
+
+	// run plays the script on the harness from the frame after start until frame end
+	run := func(h *game.Harness, start, end int) {
+		for frame := start + 1; frame <= end; frame++ {
+			h.Step(keysAt(frame, presses))
+		}
+	}
+
+	tests := []struct {
+		name      string
+		saveFrame int
+		edit      func(state []byte) []byte
+		wantErr   bool
+	}{
+		{name: "title screen", saveFrame: 30},
+		{name: "fade to fly", saveFrame: 120},
+		{name: "flying", saveFrame: 230},
+		{name: "game over", saveFrame: 320},
+		{
+			name:      "truncated",
+			saveFrame: 230,
+			edit:      func(state []byte) []byte { return state[:len(state)/2] },
+			wantErr:   true,
+		},
+		{
+			name:      "trailing data",
+			saveFrame: 230,
+			edit:      func(state []byte) []byte { return append(state, 0) },
+			wantErr:   true,
+		},
+		{
+			name:      "not a save state",
+			saveFrame: 230,
+			edit:      func(state []byte) []byte { return []byte("flappy boot") },
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := game.NewHarness()
+			defer saved.Close()
+			saved.Init(NewManager(saved.E))
+			run(saved, 0, tt.saveFrame)
+			state, err := saved.SaveState()
+			if err != nil {
+				t.Fatalf("SaveState() error = %v", err)
+			}
+
+			if tt.edit != nil {
+				err := saved.LoadState(tt.edit(state))
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("LoadState() error = %v, wantErr %v", err, tt.wantErr)
+				}
+
+				// a state that fails to load should leave the game where it was
+				got, err := saved.SaveState()
+				if err != nil {
+					t.Fatalf("SaveState() error = %v", err)
+				}
+				if !bytes.Equal(got, state) {
+					t.Errorf("the game changed after failing to load a state")
+				}
+				return
+			}
+
+			run(saved, tt.saveFrame, lastFrame)
+			wantScreen := append([]uint8{}, saved.PPU.Screen.Pix...)
+			wantSamples := append([]int16{}, saved.APU.Samples...)
+			saved.Close()
+
+			// the state is loaded into a new harness the same way it would be loaded after the game is restarted
+			h := game.NewHarness()
+			defer h.Close()
+			h.Init(NewManager(h.E))
+			if err := h.LoadState(state); err != nil {
+				t.Fatalf("LoadState() error = %v", err)
+			}
+			run(h, tt.saveFrame, lastFrame)
+
+			if !bytes.Equal(h.PPU.Screen.Pix, wantScreen) {
+				t.Errorf("the screen at frame %d does not match the game that saved the state", lastFrame)
+			}
+			if len(h.APU.Samples) != len(wantSamples) {
+				t.Fatalf("got %d samples at frame %d, want %d", len(h.APU.Samples), lastFrame, len(wantSamples))
+			}
+			for i := range wantSamples {
+				if h.APU.Samples[i] != wantSamples[i] {
+					t.Errorf("sample %d at frame %d = %d, want %d", i, lastFrame, h.APU.Samples[i], wantSamples[i])
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+//go:build standalone
+
+package score
+
+import "github.com/bjatkin/flappy_boot/internal/emu/snapshot"
+
+// SaveState writes the score and position of the counter, the digit sprites are saved by the engine
+func (c *Counter) SaveState(w *snapshot.Writer) {
+	snapshot.Ints(w, c.score[:])
+	w.Int(c.X)
+	w.Int(c.Y)
+	w.Int(c.bounceCount)
+}
+
+// LoadState reads the counter state written by SaveState
+func (c *Counter) LoadState(r *snapshot.Reader) {
+	snapshot.ReadArray(r, c.score[:])
+	for _, digit := range c.score {
+		if digit < 0 || digit >= len(c.convert) {
+			r.Fail()
+		}
+	}
+	c.X = r.Int()
+	c.Y = r.Int()
+	c.bounceCount = r.Int()
+}
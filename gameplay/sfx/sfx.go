@@ -0,0 +1,81 @@
+package sfx
+
+import (
+	"github.com/bjatkin/flappy_boot/internal/assets"
+	"github.com/bjatkin/flappy_boot/internal/game"
+)
+
+// amplitude is the loudest sample the sound effects use, it leaves some headroom so sounds can be mixed together
+const amplitude = 80
+
+var (
+	// Flap is a short rising chirp that plays when the player flaps
+	Flap = assets.NewSound(tone(440, 880, 90), -1)
+
+	// Score is a two note chime that plays when the player makes it through a pillar
+	Score = assets.NewSound(append(tone(988, 988, 60), tone(1319, 1319, 220)...), -1)
+
+	// Crash is a thud that plays when the player hits a pillar or the ground
+	Crash = assets.CrashSound
+)
+
+var (
+	// Start is a rising jingle that plays when the player presses start on the title screen
+	Start = &game.SFX{
+		Channel:    game.ChannelSweep,
+		Notes:      []int{523, 659, 784, 1047},
+		NoteFrames: 5,
+		Duty:       game.Duty25,
+		Envelope:   game.Envelope{Volume: 12, Step: 3},
+	}
+
+	// Move is a short blip that plays when the player moves the arrow in a menu
+	Move = &game.SFX{
+		Channel:  game.ChannelSquare,
+		Notes:    []int{880},
+		Length:   12,
+		Duty:     game.Duty12,
+		Envelope: game.Envelope{Volume: 10, Step: 1},
+	}
+
+	// Confirm is a quick upward sweep that plays when the player selects a menu option
+	Confirm = &game.SFX{
+		Channel:    game.ChannelSweep,
+		Notes:      []int{660},
+		NoteFrames: 12,
+		Duty:       game.Duty50,
+		Envelope:   game.Envelope{Volume: 12, Step: 2},
+		Sweep:      game.Sweep{Time: 2, Shift: 3},
+	}
+)
+
+// samples returns the number of samples in ms milliseconds of sound
+func samples(ms int) int {
+	return game.SampleRate * ms / 1000
+}
+
+// fade returns the amplitude of sample i of n, the amplitude fades out linearly over the length of the sound
+func fade(i, n int) int {
+	return amplitude * (n - i) / n
+}
+
+// tone returns a square wave that sweeps from the start frequency to the end frequency over ms milliseconds
+func tone(start, end, ms int) []int8 {
+	n := samples(ms)
+	sound := make([]int8, n)
+
+	// phase is the position in the current wave, a full wave is 0x10000
+	var phase int
+	for i := range sound {
+		freq := start + (end-start)*i/n
+		phase = (phase + freq*0x10000/game.SampleRate) & 0xFFFF
+
+		v := fade(i, n)
+		if phase >= 0x8000 {
+			v = -v
+		}
+		sound[i] = int8(v)
+	}
+
+	return sound
+}
@@ -0,0 +1,17 @@
+//go:build standalone
+
+package state
+
+import "github.com/bjatkin/flappy_boot/internal/emu/snapshot"
+
+// SaveState writes the state and frame of the tracker, the scene frames never change so they're not saved
+func (t *Tracker) SaveState(w *snapshot.Writer) {
+	w.Int(int(t.state))
+	w.Int(t.frame)
+}
+
+// LoadState reads the tracker state written by SaveState
+func (t *Tracker) LoadState(r *snapshot.Reader) {
+	t.state = State(r.Int())
+	t.frame = r.Int()
+}
@@ -0,0 +1,17 @@
+//go:build standalone
+
+package titlescreen
+
+import "github.com/bjatkin/flappy_boot/internal/emu/snapshot"
+
+// SaveState writes the state of the scene
+func (s *Scene) SaveState(w *snapshot.Writer) {
+	w.Bool(s.Done)
+	s.state.SaveState(w)
+}
+
+// LoadState reads the scene state written by SaveState
+func (s *Scene) LoadState(r *snapshot.Reader) {
+	s.Done = r.Bool()
+	s.state.LoadState(r)
+}
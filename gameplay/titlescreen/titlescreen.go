@@ -2,6 +2,7 @@ package titlescreen
 
 import (
 	"github.com/bjatkin/flappy_boot/gameplay/actor"
+	"github.com/bjatkin/flappy_boot/gameplay/sfx"
 	"github.com/bjatkin/flappy_boot/gameplay/state"
 	"github.com/bjatkin/flappy_boot/internal/assets"
 	"github.com/bjatkin/flappy_boot/internal/game"
@@ -99,6 +100,8 @@ func NewScene(e *game.Engine, sky, clouds *game.Background, player *actor.Player
 func (s *Scene) Init(e *game.Engine) error {
 	s.state.Init()
 	s.Done = false
+	s.clearPixelate(e)
+	e.PlayMusic(assets.TitleSong)
 
 	s.logo.Set(math.V2{X: math.FixOne * 72, Y: math.FixOne * 20})
 	if err := s.logo.Show(); err != nil {
@@ -135,6 +138,8 @@ func (s *Scene) Init(e *game.Engine) error {
 	s.player.Sprite.Pos = math.V2{X: math.FixOne * 104, Y: math.FixOne * 124}
 	s.player.Sprite.TileIndex = 16
 	s.player.Sprite.HFlip = false
+	s.player.Sprite.Affine = false
+	s.player.Sprite.Rotation = 0
 	if err := s.player.Show(); err != nil {
 		return err
 	}
@@ -154,6 +159,7 @@ func (s *Scene) Update(e *game.Engine) error {
 
 	if s.state.Is(main) {
 		if e.KeyJustPressed(key.Start) {
+			e.PlaySFX(sfx.Start)
 			s.state.Next()
 		}
 		return nil
@@ -172,16 +178,39 @@ func (s *Scene) Update(e *game.Engine) error {
 
 	if s.state.Is(fadeOut) {
 		e.PalFade(game.White, s.state.Frac())
+		s.pixelate(e, s.state.Frac())
 		return nil
 	}
 
 	if s.state.Is(done) {
+		s.clearPixelate(e)
 		s.Done = true
 	}
 
 	return nil
 }
 
+// pixelate pixelates the title screen using the mosaic effect. At t=0 the screen is unchanged
+// and at t=1 the screen is made up of 8x8 blocks
+func (s *Scene) pixelate(e *game.Engine, t math.Fix8) {
+	s.sky.Mosaic = true
+	s.clouds.Mosaic = true
+	s.alter.Mosaic = true
+	s.player.Sprite.Mosaic = true
+
+	size := 1 + (t * 7).Int()
+	e.SetMosaic(size, size)
+}
+
+// clearPixelate turns the mosaic effect off for the title screen
+func (s *Scene) clearPixelate(e *game.Engine) {
+	s.sky.Mosaic = false
+	s.clouds.Mosaic = false
+	s.alter.Mosaic = false
+	s.player.Sprite.Mosaic = false
+	e.SetMosaic(1, 1)
+}
+
 // Hide removes the title screen from view
 func (s *Scene) Hide() {
 	s.alter.Hide()
@@ -47,6 +47,17 @@ func (p *Pal) Free(mem *PMem) {
 	p.meta[mem.Offset] = false
 }
 
+// Used returns the number of palettes that are currently allocated as well as the total number of palettes
+func (p *Pal) Used() (palettes, total int) {
+	for _, m := range p.meta {
+		if m {
+			palettes++
+		}
+	}
+
+	return palettes, len(p.meta)
+}
+
 // IsDirty returns true if the allocator has made any new allocations since the palette was last marked clean
 func (p *Pal) IsDirty() bool {
 	return p.dirty
@@ -56,3 +67,27 @@ func (p *Pal) IsDirty() bool {
 func (p *Pal) MarkClean() {
 	p.dirty = false
 }
+
+// At returns the allocation for palette offset, nil is returned if the palette is not allocated.
+// It's used to rebuild the allocations once the palettes have been restored with SetSlots
+func (p *Pal) At(offset int) *PMem {
+	if offset < 0 || offset >= len(p.meta) || !p.meta[offset] {
+		return nil
+	}
+
+	return &PMem{
+		Memory: p.memory[offset*memmap.PaletteOffset : (offset+1)*memmap.PaletteOffset],
+		Offset: offset,
+	}
+}
+
+// Slots returns which palettes are allocated, they can be restored with SetSlots
+func (p *Pal) Slots() [8]bool {
+	return p.meta
+}
+
+// SetSlots restores the allocated palettes returned by Slots and the dirty flag returned by IsDirty
+func (p *Pal) SetSlots(slots [8]bool, dirty bool) {
+	p.meta = slots
+	p.dirty = dirty
+}
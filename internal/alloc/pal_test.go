@@ -99,3 +99,61 @@ func TestPal_Free(t *testing.T) {
 		})
 	}
 }
+
+func TestPal_Used(t *testing.T) {
+	tests := []struct {
+		name         string
+		p            *Pal
+		wantPalettes int
+	}{
+		{
+			"empty",
+			&Pal{},
+			0,
+		},
+		{
+			"partially used",
+			&Pal{meta: [8]bool{true, false, true}},
+			2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			palettes, total := tt.p.Used()
+			if palettes != tt.wantPalettes || total != 8 {
+				t.Errorf("Pal.Used() = %d, %d, want %d, 8", palettes, total, tt.wantPalettes)
+			}
+		})
+	}
+}
+
+func TestPal_At(t *testing.T) {
+	var memBlock []memmap.PaletteValue
+	for i := 0; i < 16*16; i++ {
+		memBlock = append(memBlock, memmap.PaletteValue(i))
+	}
+	p := &Pal{memory: memBlock}
+	p.SetSlots([8]bool{true, false, true}, true)
+
+	tests := []struct {
+		name   string
+		offset int
+		want   *PMem
+	}{
+		{"allocated", 2, &PMem{Memory: memBlock[32:48], Offset: 2}},
+		{"free", 1, nil},
+		{"negative offset", -1, nil},
+		{"offset out of range", 8, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.At(tt.offset); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Pal.At() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !p.IsDirty() {
+		t.Errorf("Pal.IsDirty() = false after SetSlots(slots, true)")
+	}
+}
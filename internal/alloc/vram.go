@@ -104,6 +104,63 @@ func (v *VRAM) Free(mem *VMem) {
 	}
 }
 
+// Used returns the number of cells that are currently allocated as well as the total number of cells
+func (v *VRAM) Used() (cells, total int) {
+	for i := 0; i < len(v.meta); {
+		cellSize := v.meta[i] & ^used
+		if !v.isFree(i) {
+			cells += cellSize
+		}
+		i += cellSize
+	}
+
+	return cells, len(v.meta)
+}
+
+// At returns the allocation that starts at cell offset, nil is returned if there is no allocation at the offset.
+// It's used to rebuild the allocations once the cells have been restored with SetCells
+func (v *VRAM) At(offset int) *VMem {
+	if offset < 0 || offset >= len(v.meta) || v.isFree(offset) {
+		return nil
+	}
+
+	size := v.meta[offset] & ^used
+	return &VMem{
+		Memory: v.memory[offset*v.cellSize : (offset+size)*v.cellSize],
+		Offset: offset,
+	}
+}
+
+// Cells returns the allocators cell table, it can be restored with SetCells
+func (v *VRAM) Cells() []int {
+	return v.meta
+}
+
+// SetCells restores the cell table returned by Cells. false is returned and the allocator is not changed if
+// the table does not have the same number of cells as the allocator or if it's not a valid cell table
+func (v *VRAM) SetCells(cells []int) bool {
+	if len(cells) != len(v.meta) {
+		return false
+	}
+
+	// every cell must be the start of a section that fits in the table or be inside of a section
+	for i := 0; i < len(cells); {
+		size := cells[i] & ^used
+		if cells[i] < 0 || size <= 0 || i+size > len(cells) {
+			return false
+		}
+		for _, cell := range cells[i+1 : i+size] {
+			if cell != 0 {
+				return false
+			}
+		}
+		i += size
+	}
+
+	copy(v.meta, cells)
+	return true
+}
+
 // isFree returns true if the specified cell is currently free
 func (v *VRAM) isFree(i int) bool {
 	if i < 0 || i >= len(v.meta) {
@@ -229,3 +229,102 @@ func TestVRAM_Free(t *testing.T) {
 		})
 	}
 }
+
+func TestVRAM_Used(t *testing.T) {
+	tests := []struct {
+		name      string
+		m         *VRAM
+		wantCells int
+		wantTotal int
+	}{
+		{
+			"empty",
+			&VRAM{meta: []int{5, 0, 0, 0, 0}},
+			0,
+			5,
+		},
+		{
+			"partially used",
+			&VRAM{meta: []int{used | 3, 0, 0, 2, 0}},
+			3,
+			5,
+		},
+		{
+			"free block between allocations",
+			&VRAM{meta: []int{used | 1, 2, 0, used | 2, 0}},
+			3,
+			5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cells, total := tt.m.Used()
+			if cells != tt.wantCells || total != tt.wantTotal {
+				t.Errorf("VRAM.Used() = %d, %d, want %d, %d", cells, total, tt.wantCells, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestVRAM_At(t *testing.T) {
+	var memBlock []memmap.VRAMValue
+	for i := 0; i < 50; i++ {
+		memBlock = append(memBlock, memmap.VRAMValue(i))
+	}
+	v := &VRAM{
+		meta:     []int{used | 1, 2, 0, used | 2, 0},
+		memory:   memBlock,
+		cellSize: 10,
+	}
+
+	tests := []struct {
+		name   string
+		offset int
+		want   *VMem
+	}{
+		{"first allocation", 0, &VMem{Memory: memBlock[0:10], Offset: 0}},
+		{"last allocation", 3, &VMem{Memory: memBlock[30:50], Offset: 3}},
+		{"free cell", 1, nil},
+		{"inside allocation", 4, nil},
+		{"negative offset", -1, nil},
+		{"offset out of range", 5, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := v.At(tt.offset); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("VRAM.At() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVRAM_SetCells(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells []int
+		want  bool
+	}{
+		{"empty", []int{5, 0, 0, 0, 0}, true},
+		{"allocations", []int{used | 1, 2, 0, used | 2, 0}, true},
+		{"wrong length", []int{4, 0, 0, 0}, false},
+		{"section too long", []int{used | 3, 0, 0, 3, 0}, false},
+		{"empty section", []int{0, 5, 0, 0, 0}, false},
+		{"overlapping sections", []int{used | 3, 0, 2, 2, 0}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVRAM(make([]memmap.VRAMValue, 50), 10)
+			want := append([]int{}, v.Cells()...)
+			if tt.want {
+				want = tt.cells
+			}
+
+			if got := v.SetCells(tt.cells); got != tt.want {
+				t.Errorf("VRAM.SetCells() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(v.Cells(), want) {
+				t.Errorf("VRAM.Cells() = %v, want %v", v.Cells(), want)
+			}
+		})
+	}
+}
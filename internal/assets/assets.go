@@ -3,8 +3,12 @@
 package assets
 
 import (
+	"strings"
+	"unsafe"
+
 	"github.com/bjatkin/flappy_boot/internal/alloc"
 	"github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/dma"
 	"github.com/bjatkin/flappy_boot/internal/hardware/sprite"
 	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
 )
@@ -114,6 +118,38 @@ func (t *TileMap) Free(mapAlloc, tileAlloc *alloc.VRAM, palAlloc *alloc.Pal) {
 	t.tileSet.Free(tileAlloc, palAlloc)
 }
 
+// TileSet returns the tile set the tile map uses
+func (t *TileMap) TileSet() *TileSet {
+	return t.tileSet
+}
+
+// Tiles returns the tile index data for the tile map, including the changes made with SetTile
+func (t *TileMap) Tiles() []memmap.VRAMValue {
+	return t.tiles
+}
+
+// DirtyTiles returns the tiles that have changed since the tile map was loaded into memory
+func (t *TileMap) DirtyTiles() []int {
+	return t.dirtyTiles
+}
+
+// SetTiles replaces the tile index data and the dirty tiles, it's used to restore a save state.
+// tiles must be the same length as the tile maps tiles
+func (t *TileMap) SetTiles(tiles []memmap.VRAMValue, dirtyTiles []int) {
+	copy(t.tiles, tiles)
+	t.dirtyTiles = dirtyTiles
+}
+
+// Alloc returns the memory the tile map was loaded into, nil is returned if the tile map is not loaded
+func (t *TileMap) Alloc() *alloc.VMem {
+	return t.alloc
+}
+
+// SetAlloc sets the memory the tile map was loaded into without loading it, it's used to restore a save state
+func (t *TileMap) SetAlloc(mem *alloc.VMem) {
+	t.alloc = mem
+}
+
 // TileSet is tileset data for a background or sprite
 type TileSet struct {
 	// shape is the sprite shape, the value is compatable with sprite.Attr0
@@ -162,10 +198,8 @@ func (t *TileSet) Load(tileAlloc *alloc.VRAM, palAlloc *alloc.Pal) error {
 		}
 
 		// don't use copy as it may copy data one byte at a time.
-		// pixel data must be coppied 16-bits at a time or the pixels will be corrupted
-		for i := range t.pixels {
-			t.alloc.Memory[i] = t.pixels[i]
-		}
+		// pixel data must be coppied at least 16-bits at a time or the pixels will be corrupted
+		dma.Copy32(t.alloc.Memory, t.pixels)
 	}
 
 	return nil
@@ -190,6 +224,21 @@ func (t *TileSet) Shape() sprite.Attr0 {
 	return t.shape
 }
 
+// Palette returns the palette the tile set uses
+func (t *TileSet) Palette() *Palette {
+	return t.palette
+}
+
+// Alloc returns the memory the tile set was loaded into, nil is returned if the tile set is not loaded
+func (t *TileSet) Alloc() *alloc.VMem {
+	return t.alloc
+}
+
+// SetAlloc sets the memory the tile set was loaded into without loading it, it's used to restore a save state
+func (t *TileSet) SetAlloc(mem *alloc.VMem) {
+	t.alloc = mem
+}
+
 // Palette is a 16 color palette
 type Palette struct {
 	colors []memmap.PaletteValue
@@ -206,10 +255,8 @@ func (p *Palette) Load(alloc *alloc.Pal) error {
 		}
 
 		// don't use copy as it may copy data one byte at a time.
-		// color data must be coppied 16-bits at a time or the value will be corrupted
-		for i := range p.colors {
-			p.alloc.Memory[i] = p.colors[i]
-		}
+		// color data must be coppied at least 16-bits at a time or the value will be corrupted
+		dma.Copy32(p.alloc.Memory, p.colors)
 	}
 
 	return nil
@@ -220,3 +267,189 @@ func (p *Palette) Free(palAlloc *alloc.Pal) {
 	palAlloc.Free(p.alloc)
 	p.alloc = nil
 }
+
+// Alloc returns the memory the palette was loaded into, nil is returned if the palette is not loaded
+func (p *Palette) Alloc() *alloc.PMem {
+	return p.alloc
+}
+
+// SetAlloc sets the memory the palette was loaded into without loading it, it's used to restore a save state
+func (p *Palette) SetAlloc(mem *alloc.PMem) {
+	p.alloc = mem
+}
+
+// Font is a 1 bit per pixel font with 8x8 glyphs
+type Font struct {
+	// chars are the characters in the font, they are in the same order as the glyphs
+	chars string
+
+	// glyphs is the pixel data for the font. Each glyph is 8 bytes, one byte per row,
+	// with the left most pixel in the lowest bit
+	glyphs []byte
+}
+
+// Glyph returns the pixel rows for the rune r. If the font does not contain r, ok will be false
+func (f *Font) Glyph(r rune) (glyph []byte, ok bool) {
+	i := strings.IndexRune(f.chars, r)
+	if i < 0 {
+		return nil, false
+	}
+
+	return f.glyphs[i*8 : (i+1)*8], true
+}
+
+// Sound is signed 8 bit PCM sound data
+type Sound struct {
+	// samples is the PCM data for the sound
+	samples []int8
+
+	// rate is the sample rate of the sound in Hz, at 0 the sound is played back at the engines sample rate
+	rate int
+
+	// loop is the sample playback returns to once it reaches the end of the sound,
+	// if it's negative the sound does not loop
+	loop int
+}
+
+// NewSound creates a new sound from signed 8 bit samples. loop is the sample playback returns to once it
+// reaches the end of the sound, a negative loop plays the sound once. The sound is played back at the engines sample rate
+func NewSound(samples []int8, loop int) *Sound {
+	return &Sound{
+		samples: samples,
+		loop:    loop,
+	}
+}
+
+// NewSoundRate creates a new sound like NewSound that's played back at rate Hz
+func NewSoundRate(samples []int8, loop, rate int) *Sound {
+	return &Sound{
+		samples: samples,
+		loop:    loop,
+		rate:    rate,
+	}
+}
+
+// Samples returns the PCM data for the sound
+func (s *Sound) Samples() []int8 {
+	return s.samples
+}
+
+// Loop returns the sample playback returns to once it reaches the end of the sound,
+// it's negative if the sound does not loop
+func (s *Sound) Loop() int {
+	return s.loop
+}
+
+// Rate returns the sample rate of the sound in Hz, it's 0 if the sound is played back at the engines sample rate
+func (s *Sound) Rate() int {
+	return s.rate
+}
+
+// Note is a single note in a song pattern
+type Note struct {
+	// Key is the key that's played, keys count up in semitones starting with C-0 at 1.
+	// At 0 no new note is played
+	Key uint8
+
+	// Instrument is the instrument the note is played with starting at 1.
+	// At 0 the channel keeps playing it's current instrument
+	Instrument uint8
+
+	// Effect is the tracker effect that's applied to the channel durring the row, Param is the effects parameter
+	Effect uint8
+	Param  uint8
+}
+
+// Instrument is a sound that's played by the notes in a song
+type Instrument struct {
+	// Sound is the sound that's played by the instrument
+	Sound *Sound
+
+	// Volume is the volume of the instrument when a note starts (0 - 64)
+	Volume int
+
+	// Tune is the instruments pitch, at 256 the instrument is played at it's normal pitch and at 512
+	// it's played an octave higher
+	Tune int
+}
+
+// Song is tracker music. A song is a list of patterns that are played in order, each pattern is a grid
+// of notes with a row for each step of the song and a column for each channel
+type Song struct {
+	channels    int
+	speed       int
+	tempo       int
+	restart     int
+	order       []uint8
+	patterns    [][]Note
+	instruments []Instrument
+}
+
+// NewSong creates a new song. order is the list of patterns that are played, once the song reaches the end
+// of the order it returns to the restart position. Each pattern holds the notes for every channel a row at a time.
+// speed is the number of ticks in each row and tempo sets the number of ticks per second to tempo*2/5
+func NewSong(channels, speed, tempo, restart int, order []uint8, patterns [][]Note, instruments []Instrument) *Song {
+	return &Song{
+		channels:    channels,
+		speed:       speed,
+		tempo:       tempo,
+		restart:     restart,
+		order:       order,
+		patterns:    patterns,
+		instruments: instruments,
+	}
+}
+
+// Channels returns the number of channels in the song
+func (s *Song) Channels() int {
+	return s.channels
+}
+
+// Speed returns the number of ticks in each row when the song starts
+func (s *Song) Speed() int {
+	return s.speed
+}
+
+// Tempo returns the tempo of the song when it starts, there are tempo*2/5 ticks per second
+func (s *Song) Tempo() int {
+	return s.tempo
+}
+
+// Length returns the number of patterns in the songs order
+func (s *Song) Length() int {
+	return len(s.order)
+}
+
+// Restart returns the position in the order the song returns to once it reaches the end
+func (s *Song) Restart() int {
+	return s.restart
+}
+
+// Pattern returns the notes of the pattern at the given position in the songs order
+func (s *Song) Pattern(pos int) []Note {
+	return s.patterns[s.order[pos]]
+}
+
+// Instrument returns the instrument with the given number, instruments start at 1.
+// nil is returned if the song does not have the instrument
+func (s *Song) Instrument(i int) *Instrument {
+	if i < 1 || i > len(s.instruments) {
+		return nil
+	}
+
+	return &s.instruments[i-1]
+}
+
+// songNotes returns count notes starting at offset in a generated song file
+func songNotes(song []byte, offset, count int) []Note {
+	return unsafe.Slice((*Note)(unsafe.Pointer(&song[offset])), count)
+}
+
+// pcmSamples returns count samples starting at offset in a generated sound or song file
+func pcmSamples(pcm []byte, offset, count int) []int8 {
+	if count == 0 {
+		return nil
+	}
+
+	return unsafe.Slice((*int8)(unsafe.Pointer(&pcm[offset])), count)
+}
@@ -0,0 +1,17 @@
+// This is generated code. DO NOT EDIT
+
+package assets
+
+import (
+    _ "embed"
+)
+
+//go:embed crash.pcm
+var crashSound []byte
+
+// CrashSound is the thud that plays when the player hits a pillar or the ground
+var CrashSound = &Sound{
+    samples: pcmSamples(crashSound, 0, 7262),
+    loop:    -1,
+    rate:    18157,
+}
@@ -0,0 +1,30 @@
+// This is generated code. DO NOT EDIT
+
+package assets
+
+import (
+    _ "embed"
+)
+
+//go:embed fly.song
+var flySong []byte
+
+// FlySong is the music that plays while the player is flying
+var FlySong = &Song{
+    channels: 4,
+    speed:    6,
+    tempo:    125,
+    restart:  0,
+    order:    []uint8{0, 1, 0, 1},
+    patterns: [][]Note{
+        songNotes(flySong, 0, 256),
+        songNotes(flySong, 1024, 256),
+    },
+    instruments: []Instrument{
+        {Sound: NewSound(pcmSamples(flySong, 2048, 32), 0), Volume: 44, Tune: 256},
+        {Sound: NewSound(pcmSamples(flySong, 2080, 32), 0), Volume: 56, Tune: 256},
+        {Sound: NewSound(pcmSamples(flySong, 2112, 32), 0), Volume: 24, Tune: 256},
+        {Sound: NewSound(pcmSamples(flySong, 2144, 900), -1), Volume: 36, Tune: 256},
+        {Sound: NewSound(pcmSamples(flySong, 3044, 2400), -1), Volume: 60, Tune: 256},
+    },
+}
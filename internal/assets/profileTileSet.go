@@ -0,0 +1,33 @@
+// This is generated code. DO NOT EDIT
+
+package assets
+
+import (
+    _ "embed"
+    "unsafe"
+
+    "github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+    "github.com/bjatkin/flappy_boot/internal/hardware/sprite"
+)
+
+//go:embed profile.ts4
+var profileTileSet []byte
+
+// ProfileTileSet is the bar segments used to draw the profiler. Each row is a color and each column is one pixel wider than the last
+var ProfileTileSet = &TileSet{
+    shape: sprite.Square,
+    size:  sprite.Small,
+    count: 42,
+    pixels: unsafe.Slice(
+        (*memmap.VRAMValue)(unsafe.Pointer(&profileTileSet[0])),
+        672,
+    ),
+
+    palette: &Palette{
+        colors: unsafe.Slice(
+            (*memmap.PaletteValue)(unsafe.Pointer(&profileTileSet[1344])),
+            16,
+        ),
+    },
+
+}
@@ -0,0 +1,16 @@
+// This is generated code. DO NOT EDIT
+
+package assets
+
+import (
+    _ "embed"
+)
+
+//go:embed system.fnt
+var systemFont []byte
+
+// SystemFont is the built in system font, it's used to print error information when the game crashes
+var SystemFont = &Font{
+    chars:  " !\"#%'()*+,-./0123456789:;<=>?ABCDEFGHIJKLMNOPQRSTUVWXYZ[]_|",
+    glyphs: systemFont,
+}
@@ -0,0 +1,250 @@
+package apu
+
+import (
+	"github.com/bjatkin/flappy_boot/internal/hardware/audio"
+	"github.com/bjatkin/flappy_boot/internal/hardware/dma"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	"github.com/bjatkin/flappy_boot/internal/hardware/timer"
+)
+
+const (
+	// SampleRate is the number of stereo samples the APU generates each second
+	SampleRate = 48_000
+
+	// fifoLen is the number of samples each direct sound fifo can hold
+	fifoLen = 32
+
+	// refillLen is the number of samples added to a direct sound fifo each time it runs low
+	refillLen = dma.FIFOWords * 4
+)
+
+// prescale is the number of cycles between each timer tick for each timer frequency
+var prescale = [4]int{1, 64, 256, 1024}
+
+// fifo is an emulated direct sound fifo
+type fifo struct {
+	reg     *memmap.SoundFIFO
+	samples []int8
+
+	// sample is the sample that's currently being played
+	sample int8
+}
+
+// pop plays the next sample in the fifo, refilling the fifo if it's running low
+func (f *fifo) pop(refill func(*memmap.SoundFIFO) []uint32) {
+	if len(f.samples) <= fifoLen-refillLen && refill != nil {
+		for _, word := range refill(f.reg) {
+			// samples are played starting with the least significant byte
+			f.samples = append(f.samples, int8(word), int8(word>>8), int8(word>>16), int8(word>>24))
+		}
+	}
+
+	if len(f.samples) == 0 {
+		return
+	}
+
+	f.sample = f.samples[0]
+	f.samples = f.samples[1:]
+}
+
+// APU is an emulated GBA audio processing unit. It reads the sound registers from the memmap blocks
+// and generates a frame of stereo PCM samples each time it's updated
+type APU struct {
+	// Refill is called when a direct sound fifo runs low, it should return the words a sound DMA
+	// would copy into the fifo or nil if no DMA is streaming into the fifo
+	Refill func(fifo *memmap.SoundFIFO) []uint32
+
+	// Samples are the stereo samples generated by the last update, the left sample is first
+	Samples []int16
+
+	square1 square
+	square2 square
+	wave    wave
+	noise   noise
+	fifos   [2]fifo
+
+	// timers count the cycles towards the next overflow of timer 0 and timer 1
+	timers [2]int
+
+	// frame is the part of the frame left over after the last sample and frac is the part of a cycle left
+	// over after the last sample, both are in 1/SampleRate cycles. Neither the number of samples in a frame
+	// or the number of cycles in a sample are whole numbers so the remainders carry over
+	frame int
+	frac  int
+}
+
+// New creates a new APU
+func New() *APU {
+	return &APU{
+		fifos: [2]fifo{
+			{reg: audio.FIFOA},
+			{reg: audio.FIFOB},
+		},
+	}
+}
+
+// Update reads the sound registers and generates one frame of samples into a.Samples.
+// It should be called once at the start of every frame
+func (a *APU) Update() {
+	a.square1.update(audio.Sound1Sweep, audio.Sound1Envelope, audio.Sound1Freq)
+	a.square2.update(nil, audio.Sound2Envelope, audio.Sound2Freq)
+	a.wave.update()
+	a.noise.update()
+
+	// the fifo reset bits are write only on the GBA so they're cleared once they've been read
+	ds := memmap.GetReg(audio.DSControll)
+	if ds&audio.AReset > 0 {
+		a.fifos[0].samples = a.fifos[0].samples[:0]
+	}
+	if ds&audio.BReset > 0 {
+		a.fifos[1].samples = a.fifos[1].samples[:0]
+	}
+	memmap.SetReg(audio.DSControll, ds&^(audio.AReset|audio.BReset))
+
+	a.Samples = a.Samples[:0]
+	a.frame += dma.ScreenRefresh * SampleRate
+	for a.frame >= dma.SystemClock {
+		a.frame -= dma.SystemClock
+
+		a.frac += dma.SystemClock
+		cycles := a.frac / SampleRate
+		a.frac %= SampleRate
+
+		l, r := a.sample(cycles)
+		a.Samples = append(a.Samples, l, r)
+	}
+
+	a.updateStat()
+}
+
+// sample runs the APU for the given number of cycles and returns the mixed left and right samples
+func (a *APU) sample(cycles int) (int16, int16) {
+	a.runTimers(cycles)
+
+	ch := [4]int{
+		a.square1.run(cycles, audio.Sound1Freq),
+		a.square2.run(cycles, audio.Sound2Freq),
+		a.wave.run(cycles),
+		a.noise.run(cycles),
+	}
+
+	if memmap.GetReg(audio.Stat)&audio.MasterSoundEnable == 0 {
+		return 0, 0
+	}
+
+	dmg := memmap.GetReg(audio.DMGControll)
+	ds := memmap.GetReg(audio.DSControll)
+
+	// the DMG channels are scaled by the left and right volume and then by the DMG volume ratio
+	var left, right int
+	for i, v := range ch {
+		if dmg&(audio.Sound1R<<i) > 0 {
+			right += v
+		}
+		if dmg&(audio.Sound1L<<i) > 0 {
+			left += v
+		}
+	}
+	left *= int(dmg>>audio.LeftVolumeShift)&7 + 1
+	right *= int(dmg>>audio.RightVolumeShift)&7 + 1
+
+	ratio := 2 - int(ds&3)
+	if ratio < 0 {
+		ratio = 0
+	}
+	left >>= ratio
+	right >>= ratio
+
+	// direct sound is doubled at 100% volume
+	a100 := int(ds&audio.A100)>>2 + 1
+	b100 := int(ds&audio.B100)>>3 + 1
+	if ds&audio.ALEnable > 0 {
+		left += int(a.fifos[0].sample) * a100
+	}
+	if ds&audio.AREnable > 0 {
+		right += int(a.fifos[0].sample) * a100
+	}
+	if ds&audio.BLEnable > 0 {
+		left += int(a.fifos[1].sample) * b100
+	}
+	if ds&audio.BREnable > 0 {
+		right += int(a.fifos[1].sample) * b100
+	}
+
+	return output(left), output(right)
+}
+
+// runTimers runs timer 0 and timer 1 for the given number of cycles, the direct sound fifos play
+// a new sample each time their timer overflows
+func (a *APU) runTimers(cycles int) {
+	ds := memmap.GetReg(audio.DSControll)
+	period0 := timerPeriod(timer.Counter0, timer.Controll0, 0)
+	period1 := timerPeriod(timer.Counter1, timer.Controll1, period0)
+
+	var timerA, timerB int
+	if ds&audio.ATimer1 > 0 {
+		timerA = 1
+	}
+	if ds&audio.BTimer1 > 0 {
+		timerB = 1
+	}
+
+	for i, period := range [2]int{period0, period1} {
+		if period == 0 {
+			a.timers[i] = 0
+			continue
+		}
+
+		a.timers[i] += cycles
+		for a.timers[i] >= period {
+			a.timers[i] -= period
+
+			if timerA == i {
+				a.fifos[0].pop(a.Refill)
+			}
+			if timerB == i {
+				a.fifos[1].pop(a.Refill)
+			}
+		}
+	}
+}
+
+// updateStat sets the read only channel activity bits in the audio stat register
+func (a *APU) updateStat() {
+	stat := memmap.GetReg(audio.Stat) &^ 0xF
+	for i, on := range [4]bool{a.square1.on, a.square2.on, a.wave.on, a.noise.on} {
+		if on {
+			stat |= 1 << i
+		}
+	}
+	memmap.SetReg(audio.Stat, stat)
+}
+
+// timerPeriod returns the number of cycles between each overflow of the timer or 0 if the timer is stopped.
+// cascade is the period of the previous timer which is used if the timer is counting up
+func timerPeriod(counter *memmap.TimerCounter, controll *memmap.TimerControll, cascade int) int {
+	c := memmap.GetReg(controll)
+	if c&timer.TimerStart == 0 {
+		return 0
+	}
+
+	// the emulated counter register never ticks so it always holds the reload value
+	ticks := 0x1_0000 - int(memmap.GetReg(counter))
+	if c&timer.CountUpEnable > 0 {
+		return ticks * cascade
+	}
+
+	return ticks * prescale[c&3]
+}
+
+// output converts a mixed sample into a 16 bit sample, the GBA clamps the mixed sound to 10 bits
+func output(v int) int16 {
+	if v > 511 {
+		v = 511
+	}
+	if v < -512 {
+		v = -512
+	}
+
+	return int16(v << 6)
+}
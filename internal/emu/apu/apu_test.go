@@ -0,0 +1,220 @@
+//go:build standalone
+
+package apu
+
+import (
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/audio"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	"github.com/bjatkin/flappy_boot/internal/hardware/timer"
+)
+
+// resetSound clears the io registers and turns on master sound
+func resetSound() {
+	memmap.IORegBlock = [memmap.KByte]byte{}
+	memmap.SetReg(audio.Stat, audio.MasterSoundEnable)
+}
+
+// crossings returns the number of times the left channel goes from negative to positive
+func crossings(samples []int16) int {
+	var count int
+	for i := 2; i < len(samples); i += 2 {
+		if samples[i-2] < 0 && samples[i] >= 0 {
+			count++
+		}
+	}
+
+	return count
+}
+
+// silent returns true if every sample is 0
+func silent(samples []int16) bool {
+	for _, v := range samples {
+		if v != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestAPU_Update(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func()
+		// minCross and maxCross are the range of zero crossings expected in a single frame
+		minCross, maxCross int
+		silent             bool
+	}{
+		{
+			"no sound",
+			func() {},
+			0, 0,
+			true,
+		},
+		{
+			"square 440Hz",
+			func() {
+				memmap.SetReg(audio.DMGControll, 7<<audio.LeftVolumeShift|7<<audio.RightVolumeShift|audio.Sound2L|audio.Sound2R)
+				memmap.SetReg(audio.DSControll, audio.Dmg100)
+				memmap.SetReg(audio.Sound2Envelope, 15<<audio.EnvelopeVolumeShift|audio.Duty50)
+				memmap.SetReg(audio.Sound2Freq, 1751|audio.SoundRestart)
+			},
+			// 440Hz is about 7.4 waves per frame
+			6, 8,
+			false,
+		},
+		{
+			"square channel not enabled",
+			func() {
+				memmap.SetReg(audio.Sound2Envelope, 15<<audio.EnvelopeVolumeShift|audio.Duty50)
+				memmap.SetReg(audio.Sound2Freq, 1751|audio.SoundRestart)
+			},
+			0, 0,
+			true,
+		},
+		{
+			"master sound off",
+			func() {
+				memmap.SetReg(audio.Stat, audio.MasterSoundDisable)
+				memmap.SetReg(audio.DMGControll, 7<<audio.LeftVolumeShift|audio.Sound2L)
+				memmap.SetReg(audio.Sound2Envelope, 15<<audio.EnvelopeVolumeShift|audio.Duty50)
+				memmap.SetReg(audio.Sound2Freq, 1751|audio.SoundRestart)
+			},
+			0, 0,
+			true,
+		},
+		{
+			"wave 440Hz",
+			func() {
+				memmap.SetReg(audio.DMGControll, 7<<audio.LeftVolumeShift|audio.Sound3L)
+				memmap.SetReg(audio.DSControll, audio.Dmg100)
+				for i := range audio.WaveRAM {
+					// the first half of the wave is high and the second half is low
+					if i < 4 {
+						audio.WaveRAM[i] = 0xFFFF
+					}
+				}
+				memmap.SetReg(audio.Sound3Controll, audio.WaveOn)
+				memmap.SetReg(audio.Sound3Volume, audio.WaveVolume100)
+				memmap.SetReg(audio.Sound3Freq, 1899|audio.SoundRestart)
+			},
+			6, 8,
+			false,
+		},
+		{
+			"noise",
+			func() {
+				memmap.SetReg(audio.DMGControll, 7<<audio.LeftVolumeShift|audio.Sound4L)
+				memmap.SetReg(audio.DSControll, audio.Dmg100)
+				memmap.SetReg(audio.Sound4Envelope, 15<<audio.EnvelopeVolumeShift)
+				memmap.SetReg(audio.Sound4Freq, 0x24|audio.NoiseRestart)
+			},
+			20, 800,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetSound()
+			tt.setup()
+
+			a := New()
+			a.Update()
+
+			if len(a.Samples) != 1606 && len(a.Samples) != 1608 {
+				t.Errorf("APU.Update() generated %d samples, want about 803 stereo samples", len(a.Samples))
+			}
+
+			if got := silent(a.Samples); got != tt.silent {
+				t.Errorf("APU.Update() silent = %v, want %v", got, tt.silent)
+			}
+
+			if got := crossings(a.Samples); got < tt.minCross || got > tt.maxCross {
+				t.Errorf("APU.Update() zero crossings = %d, want %d - %d", got, tt.minCross, tt.maxCross)
+			}
+		})
+	}
+}
+
+func TestAPU_UpdateRestart(t *testing.T) {
+	resetSound()
+	memmap.SetReg(audio.Sound1Envelope, 15<<audio.EnvelopeVolumeShift)
+	memmap.SetReg(audio.Sound1Freq, 1000|audio.SoundRestart)
+
+	a := New()
+	a.Update()
+
+	if got := memmap.GetReg(audio.Sound1Freq); got != 1000 {
+		t.Errorf("APU.Update() Sound1Freq = %#x, want the restart bit cleared", got)
+	}
+
+	if got := memmap.GetReg(audio.Stat); got != audio.MasterSoundEnable|1 {
+		t.Errorf("APU.Update() Stat = %#x, want sound 1 active", got)
+	}
+}
+
+func TestAPU_UpdateSweep(t *testing.T) {
+	tests := []struct {
+		name     string
+		freq     memmap.SoundFreq
+		wantFreq memmap.SoundFreq
+		wantOn   bool
+	}{
+		// a frame is a little over 2/128ths of a second so the sweep steps twice, each step adds freq/2
+		{"sweep up", 512, 1152, true},
+		{"sweep past the highest frequency", 1024, 1536, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetSound()
+			memmap.SetReg(audio.Sound1Sweep, 1<<audio.SweepTimeShift|1)
+			memmap.SetReg(audio.Sound1Envelope, 15<<audio.EnvelopeVolumeShift)
+			memmap.SetReg(audio.Sound1Freq, tt.freq|audio.SoundRestart)
+
+			a := New()
+			a.Update()
+
+			if got := memmap.GetReg(audio.Sound1Freq) & audio.FreqMask; got != tt.wantFreq {
+				t.Errorf("APU.Update() Sound1Freq = %d, want %d", got, tt.wantFreq)
+			}
+			if got := memmap.GetReg(audio.Stat)&1 > 0; got != tt.wantOn {
+				t.Errorf("APU.Update() sound 1 active = %v, want %v", got, tt.wantOn)
+			}
+		})
+	}
+}
+
+func TestAPU_UpdateDirectSound(t *testing.T) {
+	resetSound()
+	memmap.SetReg(audio.DSControll, audio.A100|audio.ALEnable|audio.ATimer0)
+	memmap.SetReg(timer.Counter0, 0x10000-924)
+	memmap.SetReg(timer.Controll0, timer.Freq1|timer.TimerStart)
+
+	var refills int
+	a := New()
+	a.Refill = func(fifo *memmap.SoundFIFO) []uint32 {
+		if fifo != audio.FIFOA {
+			return nil
+		}
+
+		refills++
+		// each word is 4 samples of 10, the least significant byte is played first
+		return []uint32{0x0A0A0A0A, 0x0A0A0A0A, 0x0A0A0A0A, 0x0A0A0A0A}
+	}
+	a.Update()
+
+	// 304 samples are played each frame and the fifo is refilled with 16 samples at a time
+	if refills < 19 || refills > 20 {
+		t.Errorf("APU.Update() refilled the fifo %d times, want 19 - 20", refills)
+	}
+
+	last := len(a.Samples) - 2
+	if a.Samples[last] != 20<<6 {
+		t.Errorf("APU.Update() left sample = %d, want %d", a.Samples[last], 20<<6)
+	}
+	if a.Samples[last+1] != 0 {
+		t.Errorf("APU.Update() right sample = %d, want 0", a.Samples[last+1])
+	}
+}
@@ -0,0 +1,336 @@
+package apu
+
+import (
+	"github.com/bjatkin/flappy_boot/internal/hardware/audio"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+const (
+	// lengthCycles is the number of cycles between each length counter tick (256Hz)
+	lengthCycles = 65_536
+
+	// envelopeCycles is the number of cycles between each envelope step for a step time of 1 (64Hz)
+	envelopeCycles = 262_144
+
+	// sweepCycles is the number of cycles between each sweep step for a sweep time of 1 (128Hz)
+	sweepCycles = 131_072
+)
+
+// dutyHigh is the number of the 8 steps in each square wave that are high for each duty setting
+var dutyHigh = [4]int{1, 2, 4, 6}
+
+// length is the length counter shared by all the DMG channels, once it runs out the channel stops
+// if the channel is timed
+type length struct {
+	timed  bool
+	count  int
+	cycles int
+}
+
+// start resets the length counter
+func (l *length) start(count int) {
+	l.count = count
+	l.cycles = 0
+}
+
+// run runs the length counter for the given number of cycles, it returns false once the length has run out
+func (l *length) run(cycles int) bool {
+	if !l.timed {
+		return true
+	}
+
+	l.cycles += cycles
+	for l.cycles >= lengthCycles {
+		l.cycles -= lengthCycles
+		l.count--
+	}
+
+	return l.count > 0
+}
+
+// envelope is the volume envelope shared by the square and noise channels
+type envelope struct {
+	volume int
+	step   int
+	up     bool
+	cycles int
+}
+
+// start resets the envelope using the value of the envelope register
+func (e *envelope) start(reg memmap.SoundEnvelope) {
+	e.volume = int(reg >> audio.EnvelopeVolumeShift)
+	e.step = int(reg>>audio.EnvelopeStepShift) & 7
+	e.up = reg&audio.EnvelopeUp > 0
+	e.cycles = 0
+}
+
+// run runs the envelope for the given number of cycles
+func (e *envelope) run(cycles int) {
+	if e.step == 0 {
+		return
+	}
+
+	e.cycles += cycles
+	for e.cycles >= e.step*envelopeCycles {
+		e.cycles -= e.step * envelopeCycles
+		if e.up && e.volume < 15 {
+			e.volume++
+		}
+		if !e.up && e.volume > 0 {
+			e.volume--
+		}
+	}
+}
+
+// square is an emulated square wave channel, sound 1 and sound 2 are both square channels
+type square struct {
+	on       bool
+	freq     int
+	duty     int
+	step     int
+	cycles   int
+	length   length
+	envelope envelope
+
+	// sweep is only used by sound 1, sweepCycles counts up to the next sweep step
+	sweep       memmap.ToneSweep
+	sweepCycles int
+}
+
+// update reads the channels registers, restarting the channel if the restart bit is set.
+// The restart bit is write only on the GBA so it's cleared once it's been read
+func (s *square) update(sweep *memmap.ToneSweep, env *memmap.SoundEnvelope, freq *memmap.SoundFreq) {
+	f := memmap.GetReg(freq)
+	s.freq = int(f & audio.FreqMask)
+	s.length.timed = f&audio.SoundTimed > 0
+	s.duty = int(memmap.GetReg(env)>>6) & 3
+	if sweep != nil {
+		s.sweep = memmap.GetReg(sweep)
+	}
+
+	if f&audio.SoundRestart == 0 {
+		return
+	}
+	memmap.SetReg(freq, f&^audio.SoundRestart)
+
+	e := memmap.GetReg(env)
+	s.on = e>>audio.EnvelopeVolumeShift > 0 || e&audio.EnvelopeUp > 0
+	s.step = 0
+	s.cycles = 0
+	s.sweepCycles = 0
+	s.length.start(64 - int(e&audio.LengthMask))
+	s.envelope.start(e)
+}
+
+// run runs the channel for the given number of cycles and returns it's output (-15 - 15).
+// freq is the frequency register which is updated by the frequency sweep
+func (s *square) run(cycles int, freq *memmap.SoundFreq) int {
+	if !s.on {
+		return 0
+	}
+
+	if !s.length.run(cycles) {
+		s.on = false
+		return 0
+	}
+	s.envelope.run(cycles)
+
+	if !s.runSweep(cycles, freq) {
+		s.on = false
+		return 0
+	}
+
+	// each square wave has 8 steps and plays at 131072/(2048 - freq) Hz
+	period := (2048 - s.freq) * 16
+	s.cycles += cycles
+	for s.cycles >= period {
+		s.cycles -= period
+		s.step = (s.step + 1) & 7
+	}
+
+	if s.step < dutyHigh[s.duty] {
+		return s.envelope.volume
+	}
+	return -s.envelope.volume
+}
+
+// runSweep runs the frequency sweep for the given number of cycles, it returns false if the frequency
+// sweeps past the highest frequency which turns the channel off
+func (s *square) runSweep(cycles int, freq *memmap.SoundFreq) bool {
+	time := int(s.sweep>>audio.SweepTimeShift) & 7
+	shift := int(s.sweep & audio.SweepShiftMask)
+	if time == 0 || shift == 0 {
+		return true
+	}
+
+	s.sweepCycles += cycles
+	for s.sweepCycles >= time*sweepCycles {
+		s.sweepCycles -= time * sweepCycles
+
+		delta := s.freq >> shift
+		if s.sweep&audio.SweepDown > 0 {
+			delta = -delta
+		}
+
+		if s.freq+delta > int(audio.FreqMask) {
+			return false
+		}
+		if s.freq+delta >= 0 {
+			s.freq += delta
+		}
+
+		// the new frequency is written back into the frequency register just like the GBA
+		f := memmap.GetReg(freq)
+		memmap.SetReg(freq, f&^audio.FreqMask|memmap.SoundFreq(s.freq))
+	}
+
+	return true
+}
+
+// wave is the emulated wave channel (sound 3). The emulated wave ram only has a single bank so the bank
+// controlls are ignored and the wave is latched from audio.WaveRAM each time the channel is restarted
+type wave struct {
+	on     bool
+	freq   int
+	volume memmap.WaveVolume
+	wave   [32]int
+	pos    int
+	cycles int
+	length length
+}
+
+// update reads the channels registers, restarting the channel if the restart bit is set
+func (w *wave) update() {
+	f := memmap.GetReg(audio.Sound3Freq)
+	w.freq = int(f & audio.FreqMask)
+	w.length.timed = f&audio.SoundTimed > 0
+	w.volume = memmap.GetReg(audio.Sound3Volume) &^ audio.WaveLengthMask
+
+	if memmap.GetReg(audio.Sound3Controll)&audio.WaveOn == 0 {
+		w.on = false
+	}
+
+	if f&audio.SoundRestart == 0 {
+		return
+	}
+	memmap.SetReg(audio.Sound3Freq, f&^audio.SoundRestart)
+
+	w.on = memmap.GetReg(audio.Sound3Controll)&audio.WaveOn > 0
+	w.pos = 0
+	w.cycles = 0
+	w.length.start(256 - int(memmap.GetReg(audio.Sound3Volume)&audio.WaveLengthMask))
+	for i, v := range audio.WaveRAM {
+		// each byte holds 2 samples with the high nibble played first
+		w.wave[i*4] = int(v>>4) & 0xF
+		w.wave[i*4+1] = int(v) & 0xF
+		w.wave[i*4+2] = int(v>>12) & 0xF
+		w.wave[i*4+3] = int(v>>8) & 0xF
+	}
+}
+
+// run runs the channel for the given number of cycles and returns it's output (-15 - 15)
+func (w *wave) run(cycles int) int {
+	if !w.on {
+		return 0
+	}
+
+	if !w.length.run(cycles) {
+		w.on = false
+		return 0
+	}
+
+	// each wave has 32 samples and plays at 65536/(2048 - freq) Hz
+	period := (2048 - w.freq) * 8
+	w.cycles += cycles
+	for w.cycles >= period {
+		w.cycles -= period
+		w.pos = (w.pos + 1) & 31
+	}
+
+	// center the 4 bit sample so the output matches the range of the other channels
+	v := w.wave[w.pos]*2 - 15
+	switch w.volume {
+	case audio.WaveVolume100:
+		return v
+	case audio.WaveVolume50:
+		return v / 2
+	case audio.WaveVolume25:
+		return v / 4
+	}
+	if w.volume&audio.WaveVolume75 > 0 {
+		return v * 3 / 4
+	}
+	return 0
+}
+
+// noise is the emulated noise channel (sound 4)
+type noise struct {
+	on       bool
+	period   int
+	narrow   bool
+	lfsr     int
+	cycles   int
+	length   length
+	envelope envelope
+}
+
+// update reads the channels registers, restarting the channel if the restart bit is set
+func (n *noise) update() {
+	f := memmap.GetReg(audio.Sound4Freq)
+	n.period = noisePeriod(f)
+	n.narrow = f&audio.Noise7 > 0
+	n.length.timed = f&audio.NoiseTimed > 0
+
+	if f&audio.NoiseRestart == 0 {
+		return
+	}
+	memmap.SetReg(audio.Sound4Freq, f&^audio.NoiseRestart)
+
+	e := memmap.GetReg(audio.Sound4Envelope)
+	n.on = e>>audio.EnvelopeVolumeShift > 0 || e&audio.EnvelopeUp > 0
+	n.lfsr = 0x7FFF
+	n.cycles = 0
+	n.length.start(64 - int(e&audio.LengthMask))
+	n.envelope.start(e)
+}
+
+// run runs the channel for the given number of cycles and returns it's output (-15 - 15)
+func (n *noise) run(cycles int) int {
+	if !n.on {
+		return 0
+	}
+
+	if !n.length.run(cycles) {
+		n.on = false
+		return 0
+	}
+	n.envelope.run(cycles)
+
+	n.cycles += cycles
+	for n.cycles >= n.period {
+		n.cycles -= n.period
+
+		bit := (n.lfsr ^ n.lfsr>>1) & 1
+		n.lfsr = n.lfsr>>1 | bit<<14
+		if n.narrow {
+			n.lfsr = n.lfsr&^0x40 | bit<<6
+		}
+	}
+
+	if n.lfsr&1 == 0 {
+		return n.envelope.volume
+	}
+	return -n.envelope.volume
+}
+
+// noisePeriod returns the number of cycles between each noise generator step.
+// The noise plays at 524288/ratio/2^(shift+1) Hz where a ratio of 0 is treated as 0.5
+func noisePeriod(f memmap.NoiseFreq) int {
+	ratio := int(f & audio.NoiseRatioMask)
+	shift := int(f>>audio.NoiseShiftShift) & 0xF
+
+	if ratio == 0 {
+		return 16 << (shift + 1)
+	}
+	return 32 * ratio << (shift + 1)
+}
@@ -0,0 +1,76 @@
+package apu
+
+import (
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+func Test_noisePeriod(t *testing.T) {
+	tests := []struct {
+		name string
+		freq memmap.NoiseFreq
+		want int
+	}{
+		{"ratio 0", 0x00, 32},
+		{"ratio 1", 0x01, 64},
+		{"ratio 3 shift 2", 0x23, 768},
+		{"width is ignored", 0x09, 64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := noisePeriod(tt.freq); got != tt.want {
+				t.Errorf("noisePeriod() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_length_run(t *testing.T) {
+	tests := []struct {
+		name   string
+		timed  bool
+		count  int
+		cycles int
+		want   bool
+	}{
+		{"continuous", false, 1, lengthCycles * 4, true},
+		{"timed", true, 2, lengthCycles, true},
+		{"timed and finished", true, 2, lengthCycles * 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := length{timed: tt.timed}
+			l.start(tt.count)
+			if got := l.run(tt.cycles); got != tt.want {
+				t.Errorf("length.run() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_envelope_run(t *testing.T) {
+	tests := []struct {
+		name   string
+		reg    memmap.SoundEnvelope
+		cycles int
+		want   int
+	}{
+		{"constant", 0xA000, envelopeCycles * 4, 10},
+		{"down", 0xA100, envelopeCycles * 3, 7},
+		{"up", 0xA900, envelopeCycles * 3, 13},
+		{"slow down", 0xA200, envelopeCycles * 3, 9},
+		{"stops at 0", 0x2100, envelopeCycles * 4, 0},
+		{"stops at 15", 0xE900, envelopeCycles * 4, 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := envelope{}
+			e.start(tt.reg)
+			e.run(tt.cycles)
+			if e.volume != tt.want {
+				t.Errorf("envelope.run() volume = %v, want %v", e.volume, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package apu
+
+import (
+	"github.com/bjatkin/flappy_boot/internal/emu/snapshot"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+// SaveState writes the state of the APU that is not kept in the sound registers
+func (a *APU) SaveState(w *snapshot.Writer) {
+	a.square1.save(w)
+	a.square2.save(w)
+	a.wave.save(w)
+	a.noise.save(w)
+
+	for i := range a.fifos {
+		snapshot.Ints(w, a.fifos[i].samples)
+		w.Int(int(a.fifos[i].sample))
+	}
+
+	snapshot.Ints(w, a.timers[:])
+	w.Int(a.frame)
+	w.Int(a.frac)
+}
+
+// LoadState reads the state written by SaveState
+func (a *APU) LoadState(r *snapshot.Reader) {
+	a.square1.load(r)
+	a.square2.load(r)
+	a.wave.load(r)
+	a.noise.load(r)
+
+	for i := range a.fifos {
+		// a fifo is only refilled once there's room for the refill so it never holds more than fifoLen samples
+		a.fifos[i].samples = snapshot.ReadInts[int8](r, fifoLen)
+		a.fifos[i].sample = int8(r.Int())
+	}
+
+	snapshot.ReadArray(r, a.timers[:])
+	a.frame = r.Int()
+	a.frac = r.Int()
+}
+
+// save writes the length counter
+func (l *length) save(w *snapshot.Writer) {
+	w.Bool(l.timed)
+	w.Int(l.count)
+	w.Int(l.cycles)
+}
+
+// load reads the length counter written by save
+func (l *length) load(r *snapshot.Reader) {
+	l.timed = r.Bool()
+	l.count = r.Int()
+	l.cycles = r.Int()
+}
+
+// save writes the volume envelope
+func (e *envelope) save(w *snapshot.Writer) {
+	w.Int(e.volume)
+	w.Int(e.step)
+	w.Bool(e.up)
+	w.Int(e.cycles)
+}
+
+// load reads the volume envelope written by save
+func (e *envelope) load(r *snapshot.Reader) {
+	e.volume = r.Int()
+	e.step = r.Int()
+	e.up = r.Bool()
+	e.cycles = r.Int()
+}
+
+// save writes the square channel
+func (s *square) save(w *snapshot.Writer) {
+	w.Bool(s.on)
+	w.Int(s.freq)
+	w.Int(s.duty)
+	w.Int(s.step)
+	w.Int(s.cycles)
+	s.length.save(w)
+	s.envelope.save(w)
+	w.Int(int(s.sweep))
+	w.Int(s.sweepCycles)
+}
+
+// load reads the square channel written by save
+func (s *square) load(r *snapshot.Reader) {
+	s.on = r.Bool()
+	s.freq = r.Int()
+	s.duty = r.Int()
+	s.step = r.Int()
+	s.cycles = r.Int()
+	s.length.load(r)
+	s.envelope.load(r)
+	s.sweep = memmap.ToneSweep(r.Int())
+	s.sweepCycles = r.Int()
+}
+
+// save writes the wave channel
+func (c *wave) save(w *snapshot.Writer) {
+	w.Bool(c.on)
+	w.Int(c.freq)
+	w.Int(int(c.volume))
+	snapshot.Ints(w, c.wave[:])
+	w.Int(c.pos)
+	w.Int(c.cycles)
+	c.length.save(w)
+}
+
+// load reads the wave channel written by save
+func (c *wave) load(r *snapshot.Reader) {
+	c.on = r.Bool()
+	c.freq = r.Int()
+	c.volume = memmap.WaveVolume(r.Int())
+	snapshot.ReadArray(r, c.wave[:])
+	c.pos = r.Int()
+	c.cycles = r.Int()
+	c.length.load(r)
+}
+
+// save writes the noise channel
+func (n *noise) save(w *snapshot.Writer) {
+	w.Bool(n.on)
+	w.Int(n.period)
+	w.Bool(n.narrow)
+	w.Int(n.lfsr)
+	w.Int(n.cycles)
+	n.length.save(w)
+	n.envelope.save(w)
+}
+
+// load reads the noise channel written by save
+func (n *noise) load(r *snapshot.Reader) {
+	n.on = r.Bool()
+	n.period = r.Int()
+	n.narrow = r.Bool()
+	n.lfsr = r.Int()
+	n.cycles = r.Int()
+	n.length.load(r)
+	n.envelope.load(r)
+}
@@ -0,0 +1,55 @@
+package apu
+
+import (
+	"sync"
+)
+
+// Stream buffers samples between the APU and an audio player that reads them from another goroutine.
+// It implements io.Reader and reads 16 bit little endian stereo samples
+type Stream struct {
+	mu   sync.Mutex
+	buff []byte
+
+	// maxLen is the most bytes the stream will buffer, the oldest samples are dropped once it's full.
+	// The emulator runs slightly faster than the GBA so without this the latency would keep growing
+	maxLen int
+}
+
+// NewStream creates a new stream that buffers at most maxSamples stereo samples
+func NewStream(maxSamples int) *Stream {
+	return &Stream{
+		maxLen: maxSamples * 4,
+	}
+}
+
+// Write adds the samples to the end of the stream
+func (s *Stream) Write(samples []int16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, v := range samples {
+		s.buff = append(s.buff, byte(v), byte(v>>8))
+	}
+
+	if over := len(s.buff) - s.maxLen; over > 0 {
+		s.buff = append(s.buff[:0], s.buff[over:]...)
+	}
+}
+
+// Read reads samples from the stream into p. Read never blocks, if the stream runs out of samples
+// the rest of p is filled with silence so the player keeps running while the emulator is stopped
+func (s *Stream) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// only read whole stereo samples
+	n := len(p) &^ 3
+	copied := copy(p[:n], s.buff)
+	s.buff = append(s.buff[:0], s.buff[copied:]...)
+
+	for i := copied; i < n; i++ {
+		p[i] = 0
+	}
+
+	return n, nil
+}
@@ -0,0 +1,68 @@
+package apu
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStream_Read(t *testing.T) {
+	tests := []struct {
+		name    string
+		max     int
+		samples []int16
+		readLen int
+		want    []byte
+	}{
+		{
+			"empty stream is silent",
+			4,
+			nil,
+			4,
+			[]byte{0, 0, 0, 0},
+		},
+		{
+			"samples are little endian",
+			4,
+			[]int16{0x0102, -2},
+			4,
+			[]byte{0x02, 0x01, 0xFE, 0xFF},
+		},
+		{
+			"short stream is padded with silence",
+			4,
+			[]int16{1, 2},
+			8,
+			[]byte{1, 0, 2, 0, 0, 0, 0, 0},
+		},
+		{
+			"only whole samples are read",
+			4,
+			[]int16{1, 2},
+			6,
+			[]byte{1, 0, 2, 0},
+		},
+		{
+			"oldest samples are dropped",
+			1,
+			[]int16{1, 2, 3, 4},
+			4,
+			[]byte{3, 0, 4, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStream(tt.max)
+			s.Write(tt.samples)
+
+			p := make([]byte, tt.readLen)
+			n, err := s.Read(p)
+			if err != nil {
+				t.Fatalf("Stream.Read() error = %v", err)
+			}
+
+			if got := p[:n]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Stream.Read() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package apu
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// WriteWAV writes the stereo samples to w as a 16 bit PCM WAV file played at SampleRate.
+// The samples are in the same order as APU.Samples with the left sample first
+func WriteWAV(w io.Writer, samples []int16) error {
+	const (
+		channels      = 2
+		bytesPerFrame = channels * 2
+	)
+	dataLen := uint32(len(samples) * 2)
+
+	header := struct {
+		RIFF          [4]byte
+		RIFFLen       uint32
+		WAVE          [4]byte
+		FMT           [4]byte
+		FMTLen        uint32
+		Format        uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+		Data          [4]byte
+		DataLen       uint32
+	}{
+		RIFF:          [4]byte{'R', 'I', 'F', 'F'},
+		RIFFLen:       36 + dataLen,
+		WAVE:          [4]byte{'W', 'A', 'V', 'E'},
+		FMT:           [4]byte{'f', 'm', 't', ' '},
+		FMTLen:        16,
+		Format:        1, // uncompressed PCM
+		Channels:      channels,
+		SampleRate:    SampleRate,
+		ByteRate:      SampleRate * bytesPerFrame,
+		BlockAlign:    bytesPerFrame,
+		BitsPerSample: 16,
+		Data:          [4]byte{'d', 'a', 't', 'a'},
+		DataLen:       dataLen,
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+
+	return binary.Write(w, binary.LittleEndian, samples)
+}
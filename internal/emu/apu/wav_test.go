@@ -0,0 +1,55 @@
+package apu
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestWriteWAV(t *testing.T) {
+	var buff bytes.Buffer
+	if err := WriteWAV(&buff, []int16{1, -1, 0x1234, -0x1234}); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+
+	got := buff.Bytes()
+	if len(got) != 44+8 {
+		t.Fatalf("WriteWAV() wrote %d bytes, want %d", len(got), 44+8)
+	}
+
+	tests := []struct {
+		name   string
+		offset int
+		want   []byte
+	}{
+		{"riff", 0, []byte("RIFF")},
+		{"wave", 8, []byte("WAVEfmt ")},
+		{"data", 36, []byte("data")},
+		{"samples", 44, []byte{0x01, 0x00, 0xFF, 0xFF, 0x34, 0x12, 0xCC, 0xED}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !bytes.Equal(got[tt.offset:tt.offset+len(tt.want)], tt.want) {
+				t.Errorf("WriteWAV() %s = %v, want %v", tt.name, got[tt.offset:tt.offset+len(tt.want)], tt.want)
+			}
+		})
+	}
+
+	fields := []struct {
+		name   string
+		offset int
+		want   uint32
+	}{
+		{"riff length", 4, 36 + 8},
+		{"sample rate", 24, SampleRate},
+		{"byte rate", 28, SampleRate * 4},
+		{"data length", 40, 8},
+	}
+	for _, tt := range fields {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := binary.LittleEndian.Uint32(got[tt.offset:]); got != tt.want {
+				t.Errorf("WriteWAV() %s = %d, want %d", tt.name, got, tt.want)
+			}
+		})
+	}
+}
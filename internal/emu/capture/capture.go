@@ -0,0 +1,176 @@
+package capture
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/dma"
+)
+
+const (
+	// frameSkip is the number of screen frames for each gif frame. Most gif players can't show
+	// frames faster than 50 times a second so only every other frame is kept
+	frameSkip = 2
+
+	// maxColors is the most colors a gif frame can use
+	maxColors = 256
+)
+
+// Frames returns the number of screen frames the GBA draws in the given number of seconds
+func Frames(seconds float64) int {
+	return int(seconds * dma.SystemClock / dma.ScreenRefresh)
+}
+
+// WritePNG writes the screen to a png file at path
+func WritePNG(path string, screen image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return png.Encode(f, screen)
+}
+
+// GIF records screen frames into an animated gif that plays at the same speed as the GBA
+type GIF struct {
+	gif gif.GIF
+
+	// frames is the number of screen frames that have been added
+	frames int
+}
+
+// NewGIF creates a new empty gif
+func NewGIF() *GIF {
+	return &GIF{}
+}
+
+// Add adds the next screen frame to the gif. Frames that are the same as the last frame in the gif
+// are merged into it so still screens don't take up any extra space
+func (g *GIF) Add(screen *image.RGBA) {
+	frame := g.frames
+	g.frames++
+	if frame%frameSkip != 0 {
+		return
+	}
+
+	// gif delays are in 100ths of a second, they're rounded from the start of each frame so the
+	// delays add up to the GBA's frame rate even though a single frame isn't a whole number of 100ths
+	delay := centiseconds(frame+frameSkip) - centiseconds(frame)
+
+	img := paletted(screen)
+	last := len(g.gif.Image) - 1
+	if last >= 0 && samePixels(g.gif.Image[last], img) {
+		g.gif.Delay[last] += delay
+		return
+	}
+
+	g.gif.Image = append(g.gif.Image, img)
+	g.gif.Delay = append(g.gif.Delay, delay)
+}
+
+// Frames returns the number of screen frames that have been added to the gif
+func (g *GIF) Frames() int {
+	return g.frames
+}
+
+// Encode writes the gif to w, it loops forever
+func (g *GIF) Encode(w io.Writer) error {
+	return gif.EncodeAll(w, &g.gif)
+}
+
+// WriteFile writes the gif to a file at path
+func (g *GIF) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return g.Encode(f)
+}
+
+// centiseconds returns the time the screen frame starts at in 100ths of a second
+func centiseconds(frame int) int {
+	cycles := int64(frame) * dma.ScreenRefresh * 100
+	return int((cycles + dma.SystemClock/2) / dma.SystemClock)
+}
+
+// paletted converts the screen into a paletted image. The screen only uses colors from the GBA's 15 bit
+// palette so most frames fit into a single gif palette exactly. If a frame has too many colors (e.g. from
+// color blending) the least used colors are swapped for the closest color in the palette
+func paletted(screen *image.RGBA) *image.Paletted {
+	counts := make(map[color.RGBA]int)
+	for i := 0; i < len(screen.Pix); i += 4 {
+		counts[pixel(screen.Pix[i:])]++
+	}
+
+	colors := make([]color.RGBA, 0, len(counts))
+	for c := range counts {
+		colors = append(colors, c)
+	}
+	// sort by use so the most used colors are kept, ties are sorted by value so the palette is always the same
+	sort.Slice(colors, func(i, j int) bool {
+		a, b := colors[i], colors[j]
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		return rgba(a) < rgba(b)
+	})
+	if len(colors) > maxColors {
+		colors = colors[:maxColors]
+	}
+
+	pal := make(color.Palette, len(colors))
+	index := make(map[color.RGBA]uint8, len(counts))
+	for i, c := range colors {
+		pal[i] = c
+		index[c] = uint8(i)
+	}
+
+	bounds := screen.Bounds()
+	img := image.NewPaletted(image.Rect(0, 0, bounds.Dx(), bounds.Dy()), pal)
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			c := pixel(screen.Pix[screen.PixOffset(bounds.Min.X+x, bounds.Min.Y+y):])
+			i, ok := index[c]
+			if !ok {
+				i = uint8(pal.Index(c))
+				index[c] = i
+			}
+			img.Pix[img.PixOffset(x, y)] = i
+		}
+	}
+
+	return img
+}
+
+// pixel returns the color of the pixel at the start of pix
+func pixel(pix []uint8) color.RGBA {
+	return color.RGBA{R: pix[0], G: pix[1], B: pix[2], A: pix[3]}
+}
+
+// rgba packs the color into a single value
+func rgba(c color.RGBA) uint32 {
+	return uint32(c.R)<<24 | uint32(c.G)<<16 | uint32(c.B)<<8 | uint32(c.A)
+}
+
+// samePixels returns true if both images have the same color at every pixel
+func samePixels(a, b *image.Paletted) bool {
+	if len(a.Pix) != len(b.Pix) {
+		return false
+	}
+
+	for i := range a.Pix {
+		if a.Palette[a.Pix[i]] != b.Palette[b.Pix[i]] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,158 @@
+package capture
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+// screen creates a 4x2 screen where each pixel is set by fill
+func screen(fill func(x, y int) color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			img.SetRGBA(x, y, fill(x, y))
+		}
+	}
+
+	return img
+}
+
+// solid returns a fill function for a single color
+func solid(c color.RGBA) func(x, y int) color.RGBA {
+	return func(x, y int) color.RGBA { return c }
+}
+
+var (
+	red   = color.RGBA{R: 255, A: 255}
+	green = color.RGBA{G: 255, A: 255}
+	blue  = color.RGBA{B: 255, A: 255}
+)
+
+func TestFrames(t *testing.T) {
+	tests := []struct {
+		name    string
+		seconds float64
+		want    int
+	}{
+		{"none", 0, 0},
+		{"one second", 1, 59},
+		{"five seconds", 5, 298},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Frames(tt.seconds); got != tt.want {
+				t.Errorf("Frames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGIF_Add(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames []color.RGBA
+		images int
+		delays []int
+	}{
+		{"single frame", []color.RGBA{red}, 1, []int{3}},
+		{"every other frame", []color.RGBA{red, red, green, green, blue, blue}, 3, []int{3, 4, 3}},
+		{"skipped frames", []color.RGBA{red, green, blue, red}, 2, []int{3, 4}},
+		{"still frames", []color.RGBA{red, red, red, red, blue, blue}, 2, []int{7, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGIF()
+			for _, c := range tt.frames {
+				g.Add(screen(solid(c)))
+			}
+
+			if g.Frames() != len(tt.frames) {
+				t.Errorf("GIF.Frames() = %d, want %d", g.Frames(), len(tt.frames))
+			}
+			if len(g.gif.Image) != tt.images {
+				t.Errorf("GIF.Add() added %d images, want %d", len(g.gif.Image), tt.images)
+			}
+			if diff := deep.Equal(g.gif.Delay, tt.delays); diff != nil {
+				t.Errorf("GIF.Add() delays %v", diff)
+			}
+		})
+	}
+}
+
+func TestGIF_Encode(t *testing.T) {
+	frames := []*image.RGBA{
+		screen(func(x, y int) color.RGBA {
+			return []color.RGBA{red, green, blue}[(x+y)%3]
+		}),
+		screen(func(x, y int) color.RGBA {
+			// every GBA color is 8.2258 times the 5 bit value
+			return color.RGBA{R: uint8(float64(x) * 8.2258), G: uint8(float64(y*4) * 8.2258), A: 255}
+		}),
+	}
+
+	g := NewGIF()
+	for _, frame := range frames {
+		g.Add(frame)
+		g.Add(frame)
+	}
+
+	var buf bytes.Buffer
+	if err := g.Encode(&buf); err != nil {
+		t.Fatalf("GIF.Encode() error = %v", err)
+	}
+
+	decoded, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("failed to decode the gif: %v", err)
+	}
+	if len(decoded.Image) != len(frames) {
+		t.Fatalf("GIF.Encode() has %d frames, want %d", len(decoded.Image), len(frames))
+	}
+
+	// every color should be kept exactly
+	for i, frame := range frames {
+		for y := 0; y < 2; y++ {
+			for x := 0; x < 4; x++ {
+				got := color.RGBAModel.Convert(decoded.Image[i].At(x, y))
+				if want := frame.RGBAAt(x, y); got != want {
+					t.Errorf("GIF.Encode() frame %d pixel %d,%d = %v, want %v", i, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func Test_paletted(t *testing.T) {
+	// 300 colors is too many for a single palette, the first 256 colors are each used twice so
+	// they're kept and the rest are swapped for the closest color
+	img := image.NewRGBA(image.Rect(0, 0, 556, 1))
+	for x := 0; x < 556; x++ {
+		c := x
+		if x >= 512 {
+			c = 256 + x - 512
+		} else {
+			c = x / 2
+		}
+		img.SetRGBA(x, 0, color.RGBA{R: uint8(c / 2), G: uint8(c % 2 * 200), A: 255})
+	}
+
+	got := paletted(img)
+	if len(got.Palette) != maxColors {
+		t.Fatalf("paletted() palette has %d colors, want %d", len(got.Palette), maxColors)
+	}
+	for x := 0; x < 512; x++ {
+		if c := got.At(x, 0); c != img.RGBAAt(x, 0) {
+			t.Errorf("paletted() pixel %d = %v, want %v", x, c, img.RGBAAt(x, 0))
+		}
+	}
+
+	// the closest color to R: 128 is R: 127 with the same green
+	if c := got.At(512, 0); c != (color.RGBA{R: 127, A: 255}) {
+		t.Errorf("paletted() pixel 512 = %v, want the closest color", c)
+	}
+}
@@ -0,0 +1,150 @@
+package input
+
+import (
+	"fmt"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	"gopkg.in/yaml.v2"
+)
+
+// Button is one of the GBA buttons. Buttons are in the same order as the bits in the key input register
+type Button int
+
+const (
+	A Button = iota
+	B
+	Select
+	Start
+	Right
+	Left
+	Up
+	Down
+	L
+	R
+
+	// Buttons is the number of GBA buttons
+	Buttons
+)
+
+// buttonNames are the names of each button in the mapping file
+var buttonNames = [Buttons]string{"A", "B", "Select", "Start", "Right", "Left", "Up", "Down", "L", "R"}
+
+// String returns the name of the button
+func (b Button) String() string {
+	if b < 0 || b >= Buttons {
+		return fmt.Sprintf("Button(%d)", int(b))
+	}
+
+	return buttonNames[b]
+}
+
+// Mask returns the bit of the button in the key input register
+func (b Button) Mask() memmap.Input {
+	return 1 << b
+}
+
+// Binding is the keyboard keys and gamepad buttons that press a GBA button. Keys are ebiten key names
+// (e.g. ArrowUp or Enter) and gamepad buttons are the names of the ebiten standard gamepad buttons
+// (e.g. RightBottom or CenterRight) or one of the left stick directions (e.g. LeftStickUp)
+type Binding struct {
+	Keys    []string `yaml:"Keys,flow"`
+	Gamepad []string `yaml:"Gamepad,flow"`
+}
+
+// Mapping binds the keyboard and gamepads to each of the GBA buttons
+type Mapping [Buttons]Binding
+
+// DefaultMapping returns the mapping that is used when there is no mapping file
+func DefaultMapping() *Mapping {
+	return &Mapping{
+		A:      {Keys: []string{"X", "C"}, Gamepad: []string{"RightBottom"}},
+		B:      {Keys: []string{"Z"}, Gamepad: []string{"RightRight"}},
+		Select: {Keys: []string{"Backspace"}, Gamepad: []string{"CenterLeft"}},
+		Start:  {Keys: []string{"Enter"}, Gamepad: []string{"CenterRight"}},
+		Right:  {Keys: []string{"ArrowRight"}, Gamepad: []string{"LeftRight", "LeftStickRight"}},
+		Left:   {Keys: []string{"ArrowLeft"}, Gamepad: []string{"LeftLeft", "LeftStickLeft"}},
+		Up:     {Keys: []string{"ArrowUp"}, Gamepad: []string{"LeftTop", "LeftStickUp"}},
+		Down:   {Keys: []string{"ArrowDown"}, Gamepad: []string{"LeftBottom", "LeftStickDown"}},
+		L:      {Keys: []string{"A"}, Gamepad: []string{"FrontTopLeft"}},
+		R:      {Keys: []string{"S"}, Gamepad: []string{"FrontTopRight"}},
+	}
+}
+
+// fileBinding is a binding in the mapping file, Keys and Gamepad are nil if they're left out of the file
+type fileBinding struct {
+	Keys    *[]string `yaml:"Keys"`
+	Gamepad *[]string `yaml:"Gamepad"`
+}
+
+// Parse parses a yaml mapping file. Buttons, keys or gamepad buttons that are left out of the file use
+// the default mapping
+func Parse(data []byte) (*Mapping, error) {
+	var file map[string]fileBinding
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse mapping file: %w", err)
+	}
+
+	m := DefaultMapping()
+	for name, binding := range file {
+		b, ok := ParseButton(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown button %q in mapping file", name)
+		}
+
+		if binding.Keys != nil {
+			m[b].Keys = *binding.Keys
+		}
+		if binding.Gamepad != nil {
+			m[b].Gamepad = *binding.Gamepad
+		}
+	}
+
+	return m, nil
+}
+
+// ParseButton returns the button with the given name
+func ParseButton(name string) (Button, bool) {
+	for b, n := range buttonNames {
+		if n == name {
+			return Button(b), true
+		}
+	}
+
+	return 0, false
+}
+
+// Marshal converts the mapping into a yaml mapping file, buttons are written in the same order as the
+// key input register
+func (m *Mapping) Marshal() ([]byte, error) {
+	file := make(yaml.MapSlice, 0, Buttons)
+	for b := range m {
+		file = append(file, yaml.MapItem{Key: Button(b).String(), Value: m[b]})
+	}
+
+	return yaml.Marshal(file)
+}
+
+// Input returns the key input register for the mapping. key and gamepad are called with each key and
+// gamepad button name in the mapping and should return true if it's held down
+func (m *Mapping) Input(key, gamepad func(name string) bool) memmap.Input {
+	reg := memmap.Input(0xFFFF)
+	for b, binding := range m {
+		if anyPressed(binding.Keys, key) || anyPressed(binding.Gamepad, gamepad) {
+			// the key input register is active low so pressed buttons are cleared
+			reg &^= Button(b).Mask()
+		}
+	}
+
+	return reg
+}
+
+// anyPressed returns true if any of the names are pressed
+func anyPressed(names []string, pressed func(name string) bool) bool {
+	for _, name := range names {
+		if pressed(name) {
+			return true
+		}
+	}
+
+	return false
+}
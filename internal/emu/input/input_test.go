@@ -0,0 +1,123 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/key"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	"github.com/go-test/deep"
+)
+
+func TestButton_Mask(t *testing.T) {
+	tests := []struct {
+		button Button
+		want   memmap.Input
+	}{
+		{A, key.AMask},
+		{B, key.BMask},
+		{Select, key.SelectMask},
+		{Start, key.StartMask},
+		{Right, key.RightMask},
+		{Left, key.LeftMask},
+		{Up, key.UpMask},
+		{Down, key.DownMask},
+		{L, key.LMask},
+		{R, key.RMask},
+	}
+	for _, tt := range tests {
+		t.Run(tt.button.String(), func(t *testing.T) {
+			if got := tt.button.Mask(); got != tt.want {
+				t.Errorf("Button.Mask() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	withA := DefaultMapping()
+	withA[A] = Binding{Keys: []string{"Space"}, Gamepad: []string{"RightBottom"}}
+
+	withStart := DefaultMapping()
+	withStart[Start].Gamepad = []string{}
+
+	tests := []struct {
+		name    string
+		data    string
+		want    *Mapping
+		wantErr bool
+	}{
+		{"empty", "", DefaultMapping(), false},
+		{"keys", "A:\n  Keys: [Space]\n", withA, false},
+		{"no gamepad buttons", "Start:\n  Gamepad: []\n", withStart, false},
+		{"unknown button", "X:\n  Keys: [Space]\n", nil, true},
+		{"unknown field", "A:\n  Mouse: [Left]\n", nil, true},
+		{"invalid yaml", "A: [", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("Parse() = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMapping_Marshal(t *testing.T) {
+	m := DefaultMapping()
+	m[L] = Binding{Keys: []string{"Q"}}
+
+	data, err := m.Marshal()
+	if err != nil {
+		t.Fatalf("Mapping.Marshal() error = %v", err)
+	}
+
+	got, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// a button with no gamepad buttons is written as an empty list so it's not replaced by the default
+	m[L].Gamepad = []string{}
+	if diff := deep.Equal(got, m); diff != nil {
+		t.Errorf("Parse(Mapping.Marshal()) = %v", diff)
+	}
+}
+
+func TestMapping_Input(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []string
+		gamepad []string
+		want    memmap.Input
+	}{
+		{"nothing pressed", nil, nil, 0xFFFF},
+		{"key", []string{"Enter"}, nil, 0xFFFF &^ key.StartMask},
+		{"second key", []string{"C"}, nil, 0xFFFF &^ key.AMask},
+		{"gamepad", nil, []string{"LeftStickUp", "FrontTopLeft"}, 0xFFFF &^ (key.UpMask | key.LMask)},
+		{"key and gamepad", []string{"X"}, []string{"RightBottom"}, 0xFFFF &^ key.AMask},
+		{"unbound key", []string{"Q"}, nil, 0xFFFF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pressed := func(names []string) func(string) bool {
+				return func(name string) bool {
+					for _, n := range names {
+						if n == name {
+							return true
+						}
+					}
+					return false
+				}
+			}
+
+			got := DefaultMapping().Input(pressed(tt.keys), pressed(tt.gamepad))
+			if got != tt.want {
+				t.Errorf("Mapping.Input() = %#x, want %#x", got, tt.want)
+			}
+		})
+	}
+}
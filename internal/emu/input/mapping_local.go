@@ -0,0 +1,34 @@
+//go:build local
+
+package input
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// Load loads the mapping file at path. If the file does not exist the default mapping is saved
+// to the file so that it can be edited
+func Load(path string) (*Mapping, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		m := DefaultMapping()
+		return m, Save(path, m)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+// Save saves the mapping to the mapping file at path
+func Save(path string, m *Mapping) error {
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o0664)
+}
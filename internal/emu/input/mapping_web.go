@@ -0,0 +1,31 @@
+//go:build web
+
+package input
+
+import (
+	"syscall/js"
+)
+
+// Load loads the mapping from local storage. If there is no mapping in local storage the default
+// mapping is used
+func Load(path string) (*Mapping, error) {
+	localStorage := js.Global().Get("localStorage")
+	data := localStorage.Call("getItem", path)
+	if data.IsNull() {
+		return DefaultMapping(), nil
+	}
+
+	return Parse([]byte(data.String()))
+}
+
+// Save saves the mapping to local storage
+func Save(path string, m *Mapping) error {
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+
+	localStorage := js.Global().Get("localStorage")
+	_ = localStorage.Call("setItem", path, string(data))
+	return nil
+}
@@ -0,0 +1,95 @@
+package input
+
+// Rebinder steps through each of the GBA buttons and binds the next key or gamepad button that's pressed to it
+type Rebinder struct {
+	mapping Mapping
+	button  Button
+}
+
+// NewRebinder creates a new rebinder that starts from the mapping, the mapping is not changed
+func NewRebinder(m *Mapping) *Rebinder {
+	r := &Rebinder{}
+	for b, binding := range m {
+		r.mapping[b] = Binding{
+			Keys:    append([]string{}, binding.Keys...),
+			Gamepad: append([]string{}, binding.Gamepad...),
+		}
+	}
+
+	return r
+}
+
+// Button returns the button that will be bound next
+func (r *Rebinder) Button() Button {
+	return r.button
+}
+
+// Binding returns the current binding of the button that will be bound next
+func (r *Rebinder) Binding() Binding {
+	if r.Done() {
+		return Binding{}
+	}
+
+	return r.mapping[r.button]
+}
+
+// Done returns true once every button has been bound or skipped
+func (r *Rebinder) Done() bool {
+	return r.button >= Buttons
+}
+
+// Mapping returns the new mapping
+func (r *Rebinder) Mapping() *Mapping {
+	m := r.mapping
+	return &m
+}
+
+// BindKey binds the key to the current button and moves to the next button. The key is removed
+// from any other buttons it was bound to. Keyboard bindings are replaced but gamepad bindings are kept
+func (r *Rebinder) BindKey(name string) {
+	if r.Done() {
+		return
+	}
+
+	for b := range r.mapping {
+		r.mapping[b].Keys = remove(r.mapping[b].Keys, name)
+	}
+	r.mapping[r.button].Keys = []string{name}
+	r.button++
+}
+
+// BindGamepad binds the gamepad button to the current button and moves to the next button. The gamepad
+// button is removed from any other buttons it was bound to. Gamepad bindings are replaced but keyboard
+// bindings are kept
+func (r *Rebinder) BindGamepad(name string) {
+	if r.Done() {
+		return
+	}
+
+	for b := range r.mapping {
+		r.mapping[b].Gamepad = remove(r.mapping[b].Gamepad, name)
+	}
+	r.mapping[r.button].Gamepad = []string{name}
+	r.button++
+}
+
+// Skip keeps the current binding and moves to the next button
+func (r *Rebinder) Skip() {
+	if r.Done() {
+		return
+	}
+
+	r.button++
+}
+
+// remove returns the names without the removed name
+func remove(names []string, removed string) []string {
+	kept := names[:0]
+	for _, name := range names {
+		if name != removed {
+			kept = append(kept, name)
+		}
+	}
+
+	return kept
+}
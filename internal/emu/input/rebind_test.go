@@ -0,0 +1,37 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestRebinder(t *testing.T) {
+	m := DefaultMapping()
+	r := NewRebinder(m)
+
+	// A takes the Z key from B, then B is skipped and Select is bound to a gamepad button
+	r.BindKey("Z")
+	r.Skip()
+	r.BindGamepad("RightBottom")
+	if r.Button() != Start {
+		t.Errorf("Rebinder.Button() = %v, want %v", r.Button(), Start)
+	}
+
+	for !r.Done() {
+		r.Skip()
+	}
+	r.BindKey("Q")
+
+	want := DefaultMapping()
+	want[A] = Binding{Keys: []string{"Z"}, Gamepad: []string{}}
+	want[B].Keys = []string{}
+	want[Select].Gamepad = []string{"RightBottom"}
+	if diff := deep.Equal(r.Mapping(), want); diff != nil {
+		t.Errorf("Rebinder.Mapping() = %v", diff)
+	}
+
+	if diff := deep.Equal(m, DefaultMapping()); diff != nil {
+		t.Errorf("NewRebinder() changed the mapping %v", diff)
+	}
+}
@@ -0,0 +1,108 @@
+package ppu
+
+import (
+	"github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+const (
+	// layerSprite is the layer index of the sprite layer, layers 0 - 3 are the backgrounds
+	layerSprite = 4
+
+	// layerBackdrop is the layer index of the backdrop color
+	layerBackdrop = 5
+)
+
+// layerPixel is a single pixel from one of the PPU layers
+type layerPixel struct {
+	color memmap.PaletteValue
+	layer int
+}
+
+// blend is the emulated state of the color special effects registers
+type blend struct {
+	mode   memmap.BlendControll
+	first  int
+	second int
+	eva    int
+	evb    int
+	evy    int
+}
+
+// update reads the latest color special effect values from the blend registers
+func (b *blend) update() {
+	controll := *display.BlendControll
+	b.mode = controll & display.BlendModeMask
+	b.first = int(controll & display.BlendFirstMask)
+	b.second = int((controll & display.BlendSecondMask) >> display.BlendSecondShift)
+
+	alpha := *display.BlendAlpha
+	b.eva = minInt(int(alpha&display.EVAMask), 16)
+	b.evb = minInt(int((alpha&display.EVBMask)>>display.EVBShift), 16)
+	b.evy = minInt(int(*display.BlendY&display.EVYMask), 16)
+}
+
+// apply applies the color special effects to the top and bottom pixels and returns the final color.
+// semi transparent sprites are always alpha blended if the bottom pixel is a second target
+func (b *blend) apply(top, bottom layerPixel, semiTransparent bool) memmap.PaletteValue {
+	isFirst := b.first&(1<<top.layer) > 0
+	isSecond := b.second&(1<<bottom.layer) > 0
+
+	if semiTransparent && isSecond {
+		return alphaBlend(top.color, bottom.color, b.eva, b.evb)
+	}
+	if !isFirst {
+		return top.color
+	}
+
+	switch b.mode {
+	case display.BlendModeAlpha:
+		if isSecond {
+			return alphaBlend(top.color, bottom.color, b.eva, b.evb)
+		}
+	case display.BlendModeWhite:
+		return brightness(top.color, true, b.evy)
+	case display.BlendModeBlack:
+		return brightness(top.color, false, b.evy)
+	}
+
+	return top.color
+}
+
+// alphaBlend blends the top and bottom colors using the top weight eva and the bottom weight evb.
+// weights are in 1/16ths and each channel is capped at 31
+func alphaBlend(top, bottom memmap.PaletteValue, eva, evb int) memmap.PaletteValue {
+	var c memmap.PaletteValue
+	for shift := 0; shift < 15; shift += 5 {
+		t := int(top>>shift) & 0x1F
+		b := int(bottom>>shift) & 0x1F
+		c |= memmap.PaletteValue(minInt((t*eva+b*evb)>>4, 31)) << shift
+	}
+
+	return c
+}
+
+// brightness moves each channel of the color towards white (or black if brighten is false) by evy 1/16ths
+func brightness(color memmap.PaletteValue, brighten bool, evy int) memmap.PaletteValue {
+	var c memmap.PaletteValue
+	for shift := 0; shift < 15; shift += 5 {
+		v := int(color>>shift) & 0x1F
+		if brighten {
+			v += (31 - v) * evy >> 4
+		} else {
+			v -= v * evy >> 4
+		}
+		c |= memmap.PaletteValue(v) << shift
+	}
+
+	return c
+}
+
+// minInt returns the smaller of a and b
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
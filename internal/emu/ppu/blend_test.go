@@ -0,0 +1,103 @@
+package ppu
+
+import (
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+func Test_blend_apply(t *testing.T) {
+	type args struct {
+		top             layerPixel
+		bottom          layerPixel
+		semiTransparent bool
+	}
+	tests := []struct {
+		name  string
+		blend blend
+		args  args
+		want  memmap.PaletteValue
+	}{
+		{
+			"blending off",
+			blend{mode: display.BlendModeOff, first: 0x3F, second: 0x3F},
+			args{
+				top:    layerPixel{color: 0x001F, layer: 0},
+				bottom: layerPixel{color: 0x7C00, layer: layerBackdrop},
+			},
+			0x001F,
+		},
+		{
+			"alpha blend",
+			blend{mode: display.BlendModeAlpha, first: 0x01, second: 0x20, eva: 8, evb: 8},
+			args{
+				top:    layerPixel{color: 0x001F, layer: 0},
+				bottom: layerPixel{color: 0x7C00, layer: layerBackdrop},
+			},
+			0x3C0F,
+		},
+		{
+			"alpha blend capped",
+			blend{mode: display.BlendModeAlpha, first: 0x01, second: 0x02, eva: 16, evb: 16},
+			args{
+				top:    layerPixel{color: 0x0010, layer: 0},
+				bottom: layerPixel{color: 0x0010, layer: 1},
+			},
+			0x001F,
+		},
+		{
+			"alpha blend bottom is not a second target",
+			blend{mode: display.BlendModeAlpha, first: 0x01, second: 0x02, eva: 8, evb: 8},
+			args{
+				top:    layerPixel{color: 0x001F, layer: 0},
+				bottom: layerPixel{color: 0x7C00, layer: layerBackdrop},
+			},
+			0x001F,
+		},
+		{
+			"brighten",
+			blend{mode: display.BlendModeWhite, first: 0x01, evy: 16},
+			args{
+				top:    layerPixel{color: 0x001F, layer: 0},
+				bottom: layerPixel{color: 0x7C00, layer: layerBackdrop},
+			},
+			0x7FFF,
+		},
+		{
+			"darken",
+			blend{mode: display.BlendModeBlack, first: 0x01, evy: 8},
+			args{
+				top:    layerPixel{color: 0x001F, layer: 0},
+				bottom: layerPixel{color: 0x7C00, layer: layerBackdrop},
+			},
+			0x0010,
+		},
+		{
+			"darken not a first target",
+			blend{mode: display.BlendModeBlack, first: 0x02, evy: 8},
+			args{
+				top:    layerPixel{color: 0x001F, layer: 0},
+				bottom: layerPixel{color: 0x7C00, layer: layerBackdrop},
+			},
+			0x001F,
+		},
+		{
+			"semi transparent sprite",
+			blend{mode: display.BlendModeBlack, second: 0x01, eva: 8, evb: 8, evy: 16},
+			args{
+				top:             layerPixel{color: 0x001F, layer: layerSprite},
+				bottom:          layerPixel{color: 0x7C00, layer: 0},
+				semiTransparent: true,
+			},
+			0x3C0F,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.blend.apply(tt.args.top, tt.args.bottom, tt.args.semiTransparent); got != tt.want {
+				t.Errorf("blend.apply() = %#04x, want %#04x", got, tt.want)
+			}
+		})
+	}
+}
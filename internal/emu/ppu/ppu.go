@@ -7,7 +7,6 @@ import (
 	"github.com/bjatkin/flappy_boot/internal/hardware/display"
 	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
 	"github.com/bjatkin/flappy_boot/internal/hardware/sprite"
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
 // transparent is the transparent color to use for RGB15 images
@@ -63,18 +62,7 @@ func (i *RGB15) ColAt(x, y int) color.RGBA {
 		return color.RGBA{}
 	}
 
-	b := int(c&0b01111100_00000000) >> 0xA
-	g := int(c&0b00000011_11100000) >> 0x5
-	r := int(c & 0b00000000_00011111)
-
-	ret := color.RGBA{
-		R: uint8(float64(r) * 8.2258),
-		G: uint8(float64(g) * 8.2258),
-		B: uint8(float64(b) * 8.2258),
-		A: 255,
-	}
-
-	return ret
+	return rgb15ToRGBA(c)
 }
 
 // Set sets the color of the pixel at x,y in the image to the given value
@@ -109,39 +97,33 @@ type Background struct {
 	bgHOffset   *uint16
 	bgVOffset   *uint16
 	enableCheck memmap.DisplayControll
+	layer       int
 
-	Enabled       bool
-	Pos           v2
-	Size          v2
-	Priority      int
+	Enabled  bool
+	Pos      v2
+	Size     v2
+	Priority int
+	Mosaic   bool
+	// Image holds the palette index of each pixel in the background, index 0 is transparent.
+	// If Direct is set the image holds colors instead, this is used by the direct color bitmap modes
 	Image         *RGB15
+	Direct        bool
 	SkipGFXUpdate bool
 }
 
-// update updates the backgrounds individual fields and gfx data
+// update updates the backgrounds individual fields and gfx data. It's called once at the start of every frame
 func (b *Background) update(palDirty bool) {
-	b.Enabled = *display.Controll&b.enableCheck > 0
+	b.updateLine()
 	if !b.Enabled {
 		return
 	}
 
-	switch *b.controll & display.BGSizeMask {
-	case display.BGSizeLarge:
-		b.Size = v2{X: 2, Y: 2}
-	case display.BGSizeTall:
-		b.Size = v2{X: 1, Y: 2}
-	case display.BGSizeWide:
-		b.Size = v2{X: 2, Y: 1}
-	case display.BGSizeSmall:
-		b.Size = v2{X: 1, Y: 1}
-	}
-
-	b.Pos = v2{
-		X: int(*b.bgHOffset),
-		Y: int(*b.bgVOffset),
+	if mode := *display.Controll & display.ModeMask; isBitmapMode(mode) {
+		b.updateBitmap(mode)
+		return
 	}
-	b.Priority = int(*b.controll & display.PriorityMask)
 
+	b.Direct = false
 	if b.SkipGFXUpdate && !palDirty {
 		return
 	}
@@ -156,11 +138,9 @@ func (b *Background) update(palDirty bool) {
 	for i := range tileMap {
 		// palette data can differ across bg tiles so we need to calculate it inside the loop
 		palette := int(tileMap[i]&0xF000) >> 0xc
-		palOffset := memmap.PaletteOffset * palette
-		palData := memmap.Palette[palOffset : palOffset+16]
 		b.setTile(
 			gfxData,
-			palData,
+			memmap.PaletteOffset*palette,
 			tileData{
 				mapOffset: i,
 				hflip:     (tileMap[i] & 0x0400) > 0,
@@ -171,8 +151,44 @@ func (b *Background) update(palDirty bool) {
 	}
 }
 
-// setTile draws the tiles pixels onto the background image
-func (b *Background) setTile(gfxData []memmap.VRAMValue, palData []memmap.PaletteValue, data tileData) {
+// updateLine reads the backgrounds registers. It's called at the start of every scan line so
+// the registers can be changed between lines by raster effects
+func (b *Background) updateLine() {
+	b.Enabled = *display.Controll&b.enableCheck > 0
+	if !b.Enabled {
+		return
+	}
+
+	b.Priority = int(*b.controll & display.PriorityMask)
+	b.Mosaic = *b.controll&display.Mosaic > 0
+
+	if isBitmapMode(*display.Controll & display.ModeMask) {
+		// Only background 2 can be used in the bitmap modes and it can not be scrolled
+		b.Enabled = b.layer == 2
+		b.Size = v2{X: 1, Y: 1}
+		b.Pos = v2{}
+		return
+	}
+
+	switch *b.controll & display.BGSizeMask {
+	case display.BGSizeLarge:
+		b.Size = v2{X: 2, Y: 2}
+	case display.BGSizeTall:
+		b.Size = v2{X: 1, Y: 2}
+	case display.BGSizeWide:
+		b.Size = v2{X: 2, Y: 1}
+	case display.BGSizeSmall:
+		b.Size = v2{X: 1, Y: 1}
+	}
+
+	b.Pos = v2{
+		X: int(*b.bgHOffset),
+		Y: int(*b.bgVOffset),
+	}
+}
+
+// setTile draws the tiles palette indexes onto the background image, palOffset is the offset of the tiles palette
+func (b *Background) setTile(gfxData []memmap.VRAMValue, palOffset int, data tileData) {
 	var indexes [16 * 4]int
 	for i := 0; i < 16; i++ {
 		quartet := getIndexQuartet(i, gfxData[data.gfxOffset*16:])
@@ -204,9 +220,53 @@ func (b *Background) setTile(gfxData []memmap.VRAMValue, palData []memmap.Palett
 			// TODO: instead of setting each pixel it would be better to copy data directly between images
 			i := indexes[y*8+x]
 			if i == 0 {
-				b.Image.Set(px, py, transparent)
+				b.Image.Set(px, py, 0)
 			} else {
-				b.Image.Set(px, py, palData[i])
+				b.Image.Set(px, py, memmap.PaletteValue(palOffset+i))
+			}
+		}
+	}
+}
+
+// isBitmapMode returns true if the display mode is one of the bitmap modes
+func isBitmapMode(mode memmap.DisplayControll) bool {
+	return mode == display.Mode3 || mode == display.Mode4 || mode == display.Mode5
+}
+
+// updateBitmap draws the bitmap in VRAM onto the background image. Mode4 bitmaps use palette indexes
+// while Mode3 and Mode5 bitmaps use colors directly
+func (b *Background) updateBitmap(mode memmap.DisplayControll) {
+	b.Direct = mode != display.Mode4
+
+	page := memmap.VRAM
+	if mode != display.Mode3 && *display.Controll&display.PageB > 0 {
+		page = memmap.VRAM[display.PageOffset:]
+	}
+
+	width, height := display.Width, display.Height
+	if mode == display.Mode5 {
+		width, height = 160, 128
+	}
+
+	for y := 0; y < display.Height; y++ {
+		for x := 0; x < display.Width; x++ {
+			if x >= width || y >= height {
+				if b.Direct {
+					b.Image.Set(x, y, transparent)
+				} else {
+					b.Image.Set(x, y, 0)
+				}
+				continue
+			}
+
+			i := y*width + x
+			switch mode {
+			case display.Mode4:
+				// mode 4 pixels are 8 bit palette indexes, index 0 is transparent
+				index := (page[i/2] >> (8 * (i % 2))) & 0xFF
+				b.Image.Set(x, y, memmap.PaletteValue(index))
+			default:
+				b.Image.Set(x, y, memmap.PaletteValue(page[i]&0x7FFF))
 			}
 		}
 	}
@@ -223,101 +283,242 @@ func (b *Background) getTileView(tile int) image.Rectangle {
 	return image.Rect(x, y, x+8, y+8)
 }
 
+// pixel returns the color of the background at the screen location x,y. The backgrounds scroll offsets are
+// taken into account and the background wraps in both directions just like it does on the GBA.
+// transparent is returned if the pixel is transparent
+func (b *Background) pixel(x, y int) memmap.PaletteValue {
+	width := b.Size.X * 256
+	height := b.Size.Y * 256
+	c := b.Image.At((x+b.Pos.X)%width, (y+b.Pos.Y)%height)
+	if b.Direct {
+		return c
+	}
+	if c == 0 {
+		return transparent
+	}
+
+	return memmap.Palette[c]
+}
+
 // Sprite is a PPU sprite
 type Sprite struct {
 	attrs *sprite.Attrs
 
-	Enabled  bool
-	Size     v2
-	Pos      v2
-	HFlip    bool
-	VFlip    bool
-	Priority int
-	Image    *ebiten.Image
+	Enabled    bool
+	Size       v2
+	Pos        v2
+	HFlip      bool
+	VFlip      bool
+	Priority   int
+	Tile       int
+	Palette    int
+	Affine     bool
+	DoubleSize bool
+	// SemiTransparent sprites are always alpha blended onto the layer below them
+	SemiTransparent bool
+	// Window sprites are not drawn, instead they make up the shape of the sprite window
+	Window bool
+	Mosaic bool
+	// Matrix is the affine matrix [pa, pb, pc, pd] as 8.8 fixed point numbers
+	Matrix [4]int
 }
 
-// update updates s sprites fields and gfx
+// update updates s sprites fields
 func (s *Sprite) update() {
-	s.Enabled = (s.attrs.Attr0 & sprite.SpriteModeMask) != sprite.Hide
-	// TODO: clear out the image here (base it on the last size to improve performance)
+	mode := s.attrs.Attr0 & sprite.SpriteModeMask
+	s.Enabled = mode != sprite.Hide
 	if !s.Enabled {
 		return
 	}
 
+	s.SemiTransparent = s.attrs.Attr0&sprite.EffectMask == sprite.Blend
+	s.Window = s.attrs.Attr0&sprite.EffectMask == sprite.Win
+	s.Mosaic = s.attrs.Attr0&sprite.Mosaic > 0
+	s.Affine = mode == sprite.Affine || mode == sprite.AffineDBL
+	s.DoubleSize = mode == sprite.AffineDBL
+
+	s.Size = s.sizeAsV2()
+	if s.Size.X == 0 {
+		// sprites with the prohibited shape are not drawn
+		s.Enabled = false
+		return
+	}
+
 	s.Pos = v2{
 		X: int(s.attrs.Attr1 & sprite.XMask),
 		Y: int(s.attrs.Attr0 & sprite.YMask),
 	}
-	s.Size = s.sizeAsV2()
-	// TODO: skip anything off screen (use the % calculation se we don't kill sprite wrapping)
+
+	// sprite coordinates wrap around so sprites can be partially drawn off the top and left of the screen
+	bounds := s.bounds()
+	if s.Pos.X+bounds.X > 512 {
+		s.Pos.X -= 512
+	}
+	if s.Pos.Y+bounds.Y > 256 {
+		s.Pos.Y -= 256
+	}
 
 	s.Priority = int(s.attrs.Attr2&sprite.PriorityMask) >> sprite.PriorityShift
 
-	s.VFlip = (s.attrs.Attr1 & sprite.VMirriorMask) > 0
-	s.HFlip = (s.attrs.Attr1 & sprite.HMirriorMask) > 0
+	if s.Affine {
+		// the flip bits are part of the affine index for affine sprites
+		s.VFlip = false
+		s.HFlip = false
+
+		index := (s.attrs.Attr1 & sprite.AffineIndexMask) >> sprite.AffineIndexShift
+		affine := &sprite.AffineOAM[index]
+		s.Matrix = [4]int{
+			int(int16(affine.Pa)),
+			int(int16(affine.Pb)),
+			int(int16(affine.Pc)),
+			int(int16(affine.Pd)),
+		}
+	} else {
+		s.VFlip = (s.attrs.Attr1 & sprite.VMirriorMask) > 0
+		s.HFlip = (s.attrs.Attr1 & sprite.HMirriorMask) > 0
+	}
 
-	tile := (s.attrs.Attr2 & sprite.IndexMask)
-	vramOffset := memmap.CharBlockOffset*4 + tile*16
-	gfxData := memmap.VRAM[vramOffset:]
+	s.Tile = int(s.attrs.Attr2 & sprite.IndexMask)
+	s.Palette = int(s.attrs.Attr2&sprite.PalMask) >> sprite.PalShift
+}
 
-	pal := (s.attrs.Attr2 & sprite.PalMask) >> sprite.PalShift
-	palOffset := memmap.PaletteOffset * (pal + 16)
-	palData := memmap.Palette[palOffset : palOffset+16]
+// draw draws the sprites pixels into the sprite layer. Pixels that have already been set by a sprite
+// with a higher priority, or the same priority and a lower OAM index are left alone, this matches
+// the GBA where the sprite with the lowest OAM index is drawn on top of other sprites with the same priority.
+// mosaic is the size of the sprite mosaic blocks. In the bitmap modes the bitmap uses the
+// first 512 sprite tiles so sprites using those tiles are not drawn
+func (s *Sprite) draw(layer *spriteLayer, mapping1D bool, mosaic v2, bitmap bool) {
+	if !s.Enabled {
+		return
+	}
+	if bitmap && s.Tile < 512 {
+		return
+	}
+	if s.Pos.X >= display.Width || s.Pos.Y >= display.Height {
+		return
+	}
 
-	s.updateImage(gfxData, palData)
+	// in 1D mapping sprite tiles are laid out one after another, in 2D mapping
+	// sprite tiles are laid out in a 32x32 tile matrix
+	rowStride := 32
+	if mapping1D {
+		rowStride = s.Size.X / 8
+	}
+
+	palOffset := memmap.PaletteOffset * (s.Palette + 16)
+	gfxData := memmap.VRAM[memmap.CharBlockOffset*4:]
+
+	bounds := s.bounds()
+	for y := 0; y < bounds.Y; y++ {
+		sy := s.Pos.Y + y
+		if sy < 0 || sy >= display.Height {
+			continue
+		}
+
+		for x := 0; x < bounds.X; x++ {
+			sx := s.Pos.X + x
+			if sx < 0 || sx >= display.Width {
+				continue
+			}
+
+			i := sy*display.Width + sx
+			if !s.Window && layer.priority[i] <= s.Priority {
+				continue
+			}
+
+			mx, my := x, y
+			if s.Mosaic {
+				// mosaic blocks are aligned to the screen so every pixel in a block uses the blocks top left pixel
+				mx -= sx % mosaic.X
+				my -= sy % mosaic.Y
+				if mx < 0 || my < 0 {
+					continue
+				}
+			}
+
+			tx, ty, ok := s.texel(mx, my, bounds)
+			if !ok {
+				continue
+			}
+
+			tile := (s.Tile + (ty/8)*rowStride + tx/8) % 1024
+			index := getIndex(gfxData[tile*memmap.TileOffset4:], tx%8, ty%8)
+			if index == 0 {
+				continue
+			}
+
+			if s.Window {
+				layer.window[i] = true
+				continue
+			}
+
+			layer.colors[i] = memmap.PaletteValue(palOffset + index)
+			layer.priority[i] = s.Priority
+			layer.semiTransparent[i] = s.SemiTransparent
+		}
+	}
 }
 
-// updateImage updates the sprites image data
-func (s *Sprite) updateImage(gfxData []memmap.VRAMValue, palData []memmap.PaletteValue) {
-	var indexes []int
-	for i := 0; i < (s.Size.X/4)*s.Size.Y; i++ {
-		quartet := getIndexQuartet(i, gfxData)
-		indexes = append(indexes, quartet[:]...)
+// bounds returns the size of the area the sprite is drawn in, double size affine sprites
+// are drawn in an area twice as large as the sprite
+func (s *Sprite) bounds() v2 {
+	if s.DoubleSize {
+		return v2{X: s.Size.X * 2, Y: s.Size.Y * 2}
 	}
 
-	// fill all sprites with a transparent color to start because sprites will randomly shift around in memory
-	// due to the fact that the underlying implementation uses a map
-	s.Image.Fill(color.RGBA{})
+	return s.Size
+}
 
-	for i := 0; i < (s.Size.X*s.Size.Y)/64; i++ {
-		for y := 0; y < 8; y++ {
-			for x := 0; x < 8; x++ {
-				color := palColorToRGBA(palData, indexes[i*64+y*8+x])
-				tileX := (i * 8) % s.Size.X
-				tileY := ((i * 8) / s.Size.X) * 8
-				s.Image.Set(x+tileX, y+tileY, color)
-			}
+// texel converts the x,y location inside the sprites bounds into the location of the pixel in the sprites texture.
+// if the location maps to a pixel outside of the sprite ok is false
+func (s *Sprite) texel(x, y int, bounds v2) (int, int, bool) {
+	if !s.Affine {
+		if s.HFlip {
+			x = s.Size.X - 1 - x
 		}
+		if s.VFlip {
+			y = s.Size.Y - 1 - y
+		}
+
+		return x, y, true
+	}
 
+	// affine sprites are rotated and scaled around their center
+	dx := x - bounds.X/2
+	dy := y - bounds.Y/2
+	tx := (s.Matrix[0]*dx+s.Matrix[1]*dy)>>8 + s.Size.X/2
+	ty := (s.Matrix[2]*dx+s.Matrix[3]*dy)>>8 + s.Size.Y/2
+	if tx < 0 || tx >= s.Size.X || ty < 0 || ty >= s.Size.Y {
+		return 0, 0, false
 	}
+
+	return tx, ty, true
 }
 
 // sizeAsV2 converts the sprites size to a v2
 func (s *Sprite) sizeAsV2() v2 {
-	ref := [...]v2{
-		{X: 8, Y: 8},
-		{X: 16, Y: 8},
-		{X: 8, Y: 16},
+	x, y := sprite.Dimensions(s.attrs.Attr0, s.attrs.Attr1)
+	return v2{X: x, Y: y}
+}
 
-		{X: 16, Y: 16},
-		{X: 32, Y: 8},
-		{X: 8, Y: 32},
+// noSprite is the sprite layer priority used for pixels that are not covered by any sprite
+const noSprite = 4
 
-		{X: 32, Y: 32},
-		{X: 32, Y: 16},
-		{X: 16, Y: 32},
+// spriteLayer holds the combined pixels of every sprite for a single frame along with each pixels priority.
+// colors holds the palette index of each pixel so palette changes between scan lines are drawn
+type spriteLayer struct {
+	colors          [display.Width * display.Height]memmap.PaletteValue
+	priority        [display.Width * display.Height]int
+	semiTransparent [display.Width * display.Height]bool
+	window          [display.Width * display.Height]bool
+}
 
-		{X: 64, Y: 64},
-		{X: 64, Y: 32},
-		{X: 32, Y: 64},
+// clear removes all the sprite pixels from the layer
+func (l *spriteLayer) clear() {
+	for i := range l.priority {
+		l.priority[i] = noSprite
+		l.window[i] = false
 	}
-
-	shape := int(s.attrs.Attr0&sprite.ShapeMask) >> 0xE
-	size := int(s.attrs.Attr1&sprite.SizeMask) >> 0xE
-
-	delta := ref[size*3+shape]
-
-	return delta
 }
 
 // PPU is a emulated GBA PPU. It only emulates the pieces of the PPU used by flappy boot
@@ -326,8 +527,17 @@ type PPU struct {
 	Backgrounds [4]Background
 	lastPal     [512]memmap.PaletteValue
 	palDirty    bool
+	blend       blend
+	windows     windows
+	bgMosaic    v2
+	sprMosaic   v2
+	sprites     *spriteLayer
 	backBuffer  *RGB15
 	Screen      *image.RGBA
+
+	// HBlank is called after each scan line is drawn, the VCount register is set to the line that was just drawn.
+	// It can be used to raise the HBlank interrupt so registers can be changed between lines
+	HBlank func()
 }
 
 // New creates a new PPU struct
@@ -339,6 +549,7 @@ func New() *PPU {
 				bgHOffset:   display.BG0HOffset,
 				bgVOffset:   display.BG0VOffset,
 				enableCheck: display.BG0,
+				layer:       0,
 
 				Image: NewRGB15(512, 512),
 			},
@@ -347,6 +558,7 @@ func New() *PPU {
 				bgHOffset:   display.BG1HOffset,
 				bgVOffset:   display.BG1VOffset,
 				enableCheck: display.BG1,
+				layer:       1,
 
 				Image: NewRGB15(512, 512),
 			},
@@ -355,6 +567,7 @@ func New() *PPU {
 				bgHOffset:   display.BG2HOffset,
 				bgVOffset:   display.BG2VOffset,
 				enableCheck: display.BG2,
+				layer:       2,
 
 				Image: NewRGB15(512, 512),
 			},
@@ -363,23 +576,24 @@ func New() *PPU {
 				bgHOffset:   display.BG3HOffset,
 				bgVOffset:   display.BG3VOffset,
 				enableCheck: display.BG3,
+				layer:       3,
 
 				Image: NewRGB15(512, 512),
 			},
 		},
-		backBuffer: NewRGB15(240, 160),
-		Screen:     image.NewRGBA(image.Rect(0, 0, 240, 160)),
+		sprites:    &spriteLayer{},
+		backBuffer: NewRGB15(display.Width, display.Height),
+		Screen:     image.NewRGBA(image.Rect(0, 0, display.Width, display.Height)),
 	}
 
 	for i := range ppu.Sprites {
-		ppu.Sprites[i].Image = ebiten.NewImage(64, 64)
 		ppu.Sprites[i].attrs = &sprite.OAM[i]
 	}
 
 	return ppu
 }
 
-// Update updates the ppu resources
+// Update updates the ppu resources and renders a new frame into the Screen image
 func (p *PPU) Update() {
 	// update palette cache to prevent updating background graphics unessiarily
 	for i := range memmap.Palette {
@@ -396,37 +610,150 @@ func (p *PPU) Update() {
 		p.Backgrounds[i].update(p.palDirty)
 	}
 
-	for i := range p.Sprites[:60] {
-		p.Sprites[i].update()
+	p.updateMosaic()
+
+	p.sprites.clear()
+	if *display.Controll&display.Sprites > 0 {
+		mapping1D := *display.Controll&display.Sprite1D > 0
+		bitmap := isBitmapMode(*display.Controll & display.ModeMask)
+		for i := range p.Sprites {
+			p.Sprites[i].update()
+			p.Sprites[i].draw(p.sprites, mapping1D, p.sprMosaic, bitmap)
+		}
 	}
 
-	for i := 3; i >= 0; i-- {
-		for _, bg := range p.Backgrounds {
-			if bg.Priority != i {
-				continue
-			}
-			if !bg.Enabled {
-				continue
+	// backgrounds, windows and color effects are drawn one scan line at a time
+	// so changes made to the registers durring an HBlank show up on the next line
+	for y := 0; y < display.Height; y++ {
+		memmap.SetReg(display.VCount, memmap.DisplayVCount(y))
+		if y > 0 {
+			for i := range p.Backgrounds {
+				p.Backgrounds[i].updateLine()
 			}
+			p.updateMosaic()
+		}
+
+		p.blend.update()
+		p.windows.update()
+		p.composeLine(y)
 
-			x := -(bg.Pos.X % (bg.Size.X * 256))
-			y := -bg.Pos.Y
-			p.backBuffer.DrawImage(x, y, bg.Image)
-			// duplicate the BG for horizontal scrolling
-			p.backBuffer.DrawImage(x+(bg.Size.X*256), y, bg.Image)
+		if p.HBlank != nil {
+			p.HBlank()
+		}
+	}
+	memmap.SetReg(display.VCount, display.Height)
+
+	for y := 0; y < display.Height; y++ {
+		for x := 0; x < display.Width; x++ {
+			i := p.Screen.PixOffset(x, y)
+			c := rgb15ToRGBA(p.backBuffer.At(x, y))
+			p.Screen.Pix[i] = c.R
+			p.Screen.Pix[i+1] = c.G
+			p.Screen.Pix[i+2] = c.B
+			p.Screen.Pix[i+3] = c.A
 		}
+	}
+
+	p.palDirty = false
+}
+
+// Redraw redraws the graphics of every background on the next update, even the backgrounds that skip
+// graphics updates. It's needed when VRAM changes without the palette changing, like when a save state is loaded
+func (p *PPU) Redraw() {
+	p.palDirty = true
+}
 
-		// TOOD: we should also draw the sprites to the back buffer here but my first attempt ended
-		// up killing performance so I'll need to make another atempt in the future.
+// updateMosaic reads the latest mosaic sizes from the mosaic register
+func (p *PPU) updateMosaic() {
+	mosaic := *display.MosaicSize
+	p.bgMosaic = v2{
+		X: int(mosaic&display.MosaicBGHMask) + 1,
+		Y: int((mosaic&display.MosaicBGVMask)>>display.MosaicBGVShift) + 1,
 	}
+	p.sprMosaic = v2{
+		X: int((mosaic&display.MosaicSprHMask)>>display.MosaicSprHShift) + 1,
+		Y: int((mosaic&display.MosaicSprVMask)>>display.MosaicSprVShift) + 1,
+	}
+}
 
-	for y := 0; y < 160; y++ {
-		for x := 0; x < 240; x++ {
-			p.Screen.Set(x, y, p.backBuffer.ColAt(x, y))
+// composeLine combines the sprite layer and all the enabled backgrounds into line y of the back buffer.
+// for each pixel, layers are checked from the highest priority (0) to the lowest priority (3).
+// sprites are drawn above backgrounds with the same priority, and backgrounds with a lower
+// index are drawn above backgrounds with a higher index. If every layer is transparent the
+// backdrop color is used. Layers hidden by the windows are skipped and the top two layers of each pixel
+// are then combined using the color effects
+func (p *PPU) composeLine(y int) {
+	// order the enabled backgrounds by their priority so the inner loop only needs to walk a short list
+	var order [4]*Background
+	var count int
+	for prio := 0; prio < 4; prio++ {
+		for i := range p.Backgrounds {
+			bg := &p.Backgrounds[i]
+			if bg.Enabled && bg.Priority == prio {
+				order[count] = bg
+				count++
+			}
 		}
 	}
 
-	p.palDirty = false
+	backdrop := layerPixel{color: memmap.Palette[0], layer: layerBackdrop}
+	for x := 0; x < display.Width; x++ {
+		i := y*display.Width + x
+		mask := p.windows.mask(x, y, p.sprites.window[i])
+		sprPrio := p.sprites.priority[i]
+		sprite := layerPixel{color: memmap.Palette[p.sprites.colors[i]], layer: layerSprite}
+		hasSprite := sprPrio != noSprite && mask&(1<<layerSprite) > 0
+
+		// find the top two visible layers for this pixel
+		var pixels [2]layerPixel
+		var n int
+		for _, bg := range order[:count] {
+			if hasSprite && sprPrio <= bg.Priority {
+				pixels[n] = sprite
+				n++
+				hasSprite = false
+			}
+			if n == 2 {
+				break
+			}
+
+			if mask&(1<<bg.layer) == 0 {
+				continue
+			}
+
+			bx, by := x, y
+			if bg.Mosaic {
+				bx -= x % p.bgMosaic.X
+				by -= y % p.bgMosaic.Y
+			}
+
+			bc := bg.pixel(bx, by)
+			if bc == transparent {
+				continue
+			}
+
+			pixels[n] = layerPixel{color: bc, layer: bg.layer}
+			n++
+			if n == 2 {
+				break
+			}
+		}
+		if hasSprite && n < 2 {
+			pixels[n] = sprite
+			n++
+		}
+		for ; n < 2; n++ {
+			pixels[n] = backdrop
+		}
+
+		if mask&windowEffects == 0 {
+			p.backBuffer.Set(x, y, pixels[0].color)
+			continue
+		}
+
+		semiTransparent := pixels[0].layer == layerSprite && p.sprites.semiTransparent[i]
+		p.backBuffer.Set(x, y, p.blend.apply(pixels[0], pixels[1], semiTransparent))
+	}
 }
 
 // getIndexQuartet converts a VRAMValue into the 4 palette indexes
@@ -439,23 +766,21 @@ func getIndexQuartet(i int, gfxData []memmap.VRAMValue) [4]int {
 	}
 }
 
-// palColorToRGBA converts a palette's color into an RGBA color
-func palColorToRGBA(palette []memmap.PaletteValue, index int) color.RGBA {
-	if index == 0 {
-		return color.RGBA{}
-	}
-	c := palette[index]
+// getIndex returns the 4 bit palette index of the pixel at x,y in an 8x8 tile
+func getIndex(tileData []memmap.VRAMValue, x, y int) int {
+	return int(tileData[y*2+x/4]>>((x%4)*4)) & 0x000F
+}
 
+// rgb15ToRGBA converts a 15 bit GBA color into an RGBA color
+func rgb15ToRGBA(c memmap.PaletteValue) color.RGBA {
 	b := (c & 0b01111100_00000000) >> 0xA
 	g := (c & 0b00000011_11100000) >> 0x5
 	r := c & 0b00000000_00011111
 
-	ret := color.RGBA{
+	return color.RGBA{
 		R: uint8(float64(r) * 8.2258),
 		G: uint8(float64(g) * 8.2258),
 		B: uint8(float64(b) * 8.2258),
 		A: 255,
 	}
-
-	return ret
 }
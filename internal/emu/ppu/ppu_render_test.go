@@ -0,0 +1,463 @@
+//go:build standalone
+
+package ppu
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	"github.com/bjatkin/flappy_boot/internal/hardware/sprite"
+)
+
+const (
+	testRed   memmap.PaletteValue = 0x001F
+	testGreen memmap.PaletteValue = 0x03E0
+	testBlue  memmap.PaletteValue = 0x7C00
+	testWhite memmap.PaletteValue = 0x7FFF
+)
+
+// resetMemory clears all the emulated memory blocks and hides every sprite
+func resetMemory() {
+	memmap.IORegBlock = [memmap.KByte]byte{}
+	memmap.PaletteBlock = [2 * memmap.KByte]byte{}
+	memmap.VRAMBlock = [96 * memmap.KByte]byte{}
+	memmap.OAMBlock = [memmap.KByte]byte{}
+
+	for i := range sprite.OAM {
+		sprite.OAM[i].Attr0 = sprite.Hide
+	}
+}
+
+// fillTile sets every pixel in the 4bpp tile to the given palette index
+func fillTile(tile []memmap.VRAMValue, index memmap.VRAMValue) {
+	for i := 0; i < memmap.TileOffset4; i++ {
+		tile[i] = index | index<<4 | index<<8 | index<<12
+	}
+}
+
+// setupBG0 enables background 0 with a solid green tile in the top left corner of the screen block
+func setupBG0(prio memmap.BGControll, hOffset, vOffset uint16) {
+	memmap.Palette[1] = testGreen
+	fillTile(memmap.VRAM[memmap.TileOffset4:], 1)
+
+	*display.BG0Controll = prio | 31<<display.SBBShift
+	*display.BG0HOffset = hOffset
+	*display.BG0VOffset = vOffset
+	memmap.VRAM[31*memmap.ScreenBlockOffset] = 1
+	*display.Controll |= display.BG0
+}
+
+// setupSprite enables the sprite at the given OAM index as an 8x8 sprite at 0,0 using the sprite palette bank
+func setupSprite(index int, bank int, prio sprite.Attr2) {
+	sprite.Palette[bank*memmap.PaletteOffset+1] = []memmap.PaletteValue{testBlue, testWhite}[bank]
+	fillTile(sprite.Block0[memmap.TileOffset4:], 1)
+
+	sprite.OAM[index].Attr0 = sprite.Normal | sprite.Square
+	sprite.OAM[index].Attr1 = sprite.Small
+	sprite.OAM[index].Attr2 = 1 | prio | sprite.Attr2(bank)<<sprite.PalShift
+	*display.Controll |= display.Sprites | display.Sprite1D
+}
+
+// setupAffine turns the sprite at the given OAM index into an affine sprite using affine matrix 0
+func setupAffine(index int, matrix [4]memmap.OAMValue, doubleSize bool) {
+	mode := sprite.Affine
+	if doubleSize {
+		mode = sprite.AffineDBL
+	}
+
+	sprite.OAM[index].Attr0 = mode | sprite.Square
+	sprite.AffineOAM[0].Pa = matrix[0]
+	sprite.AffineOAM[0].Pb = matrix[1]
+	sprite.AffineOAM[0].Pc = matrix[2]
+	sprite.AffineOAM[0].Pd = matrix[3]
+}
+
+func TestPPU_Update(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func()
+		x, y  int
+		want  memmap.PaletteValue
+	}{
+		{
+			"backdrop",
+			func() {},
+			0, 0,
+			testRed,
+		},
+		{
+			"background",
+			func() { setupBG0(2, 0, 0) },
+			7, 7,
+			testGreen,
+		},
+		{
+			"background transparent pixel",
+			func() { setupBG0(2, 0, 0) },
+			8, 8,
+			testRed,
+		},
+		{
+			"background scroll wraps",
+			func() { setupBG0(2, 252, 0) },
+			4, 0,
+			testGreen,
+		},
+		{
+			"sprite above background with the same priority",
+			func() {
+				setupBG0(2, 0, 0)
+				setupSprite(0, 0, sprite.Priority2)
+			},
+			0, 0,
+			testBlue,
+		},
+		{
+			"background above lower priority sprite",
+			func() {
+				setupBG0(0, 0, 0)
+				setupSprite(0, 0, sprite.Priority3)
+			},
+			0, 0,
+			testGreen,
+		},
+		{
+			"sprite above lower priority background",
+			func() {
+				setupBG0(3, 0, 0)
+				setupSprite(0, 0, sprite.Priority1)
+			},
+			0, 0,
+			testBlue,
+		},
+		{
+			"lower OAM index wins with the same priority",
+			func() {
+				setupSprite(0, 0, sprite.Priority1)
+				setupSprite(1, 1, sprite.Priority1)
+			},
+			0, 0,
+			testBlue,
+		},
+		{
+			"higher priority sprite wins",
+			func() {
+				setupSprite(0, 0, sprite.Priority1)
+				setupSprite(1, 1, sprite.Priority0)
+			},
+			0, 0,
+			testWhite,
+		},
+		{
+			"affine sprite",
+			func() {
+				setupSprite(0, 0, sprite.Priority0)
+				setupAffine(0, [4]memmap.OAMValue{0x0100, 0, 0, 0x0100}, false)
+			},
+			7, 7,
+			testBlue,
+		},
+		{
+			"double size affine sprite is centered",
+			func() {
+				setupSprite(0, 0, sprite.Priority0)
+				setupAffine(0, [4]memmap.OAMValue{0x0100, 0, 0, 0x0100}, true)
+			},
+			2, 2,
+			testRed,
+		},
+		{
+			"double size affine sprite",
+			func() {
+				setupSprite(0, 0, sprite.Priority0)
+				setupAffine(0, [4]memmap.OAMValue{0x0100, 0, 0, 0x0100}, true)
+			},
+			4, 4,
+			testBlue,
+		},
+		{
+			"scaled affine sprite",
+			func() {
+				setupSprite(0, 0, sprite.Priority0)
+				setupAffine(0, [4]memmap.OAMValue{0x0080, 0, 0, 0x0080}, true)
+			},
+			0, 15,
+			testBlue,
+		},
+		{
+			"alpha blended background",
+			func() {
+				setupBG0(0, 0, 0)
+				*display.BlendControll = display.BlendModeAlpha | display.BlendBG0 | display.BlendBackdrop<<display.BlendSecondShift
+				*display.BlendAlpha = 8 | 8<<display.EVBShift
+			},
+			0, 0,
+			0x01EF,
+		},
+		{
+			"brightened backdrop",
+			func() {
+				*display.BlendControll = display.BlendModeWhite | display.BlendBackdrop
+				*display.BlendY = 16
+			},
+			0, 0,
+			testWhite,
+		},
+		{
+			"semi transparent sprite",
+			func() {
+				setupBG0(1, 0, 0)
+				setupSprite(0, 0, sprite.Priority0)
+				sprite.OAM[0].Attr0 |= sprite.Blend
+				*display.BlendControll = display.BlendBG0 << display.BlendSecondShift
+				*display.BlendAlpha = 8 | 8<<display.EVBShift
+			},
+			0, 0,
+			0x3DE0,
+		},
+		{
+			"background hidden by window",
+			func() {
+				setupBG0(0, 0, 0)
+				*display.Controll |= display.Win1
+				*display.Win0H = 0<<display.WinLeftShift | 4
+				*display.Win0V = 0<<display.WinTopShift | 4
+				*display.WinIn = display.WinSprites
+				*display.WinOut = display.WinBG0
+			},
+			2, 2,
+			testRed,
+		},
+		{
+			"background outside window",
+			func() {
+				setupBG0(0, 0, 0)
+				*display.Controll |= display.Win1
+				*display.Win0H = 0<<display.WinLeftShift | 4
+				*display.Win0V = 0<<display.WinTopShift | 4
+				*display.WinIn = display.WinSprites
+				*display.WinOut = display.WinBG0
+			},
+			6, 6,
+			testGreen,
+		},
+		{
+			"sprite window",
+			func() {
+				setupBG0(0, 0, 0)
+				setupSprite(0, 0, sprite.Priority0)
+				sprite.OAM[0].Attr0 |= sprite.Win
+				*display.Controll |= display.WinSpr
+				*display.WinOut = display.WinBG0 | display.WinSprites<<display.WinShift
+			},
+			2, 2,
+			testRed,
+		},
+		{
+			"window disables effects",
+			func() {
+				*display.Controll |= display.Win1
+				*display.Win0H = 0<<display.WinLeftShift | 4
+				*display.Win0V = 0<<display.WinTopShift | 4
+				*display.BlendControll = display.BlendModeWhite | display.BlendBackdrop
+				*display.BlendY = 16
+			},
+			2, 2,
+			testRed,
+		},
+		{
+			"mosaic background",
+			func() {
+				setupBG0(0, 0, 0)
+				*display.BG0Controll |= display.Mosaic
+				*display.MosaicSize = 15
+			},
+			10, 0,
+			testGreen,
+		},
+		{
+			"mosaic size without mosaic background",
+			func() {
+				setupBG0(0, 0, 0)
+				*display.MosaicSize = 15
+			},
+			10, 0,
+			testRed,
+		},
+		{
+			"mosaic sprite block starts before the sprite",
+			func() {
+				setupSprite(0, 0, sprite.Priority0)
+				sprite.OAM[0].Attr0 |= sprite.Mosaic
+				sprite.OAM[0].Attr1 |= 4
+				*display.MosaicSize = 15 << display.MosaicSprHShift
+			},
+			10, 0,
+			testRed,
+		},
+		{
+			"mode 3 bitmap",
+			func() {
+				*display.Controll = display.Mode3 | display.BG2
+				memmap.VRAM[1*display.Width+2] = memmap.VRAMValue(testGreen)
+			},
+			2, 1,
+			testGreen,
+		},
+		{
+			"mode 4 bitmap",
+			func() {
+				*display.Controll = display.Mode4 | display.BG2
+				memmap.Palette[3] = testGreen
+				memmap.VRAM[display.Width/2] = 3 << 8
+			},
+			1, 1,
+			testGreen,
+		},
+		{
+			"mode 4 bitmap transparent index",
+			func() {
+				*display.Controll = display.Mode4 | display.BG2
+				memmap.Palette[3] = testGreen
+				memmap.VRAM[display.Width/2] = 3 << 8
+			},
+			0, 1,
+			testRed,
+		},
+		{
+			"mode 4 bitmap page b",
+			func() {
+				*display.Controll = display.Mode4 | display.BG2 | display.PageB
+				memmap.Palette[3] = testGreen
+				memmap.VRAM[0] = 3
+				memmap.VRAM[display.PageOffset] = 0
+			},
+			0, 0,
+			testRed,
+		},
+		{
+			"mode 5 bitmap page b",
+			func() {
+				*display.Controll = display.Mode5 | display.BG2 | display.PageB
+				memmap.VRAM[display.PageOffset+160+1] = memmap.VRAMValue(testGreen)
+			},
+			1, 1,
+			testGreen,
+		},
+		{
+			"mode 5 bitmap outside of the bitmap",
+			func() {
+				*display.Controll = display.Mode5 | display.BG2
+				for i := 0; i < 160*128; i++ {
+					memmap.VRAM[i] = memmap.VRAMValue(testGreen)
+				}
+			},
+			160, 0,
+			testRed,
+		},
+		{
+			"bitmap mode hides sprites using bitmap tiles",
+			func() {
+				setupSprite(0, 0, sprite.Priority0)
+				*display.Controll |= display.Mode3
+			},
+			0, 0,
+			testRed,
+		},
+		{
+			"sprite disabled in display controll",
+			func() {
+				setupSprite(0, 0, sprite.Priority0)
+				*display.Controll &= ^display.Sprites
+			},
+			0, 0,
+			testRed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetMemory()
+			memmap.Palette[0] = testRed
+			tt.setup()
+
+			p := New()
+			p.Update()
+
+			want := rgb15ToRGBA(tt.want)
+			if got := p.Screen.At(tt.x, tt.y).(color.RGBA); got != want {
+				t.Errorf("PPU.Update() pixel %d,%d = %v, want %v", tt.x, tt.y, got, want)
+			}
+		})
+	}
+}
+
+func TestPPU_UpdateHBlank(t *testing.T) {
+	tests := []struct {
+		name   string
+		hBlank func()
+		x, y   int
+		want   memmap.PaletteValue
+	}{
+		{
+			"scroll before the change",
+			func() {
+				if *display.VCount == 3 {
+					*display.BG0HOffset = 4
+				}
+			},
+			7, 3,
+			testGreen,
+		},
+		{
+			"scroll after the change",
+			func() {
+				if *display.VCount == 3 {
+					*display.BG0HOffset = 4
+				}
+			},
+			7, 4,
+			testRed,
+		},
+		{
+			"backdrop color change",
+			func() {
+				if *display.VCount == 9 {
+					memmap.Palette[0] = testWhite
+				}
+			},
+			0, 10,
+			testWhite,
+		},
+		{
+			"background palette change",
+			func() {
+				if *display.VCount == 1 {
+					memmap.Palette[1] = testBlue
+				}
+			},
+			0, 2,
+			testBlue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetMemory()
+			memmap.Palette[0] = testRed
+			setupBG0(0, 0, 0)
+
+			p := New()
+			p.HBlank = tt.hBlank
+			p.Update()
+
+			want := rgb15ToRGBA(tt.want)
+			if got := p.Screen.At(tt.x, tt.y).(color.RGBA); got != want {
+				t.Errorf("PPU.Update() pixel %d,%d = %v, want %v", tt.x, tt.y, got, want)
+			}
+			if *display.VCount != display.Height {
+				t.Errorf("PPU.Update() VCount = %d, want %d", *display.VCount, display.Height)
+			}
+		})
+	}
+}
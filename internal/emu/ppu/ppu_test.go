@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	"github.com/bjatkin/flappy_boot/internal/hardware/sprite"
 )
 
 func TestBackground_getTileView(t *testing.T) {
@@ -139,3 +140,76 @@ func TestRGB15_Set(t *testing.T) {
 		})
 	}
 }
+
+func Test_getIndex(t *testing.T) {
+	type args struct {
+		tileData []memmap.VRAMValue
+		x        int
+		y        int
+	}
+	tests := []struct {
+		name string
+		args args
+		want int
+	}{
+		{
+			"first pixel",
+			args{
+				tileData: []memmap.VRAMValue{0x4321, 0x8765},
+				x:        0,
+				y:        0,
+			},
+			1,
+		},
+		{
+			"second half word",
+			args{
+				tileData: []memmap.VRAMValue{0x4321, 0x8765},
+				x:        6,
+				y:        0,
+			},
+			7,
+		},
+		{
+			"second row",
+			args{
+				tileData: []memmap.VRAMValue{0x0000, 0x0000, 0x00F0, 0x0000},
+				x:        1,
+				y:        1,
+			},
+			15,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getIndex(tt.args.tileData, tt.args.x, tt.args.y); got != tt.want {
+				t.Errorf("getIndex() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSprite_update(t *testing.T) {
+	tests := []struct {
+		name    string
+		attrs   sprite.Attrs
+		enabled bool
+		size    v2
+	}{
+		{"square", sprite.Attrs{Attr0: sprite.Square, Attr1: sprite.Medium}, true, v2{X: 16, Y: 16}},
+		{"wide", sprite.Attrs{Attr0: sprite.Wide, Attr1: sprite.Large}, true, v2{X: 32, Y: 16}},
+		{"tall", sprite.Attrs{Attr0: sprite.Tall, Attr1: sprite.XL}, true, v2{X: 32, Y: 64}},
+		{"prohibited shape", sprite.Attrs{Attr0: sprite.ShapeMask, Attr1: sprite.XL}, false, v2{}},
+		{"hidden", sprite.Attrs{Attr0: sprite.Hide}, false, v2{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Sprite{attrs: &tt.attrs}
+			s.update()
+
+			if s.Enabled != tt.enabled || s.Size != tt.size {
+				t.Errorf("Sprite.update() enabled, size = %v, %v, want %v, %v", s.Enabled, s.Size, tt.enabled, tt.size)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package ppu
+
+import (
+	"github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+// windowAll is the window mask used when windows are disabled, every layer is visible and color effects are enabled
+const windowAll = int(display.WinMask)
+
+// windowEffects is the bit in a window mask that enables color effects
+const windowEffects = int(display.WinEffects)
+
+// span is the range of pixels covered by a window on a single axis. If start is larger than end the span wraps
+type span struct {
+	start, end int
+}
+
+// contains returns true if i is inside the span
+func (s span) contains(i int) bool {
+	if s.start <= s.end {
+		return i >= s.start && i < s.end
+	}
+
+	return i >= s.start || i < s.end
+}
+
+// windows is the emulated state of the window registers
+type windows struct {
+	enabled   bool
+	win0      bool
+	win1      bool
+	winSprite bool
+
+	h   [2]span
+	v   [2]span
+	in  [2]int
+	out int
+	spr int
+}
+
+// update reads the latest window values from the window registers
+func (w *windows) update() {
+	controll := *display.Controll
+	w.win0 = controll&display.Win1 > 0
+	w.win1 = controll&display.Win2 > 0
+	w.winSprite = controll&display.WinSpr > 0
+	w.enabled = w.win0 || w.win1 || w.winSprite
+
+	for i, reg := range []*memmap.WindowH{display.Win0H, display.Win1H} {
+		w.h[i] = span{
+			start: int((*reg & display.WinLeftMask) >> display.WinLeftShift),
+			end:   int(*reg & display.WinRightMask),
+		}
+	}
+	for i, reg := range []*memmap.WindowV{display.Win0V, display.Win1V} {
+		w.v[i] = span{
+			start: int((*reg & display.WinTopMask) >> display.WinTopShift),
+			end:   int(*reg & display.WinBottomMask),
+		}
+	}
+
+	w.in[0] = int(*display.WinIn & display.WinMask)
+	w.in[1] = int((*display.WinIn >> display.WinShift) & display.WinMask)
+	w.out = int(*display.WinOut & display.WinMask)
+	w.spr = int((*display.WinOut >> display.WinShift) & display.WinMask)
+}
+
+// mask returns the layers that are visible at x,y along with the color effects bit.
+// window 0 has the highest priority followed by window 1 and then the sprite window
+func (w *windows) mask(x, y int, inSprite bool) int {
+	if !w.enabled {
+		return windowAll
+	}
+
+	if w.win0 && w.h[0].contains(x) && w.v[0].contains(y) {
+		return w.in[0]
+	}
+	if w.win1 && w.h[1].contains(x) && w.v[1].contains(y) {
+		return w.in[1]
+	}
+	if w.winSprite && inSprite {
+		return w.spr
+	}
+
+	return w.out
+}
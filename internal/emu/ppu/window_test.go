@@ -0,0 +1,94 @@
+package ppu
+
+import "testing"
+
+func Test_windows_mask(t *testing.T) {
+	type args struct {
+		x, y     int
+		inSprite bool
+	}
+	tests := []struct {
+		name    string
+		windows windows
+		args    args
+		want    int
+	}{
+		{
+			"windows disabled",
+			windows{},
+			args{x: 10, y: 10},
+			windowAll,
+		},
+		{
+			"inside window 0",
+			windows{
+				enabled: true,
+				win0:    true,
+				h:       [2]span{{start: 0, end: 20}},
+				v:       [2]span{{start: 0, end: 20}},
+				in:      [2]int{0x01, 0x02},
+				out:     0x04,
+			},
+			args{x: 10, y: 10},
+			0x01,
+		},
+		{
+			"outside window 0",
+			windows{
+				enabled: true,
+				win0:    true,
+				h:       [2]span{{start: 0, end: 20}},
+				v:       [2]span{{start: 0, end: 20}},
+				in:      [2]int{0x01, 0x02},
+				out:     0x04,
+			},
+			args{x: 20, y: 10},
+			0x04,
+		},
+		{
+			"window 0 above window 1",
+			windows{
+				enabled: true,
+				win0:    true,
+				win1:    true,
+				h:       [2]span{{start: 0, end: 20}, {start: 0, end: 20}},
+				v:       [2]span{{start: 0, end: 20}, {start: 0, end: 20}},
+				in:      [2]int{0x01, 0x02},
+				out:     0x04,
+			},
+			args{x: 10, y: 10},
+			0x01,
+		},
+		{
+			"wrapped window 1",
+			windows{
+				enabled: true,
+				win1:    true,
+				h:       [2]span{{}, {start: 200, end: 20}},
+				v:       [2]span{{}, {start: 0, end: 160}},
+				in:      [2]int{0x01, 0x02},
+				out:     0x04,
+			},
+			args{x: 230, y: 10},
+			0x02,
+		},
+		{
+			"sprite window",
+			windows{
+				enabled:   true,
+				winSprite: true,
+				out:       0x04,
+				spr:       0x08,
+			},
+			args{x: 10, y: 10, inSprite: true},
+			0x08,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.windows.mask(tt.args.x, tt.args.y, tt.args.inSprite); got != tt.want {
+				t.Errorf("windows.mask() = %#02x, want %#02x", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package snapshot
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrInvalid is returned when a save state can not be decoded
+var ErrInvalid = errors.New("invalid save state")
+
+// Integer is any integer type that can be written to a save state
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// Writer encodes values into a save state. Integers are written as zig-zag varints so small values only
+// take a single byte, bools are a single byte and byte slices are written with their length first
+type Writer struct {
+	data []byte
+}
+
+// Data returns everything that has been written
+func (w *Writer) Data() []byte {
+	return w.data
+}
+
+// Int writes an integer
+func (w *Writer) Int(v int) {
+	w.data = binary.AppendVarint(w.data, int64(v))
+}
+
+// Int64 writes a 64 bit integer
+func (w *Writer) Int64(v int64) {
+	w.data = binary.AppendVarint(w.data, v)
+}
+
+// Bool writes a bool
+func (w *Writer) Bool(v bool) {
+	var b byte
+	if v {
+		b = 1
+	}
+	w.data = append(w.data, b)
+}
+
+// Bytes writes a byte slice
+func (w *Writer) Bytes(v []byte) {
+	w.Int(len(v))
+	w.data = append(w.data, v...)
+}
+
+// String writes a string
+func (w *Writer) String(v string) {
+	w.Int(len(v))
+	w.data = append(w.data, v...)
+}
+
+// Ints writes a slice of integers
+func Ints[T Integer](w *Writer, v []T) {
+	w.Int(len(v))
+	for i := range v {
+		w.Int64(int64(v[i]))
+	}
+}
+
+// Reader decodes the values in a save state in the same order they were written. Once a value can not
+// be decoded every read after it returns a zero value and Err returns ErrInvalid, so the values only need
+// to be checked once everything has been read
+type Reader struct {
+	data []byte
+	err  error
+}
+
+// NewReader creates a new reader for the save state data
+func NewReader(data []byte) *Reader {
+	return &Reader{data: data}
+}
+
+// Err returns ErrInvalid if a value could not be decoded or if there is data left over once all the values
+// have been read
+func (r *Reader) Err() error {
+	if r.err == nil && len(r.data) > 0 {
+		return ErrInvalid
+	}
+
+	return r.err
+}
+
+// Fail marks the save state as invalid, it's used when a value is decoded but it's not a valid value
+func (r *Reader) Fail() {
+	r.err = ErrInvalid
+	r.data = nil
+}
+
+// Int reads an integer
+func (r *Reader) Int() int {
+	return int(r.Int64())
+}
+
+// Int64 reads a 64 bit integer
+func (r *Reader) Int64() int64 {
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.Fail()
+		return 0
+	}
+	r.data = r.data[n:]
+
+	return v
+}
+
+// Bool reads a bool
+func (r *Reader) Bool() bool {
+	if len(r.data) == 0 || r.data[0] > 1 {
+		r.Fail()
+		return false
+	}
+
+	v := r.data[0] == 1
+	r.data = r.data[1:]
+	return v
+}
+
+// Len reads the length of a slice. The length must be between 0 and max so a bad save state can't
+// allocate more memory than the largest value that could have been saved
+func (r *Reader) Len(max int) int {
+	n := r.Int()
+	if n < 0 || n > max {
+		r.Fail()
+		return 0
+	}
+
+	return n
+}
+
+// Bytes reads a byte slice that is at most max bytes long
+func (r *Reader) Bytes(max int) []byte {
+	n := r.Len(max)
+	if n > len(r.data) {
+		r.Fail()
+		return nil
+	}
+
+	v := make([]byte, n)
+	copy(v, r.data)
+	r.data = r.data[n:]
+	return v
+}
+
+// Block reads a byte slice into dest, the slice must be the same length as dest
+func (r *Reader) Block(dest []byte) {
+	n := r.Len(len(dest))
+	if n != len(dest) || n > len(r.data) {
+		r.Fail()
+		return
+	}
+
+	copy(dest, r.data)
+	r.data = r.data[n:]
+}
+
+// String reads a string that is at most max bytes long
+func (r *Reader) String(max int) string {
+	return string(r.Bytes(max))
+}
+
+// ReadInts reads a slice of integers that is at most max values long
+func ReadInts[T Integer](r *Reader, max int) []T {
+	n := r.Len(max)
+	if n > len(r.data) {
+		// every integer is at least a byte long
+		r.Fail()
+		return nil
+	}
+
+	v := make([]T, n)
+	for i := range v {
+		v[i] = T(r.Int64())
+	}
+
+	return v
+}
+
+// ReadArray reads a slice of integers into dest, the slice must be the same length as dest
+func ReadArray[T Integer](r *Reader, dest []T) {
+	n := r.Len(len(dest))
+	if n != len(dest) {
+		r.Fail()
+		return
+	}
+
+	for i := range dest {
+		dest[i] = T(r.Int64())
+	}
+}
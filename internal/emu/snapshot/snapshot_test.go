@@ -0,0 +1,153 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+// values is every kind of value that can be written to a save state
+type values struct {
+	Int    int
+	Int64  int64
+	Bool   bool
+	Bytes  []byte
+	String string
+	Ints   []int16
+	Array  [3]uint8
+}
+
+// write writes the values to w
+func (v *values) write(w *Writer) {
+	w.Int(v.Int)
+	w.Int64(v.Int64)
+	w.Bool(v.Bool)
+	w.Bytes(v.Bytes)
+	w.String(v.String)
+	Ints(w, v.Ints)
+	Ints(w, v.Array[:])
+}
+
+// read reads the values written by write
+func (v *values) read(r *Reader) {
+	v.Int = r.Int()
+	v.Int64 = r.Int64()
+	v.Bool = r.Bool()
+	v.Bytes = r.Bytes(16)
+	v.String = r.String(16)
+	v.Ints = ReadInts[int16](r, 16)
+	ReadArray(r, v.Array[:])
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		v    values
+	}{
+		{
+			name: "zero values",
+			v:    values{Bytes: []byte{}, Ints: []int16{}},
+		},
+		{
+			name: "small values",
+			v: values{
+				Int:    3,
+				Int64:  -1,
+				Bool:   true,
+				Bytes:  []byte{1, 2, 3},
+				String: "FBST",
+				Ints:   []int16{-2, 0, 2},
+				Array:  [3]uint8{1, 2, 3},
+			},
+		},
+		{
+			name: "large values",
+			v: values{
+				Int:    -1 << 40,
+				Int64:  1<<63 - 1,
+				Bytes:  []byte{0xFF, 0x00},
+				String: "flappy boot",
+				Ints:   []int16{-1 << 15, 1<<15 - 1},
+				Array:  [3]uint8{0xFF, 0x80, 0x7F},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &Writer{}
+			tt.v.write(w)
+
+			r := NewReader(w.Data())
+			var got values
+			got.read(r)
+			if err := r.Err(); err != nil {
+				t.Fatalf("Err() = %v", err)
+			}
+
+			if diff := deep.Equal(got, tt.v); diff != nil {
+				t.Errorf("read values do not match the written values %v", diff)
+			}
+		})
+	}
+}
+
+func TestReader_Invalid(t *testing.T) {
+	valid := &Writer{}
+	(&values{Int: 1, Bytes: []byte{1, 2}, String: "a", Ints: []int16{1}}).write(valid)
+
+	tooLong := &Writer{}
+	tooLong.Int(1)
+	tooLong.Int64(1)
+	tooLong.Bool(true)
+	tooLong.Bytes(make([]byte, 17))
+
+	badBool := &Writer{}
+	badBool.Int(1)
+	badBool.Int64(1)
+	badBool.data = append(badBool.data, 2)
+
+	badArray := &Writer{}
+	(&values{}).write(badArray)
+	badArray.data = badArray.data[:len(badArray.data)-4]
+	Ints(badArray, []uint8{1, 2})
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "truncated", data: valid.Data()[:len(valid.Data())-1]},
+		{name: "trailing data", data: append(append([]byte{}, valid.Data()...), 0)},
+		{name: "too long", data: tooLong.Data()},
+		{name: "bad bool", data: badBool.Data()},
+		{name: "wrong array length", data: badArray.Data()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReader(tt.data)
+			var got values
+			got.read(r)
+			if err := r.Err(); err != ErrInvalid {
+				t.Errorf("Err() = %v, want %v", err, ErrInvalid)
+			}
+		})
+	}
+}
+
+func TestReader_Fail(t *testing.T) {
+	w := &Writer{}
+	w.Int(1)
+	w.Int(2)
+
+	r := NewReader(w.Data())
+	r.Int()
+	r.Fail()
+	if got := r.Int(); got != 0 {
+		t.Errorf("Int() after Fail() = %d, want 0", got)
+	}
+	if err := r.Err(); err != ErrInvalid {
+		t.Errorf("Err() = %v, want %v", err, ErrInvalid)
+	}
+}
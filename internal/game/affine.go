@@ -0,0 +1,81 @@
+package game
+
+import (
+	"unsafe"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	hw_sprite "github.com/bjatkin/flappy_boot/internal/hardware/sprite"
+	"github.com/bjatkin/flappy_boot/internal/lut"
+	"github.com/bjatkin/flappy_boot/internal/math"
+)
+
+// maxAffine is the number of affine matrices that can be stored in OAM at once
+const maxAffine = 32
+
+// maxInvScale is the largest inverse scale that fits in an affine matrix entry
+const maxInvScale = math.Fix8(0x7FFF)
+
+// affineMatrix is an affine matrix that maps screen space into a sprites texture space
+type affineMatrix struct {
+	pa, pb, pc, pd math.Fix8
+}
+
+// newAffineMatrix creates a new affine matrix for a sprite with the given rotation and scale.
+// rotation is in turns so math.FixOne is a full rotation
+func newAffineMatrix(rotation math.Fix8, scale math.V2) affineMatrix {
+	sin := lut.Sin(rotation)
+	cos := lut.Sin(rotation + math.FixQuarter)
+
+	// the GBA maps from screen space into texture space so the inverse of the scale is needed
+	invX := invScale(scale.X)
+	invY := invScale(scale.Y)
+
+	return affineMatrix{
+		pa: cos * invX >> 8,
+		pb: sin * invX >> 8,
+		pc: -sin * invY >> 8,
+		pd: cos * invY >> 8,
+	}
+}
+
+// invScale returns 1/s, a scale of 0 is clamped to the largest inverse scale the hardware supports
+func invScale(s math.Fix8) math.Fix8 {
+	if s == 0 {
+		return maxInvScale
+	}
+
+	inv := math.FixOne * math.FixOne / s
+	return math.Clamp(inv, -maxInvScale, maxInvScale)
+}
+
+// affineIndex returns the index of an affine matrix matching the sprites rotation and scale.
+// sprites with the same rotation and scale share a single matrix, if all the matrices are in use
+// -1 is returned
+func (e *Engine) affineIndex(s *Sprite) int {
+	matrix := newAffineMatrix(s.Rotation, s.Scale)
+	for i := 0; i < e.affineCount; i++ {
+		if e.affine[i] == matrix {
+			return i
+		}
+	}
+
+	if e.affineCount == maxAffine {
+		return -1
+	}
+
+	e.affine[e.affineCount] = matrix
+	e.affineCount++
+	return e.affineCount - 1
+}
+
+// drawAffine copies all the engines affine matrices into the OAM buffer. It must be called after the regular
+// sprite attributes have been written because the affine matrices are interlaced with the regular attributes
+func (e *Engine) drawAffine() {
+	affineBuff := unsafe.Slice((*hw_sprite.AffineAttrs)(unsafe.Pointer(&e.oamBuff[0])), maxAffine)
+	for i := 0; i < e.affineCount; i++ {
+		affineBuff[i].Pa = memmap.OAMValue(int16(e.affine[i].pa))
+		affineBuff[i].Pb = memmap.OAMValue(int16(e.affine[i].pb))
+		affineBuff[i].Pc = memmap.OAMValue(int16(e.affine[i].pc))
+		affineBuff[i].Pd = memmap.OAMValue(int16(e.affine[i].pd))
+	}
+}
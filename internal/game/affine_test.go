@@ -0,0 +1,67 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/math"
+)
+
+func Test_newAffineMatrix(t *testing.T) {
+	type args struct {
+		rotation math.Fix8
+		scale    math.V2
+	}
+	tests := []struct {
+		name string
+		args args
+		want affineMatrix
+	}{
+		{
+			"identity",
+			args{
+				rotation: 0,
+				scale:    math.V2{X: math.FixOne, Y: math.FixOne},
+			},
+			affineMatrix{pa: math.FixOne, pd: math.FixOne},
+		},
+		{
+			"double size",
+			args{
+				rotation: 0,
+				scale:    math.V2{X: math.FixOne * 2, Y: math.FixOne * 2},
+			},
+			affineMatrix{pa: math.FixHalf, pd: math.FixHalf},
+		},
+		{
+			"quarter turn",
+			args{
+				rotation: math.FixQuarter,
+				scale:    math.V2{X: math.FixOne, Y: math.FixOne},
+			},
+			affineMatrix{pb: math.FixOne, pc: -math.FixOne},
+		},
+		{
+			"half turn wide",
+			args{
+				rotation: math.FixHalf,
+				scale:    math.V2{X: math.FixHalf, Y: math.FixOne},
+			},
+			affineMatrix{pa: -math.FixOne * 2, pd: -math.FixOne},
+		},
+		{
+			"zero scale",
+			args{
+				rotation: 0,
+				scale:    math.V2{X: 0, Y: math.FixOne},
+			},
+			affineMatrix{pa: maxInvScale, pd: math.FixOne},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newAffineMatrix(tt.args.rotation, tt.args.scale); got != tt.want {
+				t.Errorf("newAffineMatrix() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package game
 
 import (
 	"github.com/bjatkin/flappy_boot/internal/assets"
+	hw_display "github.com/bjatkin/flappy_boot/internal/hardware/display"
 	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
 	"github.com/bjatkin/flappy_boot/internal/math"
 )
@@ -17,15 +18,21 @@ type Background struct {
 	controllReg memmap.BGControll
 	HScroll     math.Fix8
 	VScroll     math.Fix8
+
+	// Mosaic enables the mosaic effect for the background, see Engine.SetMosaic
+	Mosaic bool
 }
 
 // NewBackground returns a new Background
 func (e *Engine) NewBackground(tilemap *assets.TileMap, priority memmap.BGControll) *Background {
-	return &Background{
+	b := &Background{
 		engine:      e,
 		tileMap:     tilemap,
 		controllReg: priority,
 	}
+	e.objects.track(b)
+
+	return b
 }
 
 // Load loads a backgrounds data into memory
@@ -74,11 +81,28 @@ func (b *Background) Unload() {
 	b.tileMap.Free(b.engine.mapAlloc, b.engine.bgTileAlloc, b.engine.sprPalAlloc)
 }
 
+// Layer returns the display layer the background is drawn on. If the background is not being shown 0 is returned
+func (b *Background) Layer() Layer {
+	for i := range b.engine.activeBackgrounds {
+		if b.engine.activeBackgrounds[i] == b {
+			return LayerBG0 << i
+		}
+	}
+
+	return 0
+}
+
 // controll returns the correct value for the background controll registers for the given background
 func (b *Background) controll() memmap.BGControll {
+	var mosaic memmap.BGControll
+	if b.Mosaic {
+		mosaic = hw_display.Mosaic
+	}
+
 	return b.controllReg |
 		b.tileMap.ScreenBaseBlock() |
-		b.tileMap.Size
+		b.tileMap.Size |
+		mosaic
 }
 
 func (b *Background) SetTile(x, y, tile int) {
@@ -0,0 +1,173 @@
+package game
+
+import (
+	"errors"
+	"unicode"
+
+	"github.com/bjatkin/flappy_boot/internal/assets"
+	hw_display "github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/dma"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+// ErrBitmapMode is returned when a bitmap is created with a display mode that is not a bitmap mode
+var ErrBitmapMode = errors.New("bitmaps must use display mode 3, 4 or 5")
+
+// Bitmap is a bitmap surface that is drawn directly to the screen using one of the bitmap display modes.
+// Mode3 bitmaps are 240x160 with 15 bit colors. Mode4 bitmaps are 240x160 and use the 256 color background palette.
+// Mode5 bitmaps are 160x128 with 15 bit colors. Mode4 and Mode5 bitmaps have two pages, drawing is always done to the
+// back page and Flip is used to show it.
+//
+// Bitmaps use the same VRAM as background tiles and sprite tiles 0 - 511 so backgrounds can not be shown
+// while a bitmap is shown, and the background graphics will need to be reloaded afterwards
+type Bitmap struct {
+	// engine is a reference to the bitmaps parent engine
+	engine *Engine
+	mode   memmap.DisplayControll
+
+	// page is the page that is currently being displayed
+	page int
+
+	Width, Height int
+}
+
+// NewBitmap returns a new Bitmap using the given bitmap display mode
+func (e *Engine) NewBitmap(mode memmap.DisplayControll) (*Bitmap, error) {
+	b := &Bitmap{
+		engine: e,
+		mode:   mode,
+	}
+
+	switch mode {
+	case hw_display.Mode3, hw_display.Mode4:
+		b.Width, b.Height = hw_display.Width, hw_display.Height
+	case hw_display.Mode5:
+		b.Width, b.Height = 160, 128
+	default:
+		return nil, ErrBitmapMode
+	}
+	e.objects.track(b)
+
+	return b, nil
+}
+
+// pageData returns the VRAM for the page that is being drawn to
+func (b *Bitmap) pageData() []memmap.VRAMValue {
+	if b.mode == hw_display.Mode3 || b.page == 1 {
+		return memmap.VRAM
+	}
+
+	return memmap.VRAM[hw_display.PageOffset:]
+}
+
+// Set sets the pixel at x,y to the given color. Set should only be used by Mode3 and Mode5 bitmaps
+func (b *Bitmap) Set(x, y int, c memmap.PaletteValue) {
+	if x < 0 || x >= b.Width || y < 0 || y >= b.Height {
+		return
+	}
+
+	b.pageData()[y*b.Width+x] = memmap.VRAMValue(c)
+}
+
+// SetIndex sets the pixel at x,y to the given palette index. SetIndex should only be used by Mode4 bitmaps
+func (b *Bitmap) SetIndex(x, y int, index uint8) {
+	if x < 0 || x >= b.Width || y < 0 || y >= b.Height {
+		return
+	}
+
+	// VRAM can only be written 16 bits at a time so the neighboring pixel needs to be preserved
+	data := b.pageData()
+	i := y*b.Width + x
+	v := data[i/2]
+	if i%2 == 0 {
+		v = v&0xFF00 | memmap.VRAMValue(index)
+	} else {
+		v = v&0x00FF | memmap.VRAMValue(index)<<8
+	}
+	data[i/2] = v
+}
+
+// Fill sets every pixel in the bitmap to the given color. Fill should only be used by Mode3 and Mode5 bitmaps
+func (b *Bitmap) Fill(c memmap.PaletteValue) {
+	dma.Fill32(b.pageData()[:b.Width*b.Height], memmap.VRAMValue(c))
+}
+
+// FillIndex sets every pixel in the bitmap to the given palette index. FillIndex should only be used by Mode4 bitmaps
+func (b *Bitmap) FillIndex(index uint8) {
+	dma.Fill32(b.pageData()[:b.Width*b.Height/2], memmap.VRAMValue(index)|memmap.VRAMValue(index)<<8)
+}
+
+// Print draws the text onto the bitmap using the system font, x and y are the top left corner of the first char.
+// new lines start again at x, lower case letters are drawn in upper case and chars that are not in the font are drawn as '?'.
+// Print should only be used by Mode3 and Mode5 bitmaps
+func (b *Bitmap) Print(x, y int, text string, c memmap.PaletteValue) {
+	dx := x
+	for _, r := range text {
+		if r == '\n' {
+			dx = x
+			y += 8
+			continue
+		}
+
+		glyph, ok := assets.SystemFont.Glyph(unicode.ToUpper(r))
+		if !ok {
+			glyph, _ = assets.SystemFont.Glyph('?')
+		}
+
+		for gy, row := range glyph {
+			for gx := 0; gx < 8; gx++ {
+				if row&(1<<gx) > 0 {
+					b.Set(dx+gx, y+gy, c)
+				}
+			}
+		}
+		dx += 8
+	}
+}
+
+// SetPalette sets the color of the background palette at index. It's used by Mode4 bitmaps
+func (b *Bitmap) SetPalette(index uint8, c memmap.PaletteValue) {
+	b.engine.palBuff[index] = c
+	// force the palette to be coppied into palette memory on the next draw
+	b.engine.doFade = true
+}
+
+// Flip shows the page that is being drawn to. Mode3 bitmaps only have a single page so Flip does nothing
+func (b *Bitmap) Flip() {
+	if b.mode == hw_display.Mode3 {
+		return
+	}
+
+	b.page = 1 - b.page
+}
+
+// Show switches the display into the bitmaps display mode and draws the bitmap on screen
+func (b *Bitmap) Show() {
+	b.engine.bitmap = b
+}
+
+// Hide switches the display back into the tiled display mode
+func (b *Bitmap) Hide() {
+	if b.engine.bitmap == b {
+		b.engine.bitmap = nil
+	}
+}
+
+// drawBitmap updates the display mode in the shadow display controll register based on the engines active bitmap
+func (e *Engine) drawBitmap() {
+	controll := e.regs.displayControll &^ (hw_display.ModeMask | hw_display.PageB)
+	if e.bitmap == nil {
+		e.regs.displayControll = controll | hw_display.Mode0
+		return
+	}
+
+	// only background 2 is used in the bitmap modes
+	controll &^= hw_display.BG0 | hw_display.BG1 | hw_display.BG3
+	controll |= e.bitmap.mode | hw_display.BG2
+	if e.bitmap.page == 1 {
+		controll |= hw_display.PageB
+	}
+
+	e.regs.bgControll[2] = 0
+	e.regs.displayControll = controll
+}
@@ -0,0 +1,102 @@
+//go:build standalone
+
+package game
+
+import (
+	"testing"
+
+	hw_display "github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+func TestBitmap_SetIndex(t *testing.T) {
+	tests := []struct {
+		name  string
+		x, y  int
+		index uint8
+		flip  bool
+		at    int
+		want  memmap.VRAMValue
+	}{
+		{
+			"even pixel",
+			0, 0, 0x12,
+			false,
+			hw_display.PageOffset,
+			0xCD12,
+		},
+		{
+			"odd pixel",
+			1, 0, 0x12,
+			false,
+			hw_display.PageOffset,
+			0x12AB,
+		},
+		{
+			"second row",
+			0, 1, 0x12,
+			false,
+			hw_display.PageOffset + hw_display.Width/2,
+			0xCD12,
+		},
+		{
+			"flipped page",
+			0, 0, 0x12,
+			true,
+			0,
+			0xCD12,
+		},
+		{
+			"out of bounds",
+			hw_display.Width, 0, 0x12,
+			false,
+			hw_display.PageOffset + hw_display.Width/2,
+			0xCDAB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memmap.VRAM[tt.at] = 0xCDAB
+			b, err := (&Engine{}).NewBitmap(hw_display.Mode4)
+			if err != nil {
+				t.Fatalf("NewBitmap() error = %v", err)
+			}
+			if tt.flip {
+				b.Flip()
+			}
+
+			b.SetIndex(tt.x, tt.y, tt.index)
+			if got := memmap.VRAM[tt.at]; got != tt.want {
+				t.Errorf("Bitmap.SetIndex() VRAM[%#x] = %#04x, want %#04x", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_NewBitmap(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          memmap.DisplayControll
+		width, height int
+		wantErr       bool
+	}{
+		{"mode 3", hw_display.Mode3, 240, 160, false},
+		{"mode 4", hw_display.Mode4, 240, 160, false},
+		{"mode 5", hw_display.Mode5, 160, 128, false},
+		{"tiled mode", hw_display.Mode0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := (&Engine{}).NewBitmap(tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewBitmap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if b.Width != tt.width || b.Height != tt.height {
+				t.Errorf("NewBitmap() size = %dx%d, want %dx%d", b.Width, b.Height, tt.width, tt.height)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package game
+
+import (
+	hw_display "github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	"github.com/bjatkin/flappy_boot/internal/math"
+)
+
+// Layer is a bitmask of display layers, it's used to select the layers that color effects are applied to
+type Layer uint16
+
+const (
+	// LayerBG0 is background layer 0
+	LayerBG0 Layer = 0x0001
+
+	// LayerBG1 is background layer 1
+	LayerBG1 Layer = 0x0002
+
+	// LayerBG2 is background layer 2
+	LayerBG2 Layer = 0x0004
+
+	// LayerBG3 is background layer 3
+	LayerBG3 Layer = 0x0008
+
+	// LayerSprites is the sprite layer
+	LayerSprites Layer = 0x0010
+
+	// LayerBackdrop is the backdrop color that is shown when no other layer is visible
+	LayerBackdrop Layer = 0x0020
+
+	// LayerAll is every display layer
+	LayerAll Layer = 0x003F
+)
+
+// Blend alpha blends the top layers over the bottom layers. t is clamped between 0 and 1.
+// At 0 only the bottom layers are visible and at 1 only the top layers are visible.
+// Sprites with SemiTransparent set are always blended onto the bottom layers using t, even if
+// the sprite layer is not one of the top layers
+func (e *Engine) Blend(top, bottom Layer, t math.Fix8) {
+	eva := blendCoefficient(t)
+	e.blendControll = hw_display.BlendModeAlpha |
+		memmap.BlendControll(top) |
+		memmap.BlendControll(bottom)<<hw_display.BlendSecondShift
+	e.blendAlpha = memmap.BlendAlpha(eva) | memmap.BlendAlpha(16-eva)<<hw_display.EVBShift
+}
+
+// Brighten brightens the layers towards white. t is clamped between 0 and 1.
+// At 0 the layers are unchanged and at 1 the layers are completely white
+func (e *Engine) Brighten(layers Layer, t math.Fix8) {
+	e.blendControll = hw_display.BlendModeWhite | memmap.BlendControll(layers)
+	e.blendY = memmap.BlendY(blendCoefficient(t))
+}
+
+// Darken darkens the layers towards black. t is clamped between 0 and 1.
+// At 0 the layers are unchanged and at 1 the layers are completely black
+func (e *Engine) Darken(layers Layer, t math.Fix8) {
+	e.blendControll = hw_display.BlendModeBlack | memmap.BlendControll(layers)
+	e.blendY = memmap.BlendY(blendCoefficient(t))
+}
+
+// ClearBlend removes any color effects set with Blend, Brighten or Darken
+func (e *Engine) ClearBlend() {
+	e.blendControll = hw_display.BlendModeOff
+	e.blendAlpha = 0
+	e.blendY = 0
+}
+
+// blendCoefficient converts t into a hardware blend coefficient between 0 and 16
+func blendCoefficient(t math.Fix8) int {
+	t = math.Clamp(t, 0, math.FixOne)
+	return int(t*16) >> 8
+}
+
+// hardwareFade returns true if the current palette fade can be done with the hardware brightness effect
+func (e *Engine) hardwareFade() bool {
+	return e.fadeCol == White || e.fadeCol == Black
+}
+
+// drawBlend copies the engines color effects into the shadow blend registers. Hardware palette fades take
+// priority over any color effects set by Blend, Brighten or Darken
+func (e *Engine) drawBlend() {
+	if e.hardwareFade() && e.fadeFrac > 0 {
+		mode := hw_display.BlendModeWhite
+		if e.fadeCol == Black {
+			mode = hw_display.BlendModeBlack
+		}
+
+		e.regs.blendControll = mode | memmap.BlendControll(LayerAll)
+		e.regs.blendAlpha = e.blendAlpha
+		e.regs.blendY = memmap.BlendY(blendCoefficient(e.fadeFrac))
+		return
+	}
+
+	e.regs.blendControll = e.blendControll
+	e.regs.blendAlpha = e.blendAlpha
+	e.regs.blendY = e.blendY
+}
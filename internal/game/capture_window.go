@@ -0,0 +1,86 @@
+//go:build standalone && !headless
+
+package game
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/bjatkin/flappy_boot/internal/emu/capture"
+)
+
+var (
+	screenshotFlag   = flag.String("screenshot", "", "save the screen to a png file at this path once the capture frame is reached")
+	gifFlag          = flag.String("gif", "", "record the screen to a gif file at this path starting at the capture frame")
+	gifSecondsFlag   = flag.Float64("gif-seconds", 5, "the number of seconds of frames to record to a gif")
+	captureFrameFlag = flag.Int("capture-frame", 1, "the engine frame the screenshot and gif flags capture at")
+)
+
+// capturer saves screenshots and records gifs of the screen
+type capturer struct {
+	// gif is the gif that's being recorded, it's nil unless frames are being recorded
+	gif     *capture.GIF
+	gifPath string
+}
+
+// screenshot saves the current screen to a png file at path
+func (h *Harness) screenshot(path string) {
+	if err := capture.WritePNG(path, h.PPU.Screen); err != nil {
+		fmt.Printf("failed to save screenshot: %v\n", err)
+		return
+	}
+
+	fmt.Printf("saved screenshot to %s\n", path)
+}
+
+// startGIF starts recording frames to a gif file at path, nothing happens if a gif is already being recorded
+func (h *Harness) startGIF(path string) {
+	if h.capture.gif != nil {
+		return
+	}
+
+	h.capture.gif = capture.NewGIF()
+	h.capture.gifPath = path
+}
+
+// saveGIF writes the gif that's being recorded and stops recording
+func (h *Harness) saveGIF() {
+	if h.capture.gif == nil {
+		return
+	}
+
+	gif, path := h.capture.gif, h.capture.gifPath
+	h.capture.gif = nil
+	if err := gif.WriteFile(path); err != nil {
+		fmt.Printf("failed to save gif: %v\n", err)
+		return
+	}
+
+	fmt.Printf("saved gif to %s\n", path)
+}
+
+// captureName is the name of the file a screenshot or gif is saved to when it's captured with a hotkey
+func (h *Harness) captureName(ext string) string {
+	return fmt.Sprintf("flappy_boot_%d.%s", h.E.Frame(), ext)
+}
+
+// updateCapture captures the frame that was just run. It must be called after every frame
+func (h *Harness) updateCapture() {
+	if h.E.Frame() == *captureFrameFlag {
+		if *screenshotFlag != "" {
+			h.screenshot(*screenshotFlag)
+		}
+		if *gifFlag != "" {
+			h.startGIF(*gifFlag)
+		}
+	}
+
+	if h.capture.gif == nil {
+		return
+	}
+
+	h.capture.gif.Add(h.PPU.Screen)
+	if h.capture.gif.Frames() >= capture.Frames(*gifSecondsFlag) {
+		h.saveGIF()
+	}
+}
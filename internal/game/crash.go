@@ -0,0 +1,107 @@
+package game
+
+import (
+	"strconv"
+
+	"github.com/bjatkin/flappy_boot/internal/alloc"
+	"github.com/bjatkin/flappy_boot/internal/display"
+	hw_display "github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+const (
+	// crashColumns is the number of chars that fit on a single line of the crash screen
+	crashColumns = hw_display.Width/8 - 2
+
+	// crashErrLines is the maximum number of lines of error text on the crash screen
+	crashErrLines = 8
+)
+
+// exit exits the game loop and draws error infromation to the screen using the system font
+func (e *Engine) exit(err error) {
+	// the sound DMA would keep reading past the end of the sound buffer and play noise forever
+	e.stopSound()
+	// the rasters would keep writing scroll, palette and affine registers over the crash screen
+	e.stopRasters()
+
+	memmap.SetReg(hw_display.Controll, hw_display.Mode3|hw_display.BG2)
+	memmap.SetReg(hw_display.BG2Controll, 0)
+
+	// clear any color effects so the error screen is not hidden
+	memmap.SetReg(hw_display.BlendControll, hw_display.BlendModeOff)
+	memmap.SetReg(hw_display.MosaicSize, 0)
+
+	// Draw blue to the screen so we can tell there was an error
+	screen := &Bitmap{mode: hw_display.Mode3, Width: hw_display.Width, Height: hw_display.Height}
+	screen.Fill(memmap.PaletteValue(display.RGB15(0, 0, 31)))
+
+	text := memmap.PaletteValue(display.RGB15(31, 31, 31))
+	screen.Print(8, 8, "FATAL ERROR", text)
+
+	lines := wrapText(err.Error(), crashColumns)
+	if len(lines) > crashErrLines {
+		lines = lines[:crashErrLines]
+	}
+	for i, line := range lines {
+		screen.Print(8, 24+i*8, line, text)
+	}
+
+	scene := e.scene
+	if scene == "" {
+		scene = "-"
+	}
+	screen.Print(8, 96, "SCENE: "+scene, text)
+	screen.Print(8, 104, "FRAME: "+strconv.Itoa(e.frame), text)
+	screen.Print(8, 112, "FLUSH MISSES: "+strconv.Itoa(e.flushMisses), text)
+
+	screen.Print(8, 120, "BG TILES:  "+vramStats(e.bgTileAlloc), text)
+	screen.Print(8, 128, "SPR TILES: "+vramStats(e.sprTileAlloc), text)
+	screen.Print(8, 136, "BG MAPS:   "+vramStats(e.mapAlloc), text)
+	screen.Print(8, 144, "PALETTES:  "+palStats(e.bgPalAlloc)+" "+palStats(e.sprPalAlloc), text)
+
+	// block forever
+	halt()
+}
+
+// vramStats formats the VRAM allocators usage as used/total
+func vramStats(v *alloc.VRAM) string {
+	if v == nil {
+		return "-"
+	}
+
+	used, total := v.Used()
+	return strconv.Itoa(used) + "/" + strconv.Itoa(total)
+}
+
+// palStats formats the palette allocators usage as used/total
+func palStats(p *alloc.Pal) string {
+	if p == nil {
+		return "-"
+	}
+
+	used, total := p.Used()
+	return strconv.Itoa(used) + "/" + strconv.Itoa(total)
+}
+
+// wrapText splits the text into lines that are at most width chars long. Lines are split on spaces
+// when possible, words that are longer than width are split across multiple lines
+func wrapText(text string, width int) []string {
+	var lines []string
+	for len(text) > width {
+		split := width
+		for i := width; i > 0; i-- {
+			if text[i] == ' ' {
+				split = i
+				break
+			}
+		}
+
+		lines = append(lines, text[:split])
+		text = text[split:]
+		if len(text) > 0 && text[0] == ' ' {
+			text = text[1:]
+		}
+	}
+
+	return append(lines, text)
+}
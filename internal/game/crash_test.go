@@ -0,0 +1,53 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_wrapText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{
+			"short text",
+			"out of memory",
+			20,
+			[]string{"out of memory"},
+		},
+		{
+			"split on space",
+			"out of memory",
+			8,
+			[]string{"out of", "memory"},
+		},
+		{
+			"split at exact width",
+			"abcd efgh",
+			4,
+			[]string{"abcd", "efgh"},
+		},
+		{
+			"split long word",
+			"abcdefghij",
+			4,
+			[]string{"abcd", "efgh", "ij"},
+		},
+		{
+			"empty",
+			"",
+			4,
+			[]string{""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapText(tt.text, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrapText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+//go:build standalone
+
+package game
+
+import (
+	"fmt"
+)
+
+const (
+	// normalSpeed is the index of the normal game speed in debugSpeeds
+	normalSpeed = 2
+
+	// speedQuarters is the number of quarter frames in a single frame
+	speedQuarters = 4
+)
+
+// debugSpeeds are the speeds the debugger can run the game at, they're in quarter frames per tick
+var debugSpeeds = []int{1, 2, 4, 8, 16}
+
+// speedNames are the names of each of the debug speeds in the status overlay
+var speedNames = []string{"0.25", "0.5", "1", "2", "4"}
+
+// debugger controlls how many frames the harness runs on each tick so the game can be paused, stepped a
+// single frame at a time, or slowed down and sped up. It never touches the key input register
+type debugger struct {
+	paused bool
+	speed  int
+
+	// quarters are the quarter frames left over from the last tick, slow speeds only run a frame
+	// once enough quarter frames have built up
+	quarters int
+
+	// step is true if a single frame should be run while the game is paused
+	step bool
+}
+
+// newDebugger creates a new debugger that runs the game at normal speed
+func newDebugger() debugger {
+	return debugger{speed: normalSpeed}
+}
+
+// pause pauses the game if it's running and resumes it if it's paused
+func (d *debugger) pause() {
+	d.paused = !d.paused
+	d.step = false
+}
+
+// stepFrame runs a single frame on the next tick, the game is paused if it's running
+func (d *debugger) stepFrame() {
+	d.paused = true
+	d.step = true
+}
+
+// slower slows the game down to the next slowest speed
+func (d *debugger) slower() {
+	if d.speed > 0 {
+		d.speed--
+	}
+}
+
+// faster speeds the game up to the next fastest speed
+func (d *debugger) faster() {
+	if d.speed < len(debugSpeeds)-1 {
+		d.speed++
+	}
+}
+
+// frames returns the number of frames that should be run on this tick
+func (d *debugger) frames() int {
+	if d.paused {
+		step := d.step
+		d.step = false
+		if step {
+			return 1
+		}
+		return 0
+	}
+
+	d.quarters += debugSpeeds[d.speed]
+	frames := d.quarters / speedQuarters
+	d.quarters %= speedQuarters
+
+	return frames
+}
+
+// active returns true if the game is paused or is not running at normal speed
+func (d *debugger) active() bool {
+	return d.paused || d.speed != normalSpeed
+}
+
+// status returns the status overlay text for the given engine frame
+func (d *debugger) status(frame int) string {
+	if d.paused {
+		return fmt.Sprintf("FRAME %d PAUSED", frame)
+	}
+
+	return fmt.Sprintf("FRAME %d x%s", frame, speedNames[d.speed])
+}
@@ -0,0 +1,72 @@
+//go:build standalone
+
+package game
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func Test_debugger_frames(t *testing.T) {
+	tests := []struct {
+		name    string
+		control func(d *debugger)
+		want    []int
+	}{
+		{"normal speed", func(d *debugger) {}, []int{1, 1, 1, 1}},
+		{"quarter speed", func(d *debugger) { d.slower(); d.slower() }, []int{0, 0, 0, 1, 0, 0, 0, 1}},
+		{"half speed", func(d *debugger) { d.slower() }, []int{0, 1, 0, 1}},
+		{"slowest speed", func(d *debugger) { d.slower(); d.slower(); d.slower() }, []int{0, 0, 0, 1}},
+		{"double speed", func(d *debugger) { d.faster() }, []int{2, 2}},
+		{"quadruple speed", func(d *debugger) { d.faster(); d.faster() }, []int{4, 4}},
+		{"fastest speed", func(d *debugger) { d.faster(); d.faster(); d.faster() }, []int{4, 4}},
+		{"paused", func(d *debugger) { d.pause() }, []int{0, 0}},
+		{"resumed", func(d *debugger) { d.pause(); d.pause() }, []int{1, 1}},
+		{"step", func(d *debugger) { d.stepFrame() }, []int{1, 0, 0}},
+		{"step while paused", func(d *debugger) { d.pause(); d.stepFrame() }, []int{1, 0}},
+		{"resume after step", func(d *debugger) { d.stepFrame(); d.pause() }, []int{1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDebugger()
+			tt.control(&d)
+
+			var got []int
+			for range tt.want {
+				got = append(got, d.frames())
+			}
+
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("debugger.frames() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_debugger_status(t *testing.T) {
+	tests := []struct {
+		name    string
+		control func(d *debugger)
+		active  bool
+		want    string
+	}{
+		{"normal speed", func(d *debugger) {}, false, "FRAME 120 x1"},
+		{"quarter speed", func(d *debugger) { d.slower(); d.slower() }, true, "FRAME 120 x0.25"},
+		{"quadruple speed", func(d *debugger) { d.faster(); d.faster() }, true, "FRAME 120 x4"},
+		{"paused", func(d *debugger) { d.stepFrame() }, true, "FRAME 120 PAUSED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDebugger()
+			tt.control(&d)
+
+			if got := d.active(); got != tt.active {
+				t.Errorf("debugger.active() = %v, want %v", got, tt.active)
+			}
+			if got := d.status(120); got != tt.want {
+				t.Errorf("debugger.status() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
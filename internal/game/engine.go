@@ -3,8 +3,9 @@ package game
 import (
 	"github.com/bjatkin/flappy_boot/internal/alloc"
 	"github.com/bjatkin/flappy_boot/internal/assets"
-	"github.com/bjatkin/flappy_boot/internal/display"
 	hw_display "github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/dma"
+	"github.com/bjatkin/flappy_boot/internal/hardware/interrupt"
 	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
 	"github.com/bjatkin/flappy_boot/internal/hardware/save"
 	hw_sprite "github.com/bjatkin/flappy_boot/internal/hardware/sprite"
@@ -27,8 +28,25 @@ type Runable interface {
 
 // Engine is the core game engine
 type Engine struct {
-	// activeSprites are the sprites tha need to be drawn each frame
-	activeSprites map[*Sprite]struct{}
+	// activeSprites are the sprites tha need to be drawn each frame, only the first spriteCount sprites are active
+	activeSprites [hw_sprite.MaxAttrs]*Sprite
+	spriteCount   int
+	spriteSeq     int
+
+	// affine are the affine matrices used by the active sprites this frame
+	affine      [maxAffine]affineMatrix
+	affineCount int
+
+	// oamBuff is the sprite attribute buffer that is coppied into OAM memory on the next flush
+	oamBuff [hw_sprite.MaxAttrs]hw_sprite.Attrs
+
+	// activeWindows are the rectangular windows that need to be drawn each frame
+	activeWindows [2]*Window
+	spriteWindow  *Window
+	windowOutside memmap.WindowControll
+
+	// activeRasters are the raster effects that are updated on every scan line
+	activeRasters [maxRasters]*Raster
 
 	// activeBackgrounds are the backgrounds that need to be drawn each frame
 	activeBackgrounds [4]*Background
@@ -39,6 +57,47 @@ type Engine struct {
 	fadeFrac math.Fix8
 	doFade   bool
 
+	// palShadow is the faded palette that is coppied into palette memory on the next flush
+	palShadow [512]memmap.PaletteValue
+	palDirty  bool
+
+	// regs are the display registers that are coppied into the hardware registers on the next flush
+	regs shadowRegs
+
+	// flushMisses is the number of flushes that finished after VBlank had ended
+	flushMisses int
+
+	// voices are the sounds that are mixed together each frame, the last musicChannels voices are used by the music
+	voices   [maxVoices + musicChannels]Voice
+	voiceSeq int
+
+	// music is the song that's playing on the music voices
+	music musicPlayer
+
+	// soundBuff are the two mixed sound buffers, one is streamed to direct sound while the other is mixed
+	soundBuff [2][samplesPerFrame / 4]uint32
+	soundPage int
+
+	// sfx are the sound effects playing on each DMG channel
+	sfx [4]sfxPlayer
+
+	// dmg are the DMG channel registers that are written on the next flush
+	dmg [4]dmgRegs
+
+	// blendControll, blendAlpha and blendY are the color effects that are copied into the blend registers every frame
+	blendControll memmap.BlendControll
+	blendAlpha    memmap.BlendAlpha
+	blendY        memmap.BlendY
+
+	// mosaic is the mosaic size that is copied into the mosaic register every frame
+	mosaic memmap.MosaicSize
+
+	// scene is the name of the current scene, it's shown on the crash screen
+	scene string
+
+	// bitmap is the bitmap that is shown instead of the backgrounds, if it's nil the tiled display mode is used
+	bitmap *Bitmap
+
 	// Allocators
 	bgPalAlloc   *alloc.Pal
 	sprPalAlloc  *alloc.Pal
@@ -57,6 +116,23 @@ type Engine struct {
 	// frame is the current engine frame
 	frame int
 
+	// profiler times each part of the frame so it can be compared to the frame budget
+	profiler profiler
+
+	// seed is the seed for the games random numbers, it's only used if seeded is true
+	seed   int64
+	seeded bool
+
+	// recording holds the keys for each frame since recording started, replay is the replay that's
+	// being played and replayFrame is the next frame of the replay
+	recording   *Replay
+	replay      *Replay
+	replayFrame int
+
+	// objects are the sprites, backgrounds and other objects the engine has created, save states use them to
+	// find each object
+	objects objects
+
 	// Debug contains some simple sprites for debugging
 	Debug [20]*Sprite
 }
@@ -67,14 +143,27 @@ func NewEngine() *Engine {
 	memmap.SetReg(hw_display.Controll, hw_display.Sprite1D|hw_display.ForceBlank)
 
 	e := &Engine{
-		activeSprites: make(map[*Sprite]struct{}, 128),
-
 		// the first tile is left transparent and can be shared by all tile maps
 		bgTileAlloc:  alloc.NewVRAM(memmap.VRAM[memmap.TileOffset4:memmap.CharBlockOffset*2], 16),
 		sprTileAlloc: alloc.NewVRAM(memmap.VRAM[memmap.CharBlockOffset*4:], 16),
 		mapAlloc:     alloc.NewVRAM(memmap.VRAM[memmap.CharBlockOffset*2:], memmap.HalfKByte*2),
 	}
 
+	// the display is drawn from the shadow registers so they need to start with the same display state
+	e.regs.displayControll = memmap.GetReg(hw_display.Controll)
+
+	// enable the VBlank interrupt so vSyncWait can halt the cpu rather than spinning
+	memmap.SetReg(hw_display.Stat, memmap.GetReg(hw_display.Stat)|hw_display.VBlankIRQ)
+	interrupt.Handle(interrupt.VBlank, nil)
+	interrupt.Handle(interrupt.HBlank, e.hBlank)
+	memmap.SetReg(interrupt.Master, interrupt.MasterEnable)
+
+	e.initSound()
+	initClock()
+
+	// everything is visible outside of the windows by default
+	e.SetWindowOutside(LayerAll, true)
+
 	e.bgPalAlloc = alloc.NewPal(e.palBuff[:256])
 	e.sprPalAlloc = alloc.NewPal(e.palBuff[256:])
 
@@ -85,12 +174,18 @@ func NewEngine() *Engine {
 	}
 
 	e.Debug = debugSprites
+	e.initProfiler()
 
 	e.initFRAM()
 
 	return e
 }
 
+// SetScene sets the name of the current scene so it can be shown on the crash screen
+func (e *Engine) SetScene(name string) {
+	e.scene = name
+}
+
 // Frame return the current engine frame
 func (e *Engine) Frame() int {
 	return e.frame
@@ -99,46 +194,82 @@ func (e *Engine) Frame() int {
 // Run runs the provided Runable
 func (e *Engine) Init(run Runable) {
 	// enable sprites
-	memmap.SetReg(hw_display.Controll, *hw_display.Controll|hw_display.Sprites)
+	e.regs.displayControll |= hw_display.Sprites
+	memmap.SetReg(hw_display.Controll, e.regs.displayControll)
 	// hide all the sprites before the engine starts
 	e.drawSprites()
+	e.flushSprites()
 
 	err := run.Init(e)
 	if err != nil {
-		exit(err)
+		e.exit(err)
 	}
 }
 
 func (e *Engine) Update(run Runable) {
+	e.profiler.begin(ProfileUpdate, clock())
 	e.keyPoll()
 	err := run.Update(e)
 	if err != nil {
-		exit(err)
+		e.exit(err)
 	}
+	e.profiler.end(clock())
 
 	e.frame++
 }
 
+// Draw draws the current frame into the engines shadow OAM, palette and registers. Nothing is shown on screen
+// until the shadow state is flushed at the start of the next VBlank
 func (e *Engine) Draw() {
+	e.profiler.begin(ProfileDraw, clock())
+
 	// update the palette if needed
 	if e.doFade || e.bgPalAlloc.IsDirty() || e.sprPalAlloc.IsDirty() {
+		e.profiler.begin(ProfilePalette, clock())
 		e.updatePalette()
 		e.doFade = false
 		e.bgPalAlloc.MarkClean()
 		e.sprPalAlloc.MarkClean()
+		e.profiler.end(clock())
 	}
 
-	// copy active sprite data into OAM memory
+	// move the profiler bar, this must be done before the sprites are drawn
+	e.drawProfiler()
+
+	// copy active sprite data into the OAM buffer
+	e.profiler.begin(ProfileSprites, clock())
 	e.drawSprites()
+	e.profiler.end(clock())
 
 	// copy active background data into the background registers
 	e.drawBackgrounds()
 
+	// update the raster tables, this must be done after the backgrounds are drawn
+	e.drawRasters()
+
+	// copy active window data into the window registers
+	e.drawWindows()
+
+	// copy color effects into the blend registers
+	e.drawBlend()
+	e.drawMosaic()
+
+	// set the display mode, this must be done after the backgrounds are drawn
+	e.drawBitmap()
+
+	// mix the sound for the next frame, the music must be drawn first so new notes are mixed
+	e.drawMusic()
+	e.mixSound()
+	e.drawSFX()
+
+	e.profiler.end(clock())
 }
 
 // PalFade fades the current color palette towards the specified color
 // t is clamped to between 0 and 1. At 0 the color palette is completely unchanged.
 // At 1 the palette is completely the provided color.
+// Fades to White or Black use the hardware brightness effect, fades to any other color
+// are done by updating every color in the palette which is much slower
 func (e *Engine) PalFade(color memmap.PaletteValue, t math.Fix8) {
 	t = math.Clamp(t, 0, math.FixOne)
 	if e.fadeCol != color || e.fadeFrac != t {
@@ -148,10 +279,22 @@ func (e *Engine) PalFade(color memmap.PaletteValue, t math.Fix8) {
 	e.fadeFrac = t
 }
 
-// updatePalette will copy the current palette into palette RAM
+// updatePalette will copy the current palette into the shadow palette
 func (e *Engine) updatePalette() {
+	frac := e.fadeFrac
+	if e.hardwareFade() {
+		// the hardware handles the fade so the palette can be copied over unchanged
+		frac = 0
+	}
+
+	e.palDirty = true
+	if frac == 0 {
+		dma.Copy32(e.palShadow[:], e.palBuff[:])
+		return
+	}
+
 	for i := range e.palBuff {
-		memmap.Palette[i] = lerpColor(e.palBuff[i], e.fadeCol, e.fadeFrac)
+		e.palShadow[i] = lerpColor(e.palBuff[i], e.fadeCol, frac)
 	}
 }
 
@@ -185,11 +328,20 @@ func lerpColor(src, dest memmap.PaletteValue, t math.Fix8) memmap.PaletteValue {
 	return memmap.PaletteValue(red | green<<5 | blue<<10)
 }
 
-// drawSprites copies all the engines active sprites into OAM memory
+// drawSprites copies all the engines active sprites into the OAM buffer
 func (e *Engine) drawSprites() {
+	e.affineCount = 0
+
+	sortSprites(e.activeSprites[:e.spriteCount])
+
 	var i int
-	for s := range e.activeSprites {
-		hw_sprite.OAM[i] = *s.attrs()
+	for _, s := range e.activeSprites[:e.spriteCount] {
+		affineIndex := -1
+		if s.Affine {
+			affineIndex = e.affineIndex(s)
+		}
+
+		e.oamBuff[i] = *s.attrs(affineIndex)
 		i++
 	}
 
@@ -198,18 +350,20 @@ func (e *Engine) drawSprites() {
 		Attr1: hw_sprite.Attr1(511),
 	}
 	for ; i < 128; i++ {
-		hw_sprite.OAM[i] = clear
+		e.oamBuff[i] = clear
 	}
+
+	e.drawAffine()
 }
 
-// drawBackgrounds updates all the background registers and the display controll register based on the
+// drawBackgrounds updates all the shadow background registers and the shadow display controll register based on the
 // engines active background
 func (e *Engine) drawBackgrounds() {
 	// TODO: only update the backgrouds if something has changed?
 
 	// 0xF0FF masks out all the 'active backgrounds' bits from the controll register
 	// these bits are then added back to the controll value only if the background is still active
-	backgroundControll := memmap.GetReg(hw_display.Controll) & 0xF0FF
+	backgroundControll := e.regs.displayControll & 0xF0FF
 	for i := range e.activeBackgrounds {
 		if e.activeBackgrounds[i] == nil {
 			continue
@@ -226,32 +380,16 @@ func (e *Engine) drawBackgrounds() {
 			backgroundControll |= hw_display.BG3
 		}
 	}
-	memmap.SetReg(hw_display.Controll, backgroundControll)
+	e.regs.displayControll = backgroundControll
 
 	for i := range e.activeBackgrounds {
 		if e.activeBackgrounds[i] == nil {
 			continue
 		}
 
-		controll := e.activeBackgrounds[i].controll()
-		switch i {
-		case 0:
-			memmap.SetReg(hw_display.BG0Controll, controll)
-			memmap.SetReg(hw_display.BG0HOffset, e.activeBackgrounds[0].HScroll.Uint16())
-			memmap.SetReg(hw_display.BG0VOffset, e.activeBackgrounds[0].VScroll.Uint16())
-		case 1:
-			memmap.SetReg(hw_display.BG1Controll, controll)
-			memmap.SetReg(hw_display.BG1HOffset, e.activeBackgrounds[1].HScroll.Uint16())
-			memmap.SetReg(hw_display.BG1VOffset, e.activeBackgrounds[1].VScroll.Uint16())
-		case 2:
-			memmap.SetReg(hw_display.BG2Controll, controll)
-			memmap.SetReg(hw_display.BG2HOffset, e.activeBackgrounds[2].HScroll.Uint16())
-			memmap.SetReg(hw_display.BG2VOffset, e.activeBackgrounds[2].VScroll.Uint16())
-		case 3:
-			memmap.SetReg(hw_display.BG3Controll, controll)
-			memmap.SetReg(hw_display.BG3HOffset, e.activeBackgrounds[3].HScroll.Uint16())
-			memmap.SetReg(hw_display.BG3VOffset, e.activeBackgrounds[3].VScroll.Uint16())
-		}
+		e.regs.bgControll[i] = e.activeBackgrounds[i].controll()
+		e.regs.bgHOffset[i] = e.activeBackgrounds[i].HScroll.Uint16()
+		e.regs.bgVOffset[i] = e.activeBackgrounds[i].VScroll.Uint16()
 	}
 }
 
@@ -278,15 +416,71 @@ func (e *Engine) removeBackground(bg *Background) {
 	}
 }
 
-// addSprite adds a new sprite to the list of active sprites.
-func (e *Engine) addSprite(sprite *Sprite) {
-	e.activeSprites[sprite] = struct{}{}
+// addSprite adds a new sprite to the list of active sprites. If the list of active sprites is full an error is returned
+func (e *Engine) addSprite(sprite *Sprite) error {
+	if sprite.active {
+		return nil
+	}
+
+	if e.spriteCount == len(e.activeSprites) {
+		return alloc.ErrOOM
+	}
+
+	e.spriteSeq++
+	sprite.seq = e.spriteSeq
+	sprite.active = true
+
+	e.activeSprites[e.spriteCount] = sprite
+	e.spriteCount++
+	return nil
 }
 
 // removeSprite removes a sprite from the list of active sprites. It will not unload the sprites assets
 // from memory so you must do that yourself if the sprite is no longer needed
 func (e *Engine) removeSprite(sprite *Sprite) {
-	delete(e.activeSprites, sprite)
+	if !sprite.active {
+		return
+	}
+
+	for i := range e.activeSprites[:e.spriteCount] {
+		if e.activeSprites[i] != sprite {
+			continue
+		}
+
+		// the last sprite is moved into the empty spot, the list is sorted before every draw so the order is restored
+		e.spriteCount--
+		e.activeSprites[i] = e.activeSprites[e.spriteCount]
+		e.activeSprites[e.spriteCount] = nil
+		break
+	}
+
+	sprite.active = false
+}
+
+// sortSprites sorts the sprites into OAM order. Sprites are sorted by priority, then by Z and then by the order they
+// were shown, sprites earlier in the list are drawn on top. An insertion sort is used since the list is almost always
+// sorted already and it does not allocate
+func sortSprites(sprites []*Sprite) {
+	for i := 1; i < len(sprites); i++ {
+		s := sprites[i]
+		j := i
+		for ; j > 0 && spriteAbove(s, sprites[j-1]); j-- {
+			sprites[j] = sprites[j-1]
+		}
+		sprites[j] = s
+	}
+}
+
+// spriteAbove returns true if sprite a should be drawn above sprite b
+func spriteAbove(a, b *Sprite) bool {
+	if a.Priority != b.Priority {
+		return a.Priority < b.Priority
+	}
+	if a.Z != b.Z {
+		return a.Z > b.Z
+	}
+
+	return a.seq > b.seq
 }
 
 // initFRAM initializes FRAM so that it can be used to save the high score
@@ -324,33 +518,7 @@ func (e *Engine) LoadData(n int) []byte {
 	return load
 }
 
-// exit exits the game loop and draws error infromation to the screen
-func exit(err error) {
-	// TODO: this should be updated to use a 'system' font to write out the
-	// error data to make debugging easier
-	memmap.SetReg(hw_display.Controll, hw_display.Mode3|hw_display.BG2)
-
-	// Draw red to the screen so we can tell there was an error
-	blue := display.RGB15(0, 0, 31)
-	for i := 10; i < 240*160; i++ {
-		memmap.VRAM[i] = memmap.VRAMValue(blue)
-	}
-
-	// block forever
-	for {
-	}
-}
-
-// vSyncWait blocks while it waits for the screen to enter the vertical blank and then returns
+// vSyncWait halts the cpu until the screen enters the vertical blank and then returns
 func vSyncWait() {
-	// TODO: leverage hardware interrupts rather than spinning the GBA cpu like this.
-	// my guess is this is going to lead to pretty high power usage for no real benefit
-
-	// wait till VDraw
-	for hw_display.GetVCount() >= 160 {
-	}
-
-	// wailt tile VBlank
-	for hw_display.GetVCount() < 160 {
-	}
+	interrupt.WaitVBlank()
 }
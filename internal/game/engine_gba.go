@@ -20,9 +20,16 @@ func (h *Harness) Run(run Runable) error {
 
 	for {
 		h.E.Update(run)
+		h.E.Draw()
 
 		vSyncWait()
 
-		h.E.Draw()
+		h.E.flush()
+	}
+}
+
+// halt stops the game by blocking forever
+func halt() {
+	for {
 	}
 }
@@ -0,0 +1,87 @@
+//go:build standalone && headless
+
+package game
+
+import (
+	"os"
+	"time"
+
+	"github.com/bjatkin/flappy_boot/internal/emu/apu"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+// Harness is the headless harness, it runs the emulator without opening a window so frames
+// can be rendered on machines that do not have a display. Save data is never written to disk
+type Harness struct {
+	emulator
+
+	// recording is true once RecordAudio has been called, audio holds every sample generated since then
+	recording bool
+	audio     []int16
+}
+
+// NewHarness creates a new engine harness
+func NewHarness() *Harness {
+	return &Harness{
+		emulator: newEmulator(),
+	}
+}
+
+// Init init's the game, it must be called before Step
+func (h *Harness) Init(run Runable) {
+	h.init(run)
+}
+
+// Step runs a single frame of the game with the given key input register value.
+// the rendered frame is available in h.PPU.Screen once Step returns
+func (h *Harness) Step(keys memmap.Input) {
+	h.step(keys)
+
+	if h.recording {
+		h.audio = append(h.audio, h.APU.Samples...)
+	}
+}
+
+// RecordAudio starts recording the audio generated by each step so it can be written to a WAV file
+func (h *Harness) RecordAudio() {
+	h.recording = true
+}
+
+// Audio returns the stereo samples that have been recorded, the left sample is first
+func (h *Harness) Audio() []int16 {
+	return h.audio
+}
+
+// WriteWAV writes the recorded audio to a WAV file at path
+func (h *Harness) WriteWAV(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return apu.WriteWAV(f, h.audio)
+}
+
+// Frame returns the number of frames that have been stepped
+func (h *Harness) Frame() int {
+	return h.frame
+}
+
+// Run init's the game and runs it at 60 frames per second with no input
+func (h *Harness) Run(run Runable) {
+	h.Init(run)
+
+	tick := time.NewTicker(time.Second / 60)
+	defer tick.Stop()
+
+	for range tick.C {
+		h.Step(0xFFFF)
+	}
+}
+
+// Close releases the assets the game loaded. Assets are package variables so they stay loaded after the
+// harness is done with them, Close must be called before another harness is created in the same program
+func (h *Harness) Close() {
+	h.E.releaseAssets()
+}
@@ -0,0 +1,197 @@
+//go:build standalone && headless
+
+package game
+
+import (
+	"errors"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/assets"
+	"github.com/bjatkin/flappy_boot/internal/hardware/audio"
+	hw_display "github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/dma"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	"github.com/bjatkin/flappy_boot/internal/hardware/timer"
+)
+
+// crashRun is a Runable that shows a raster and then returns an error the second time it's updated
+type crashRun struct {
+	updates int
+}
+
+func (c *crashRun) Init(e *Engine) error {
+	return e.NewPaletteRaster(1).Show()
+}
+
+func (c *crashRun) Update(e *Engine) error {
+	c.updates++
+	if c.updates < 2 {
+		return nil
+	}
+
+	return errors.New("crash")
+}
+
+func TestHarness_Crash(t *testing.T) {
+	h := NewHarness()
+	run := &crashRun{}
+	h.Init(run)
+
+	for i := 0; i < 4; i++ {
+		h.Step(0xFFFF)
+	}
+
+	if run.updates != 2 {
+		t.Errorf("Harness.Step() ran %d updates after crashing, want 2", run.updates)
+	}
+
+	// the screen should be blue so the crash is visible
+	for _, pos := range [][2]int{{0, 0}, {120, 80}, {239, 159}} {
+		got := h.PPU.Screen.At(pos[0], pos[1]).(color.RGBA)
+		if got.R != 0 || got.G != 0 || got.B < 0xF0 {
+			t.Errorf("Harness.Step() pixel %d,%d = %v, want blue", pos[0], pos[1], got)
+		}
+	}
+
+	// the top of the F in the FATAL ERROR header should be white
+	if got := h.PPU.Screen.At(9, 8).(color.RGBA); got.R < 0xF0 || got.G < 0xF0 || got.B < 0xF0 {
+		t.Errorf("Harness.Step() pixel 9,8 = %v, want white", got)
+	}
+
+	// nothing should keep streaming sound once the game has crashed
+	if memmap.GetReg(dma.Controll1)&dma.DMAOn != 0 || memmap.GetReg(timer.Controll0)&timer.TimerStart != 0 {
+		t.Errorf("Harness.Step() the sound DMA or timer is still running after crashing")
+	}
+	if memmap.GetReg(audio.Stat)&audio.MasterSoundEnable != 0 {
+		t.Errorf("Harness.Step() master sound is still enabled after crashing")
+	}
+	if memmap.GetReg(hw_display.Stat)&hw_display.HBlankIRQ != 0 {
+		t.Errorf("Harness.Step() the HBlank interrupt is still enabled after crashing")
+	}
+}
+
+// soundRun is a Runable that plays a sound on the first update and a sound effect on the second
+type soundRun struct {
+	updates int
+}
+
+func (s *soundRun) Init(e *Engine) error {
+	return nil
+}
+
+func (s *soundRun) Update(e *Engine) error {
+	s.updates++
+	switch s.updates {
+	case 1:
+		e.PlaySound(assets.NewSound([]int8{100, -100, 100, -100}, 0))
+	case 2:
+		e.PlaySFX(&SFX{Channel: ChannelSquare, Notes: []int{440}, Envelope: Envelope{Volume: 15}})
+	}
+
+	return nil
+}
+
+func TestHarness_RecordAudio(t *testing.T) {
+	h := NewHarness()
+	h.Init(&soundRun{})
+	h.RecordAudio()
+
+	// loud returns true if any sample in the frame is louder than the direct sound alone
+	loud := func(frame []int16) bool {
+		for _, v := range frame {
+			if v > 200<<6 || v < -200<<6 {
+				return true
+			}
+		}
+		return false
+	}
+
+	var frames [][]int16
+	for i := 0; i < 4; i++ {
+		h.Step(0xFFFF)
+		frames = append(frames, append([]int16{}, h.APU.Samples...))
+	}
+
+	tests := []struct {
+		name  string
+		frame int
+		want  bool
+	}{
+		// the sound plays durring the frame after it's mixed, the sfx plays on top of it a frame later
+		{"direct sound", 1, false},
+		{"direct sound and sfx", 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if silent(frames[tt.frame]) {
+				t.Fatalf("Harness.Step() frame %d is silent", tt.frame)
+			}
+			if got := loud(frames[tt.frame]); got != tt.want {
+				t.Errorf("Harness.Step() frame %d loud = %v, want %v", tt.frame, got, tt.want)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "audio.wav")
+	if err := h.WriteWAV(path); err != nil {
+		t.Fatalf("Harness.WriteWAV() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat wav file: %v", err)
+	}
+	if want := int64(44 + len(h.Audio())*2); info.Size() != want {
+		t.Errorf("Harness.WriteWAV() wrote %d bytes, want %d", info.Size(), want)
+	}
+}
+
+// silent returns true if every sample is 0
+func silent(samples []int16) bool {
+	for _, v := range samples {
+		if v != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// profileRun is a Runable that shows the profiler bar
+type profileRun struct{}
+
+func (p *profileRun) Init(e *Engine) error {
+	return e.ShowProfiler(true)
+}
+
+func (p *profileRun) Update(e *Engine) error {
+	return nil
+}
+
+func TestHarness_Profile(t *testing.T) {
+	h := NewHarness()
+	h.Init(&profileRun{})
+
+	for i := 0; i < 3; i++ {
+		h.Step(0xFFFF)
+	}
+
+	profile := h.E.Profile()
+	if profile[ProfileDraw] <= 0 || profile[ProfileFlush] <= 0 {
+		t.Errorf("Engine.Profile() = %v, want Draw and Flush to be timed", profile)
+	}
+
+	if h.E.spriteCount == 0 {
+		t.Errorf("Engine.ShowProfiler() did not show the profiler bar")
+	}
+
+	if err := h.E.ShowProfiler(false); err != nil {
+		t.Fatalf("Engine.ShowProfiler() error = %v", err)
+	}
+	if h.E.spriteCount != 0 {
+		t.Errorf("Engine.ShowProfiler() left %d sprites shown", h.E.spriteCount)
+	}
+}
@@ -3,155 +3,112 @@
 package game
 
 import (
-	"log"
+	"errors"
 
+	"github.com/bjatkin/flappy_boot/internal/emu/apu"
 	"github.com/bjatkin/flappy_boot/internal/emu/ppu"
-	"github.com/bjatkin/flappy_boot/internal/hardware/display"
+	hw_display "github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/dma"
+	"github.com/bjatkin/flappy_boot/internal/hardware/interrupt"
 	"github.com/bjatkin/flappy_boot/internal/hardware/key"
 	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
-	"github.com/bjatkin/flappy_boot/internal/hardware/save"
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
-const saveFile = "flappy_boot_stand.sav"
+// errHalt is used to unwind the game loop when the engine halts
+var errHalt = errors.New("engine halted")
 
-// Harness is the standalone harness that allows the emulator to be used in standalone mode
-type Harness struct {
-	E        *Engine
-	R        Runable
-	PPU      *ppu.PPU
-	saveData [save.DataLen]byte
-	frame    int
+// halt stops the game, the emulator recovers from the panic and keeps rendering the last frame
+// so the error screen can still be displayed
+func halt() {
+	panic(errHalt)
 }
 
-// NewHarness creates a new engine harness
-func NewHarness() *Harness {
-	save.LoadData(saveFile)
+// emulator is the emulated GBA core that is shared by both the window and headless harnesses
+type emulator struct {
+	E     *Engine
+	R     Runable
+	PPU   *ppu.PPU
+	APU   *apu.APU
+	frame int
 
-	harness := &Harness{
+	// halted is true once the engine has halted, no more game frames are run after this
+	halted bool
+}
+
+// newEmulator creates a new emulator core
+func newEmulator() emulator {
+	emu := emulator{
 		E:   NewEngine(),
 		PPU: ppu.New(),
+		APU: apu.New(),
 	}
 
-	harness.PPU.Backgrounds[0].SkipGFXUpdate = true
-	harness.PPU.Backgrounds[1].SkipGFXUpdate = true
+	emu.PPU.Backgrounds[0].SkipGFXUpdate = true
+	emu.PPU.Backgrounds[1].SkipGFXUpdate = true
+	emu.PPU.HBlank = hBlank
+	emu.APU.Refill = dma.RefillFIFO
 
-	return harness
+	return emu
 }
 
-// Update runs the GBA update/ draw code at 60TPS
-func (h *Harness) Update() error {
-	h.frame++
-	h.E.Draw()
-
-	// update the input register (note we only need to update keys that the game actually uses)
-	keyReg := memmap.Input(0xFFFF)
-	if ebiten.IsKeyPressed(ebiten.KeyEnter) {
-		keyReg &= ^key.StartMask
-	}
-	if ebiten.IsKeyPressed(ebiten.KeyC) {
-		keyReg &= ^key.AMask
-	}
-	if ebiten.IsKeyPressed(ebiten.KeyUp) {
-		keyReg &= ^key.UpMask
-	}
-	if ebiten.IsKeyPressed(ebiten.KeyDown) {
-		keyReg &= ^key.DownMask
-	}
-	*key.Input = keyReg
+// init init's the game so it's ready to be stepped
+func (e *emulator) init(run Runable) {
+	defer e.recoverHalt()
 
-	h.E.Update(h.R)
-	if h.frame%10 == 0 {
-		// only check the save buffer every 10 frame to help improve performance
-		h.updateSaveData(saveFile)
-	}
-	return nil
+	e.R = run
+	e.E.Init(run)
 }
 
-// updateSaveData updates the save data in the save file
-func (h *Harness) updateSaveData(path string) {
-	var delta bool
-	for i := 0; i < len(h.saveData); i++ {
-		if h.saveData[i] != byte(save.SRAM[i]) {
-			delta = true
-		}
-		// update save data so we can tell if something changes
-		h.saveData[i] = byte(save.SRAM[i])
-	}
+// step runs a single GBA frame using the provided key input register value.
+// once the frame has been run the PPU is updated so the Screen image contains the new frame,
+// the APU generates the next frame of audio and the VBlank interrupt is raised
+func (e *emulator) step(keys memmap.Input) {
+	e.frame++
+	defer e.vBlank()
 
-	if !delta {
-		// save data has not changed, no reason to do an update
+	if e.halted {
 		return
 	}
+	defer e.recoverHalt()
+
+	e.E.Draw()
 
-	save.SaveData(saveFile, h.saveData[:])
+	*key.Input = keys
 
-	return
+	e.E.Update(e.R)
 }
 
-// Draw takes the data form inside the simulated GBA memory and draws it onto the screen
-// this can happy more than 60 times a second which is why the actuall GBA draw call needs to be in the Update function
-func (h *Harness) Draw(screen *ebiten.Image) {
-	// TODO: should probably just hand the screen directly into the PPU
-	h.PPU.Update()
-
-	// backgrounds are all rendeder by the PPU
-	screen.DrawImage(ebiten.NewImageFromImage(h.PPU.Screen), &ebiten.DrawImageOptions{})
-
-	// i is the priority, we draw from lowest prioirity(3) to highest priority(0) so higher priorities
-	// overwrite pixels from lower priorities
-	for i := 3; i >= 0; i-- {
-		for _, spr := range h.PPU.Sprites {
-			if !spr.Enabled {
-				continue
-			}
-			if spr.Priority != i {
-				continue
-			}
-
-			y := ((spr.Pos.Y + spr.Size.Y) % 0xFF) - spr.Size.Y
-			x := ((spr.Pos.X + spr.Size.X) % 0x1FF) - spr.Size.X
-			if x > 240 || y > 160 {
-				continue
-			}
-
-			transform := ebiten.GeoM{}
-			switch {
-			case spr.VFlip && spr.HFlip:
-				transform.Scale(-1, -1)
-				// Transform must take scale into account since all the sprites are 64x64 by default
-				transform.Translate(float64(x+spr.Size.X), float64((y + spr.Size.Y)))
-			case spr.VFlip:
-				transform.Scale(1, -1)
-				// Transform must take scale into account since all the sprites are 64x64 by default
-				transform.Translate(float64(x), float64((y + spr.Size.Y)))
-			case spr.HFlip:
-				transform.Scale(-1, 1)
-				// Transform must take scale into account since all the sprites are 64x64 by default
-				transform.Translate(float64(x+spr.Size.X), float64(y))
-			default:
-				transform.Translate(float64(x), float64(y))
-			}
-			screen.DrawImage(ebiten.NewImageFromImage(spr.Image), &ebiten.DrawImageOptions{GeoM: transform})
-		}
+// vBlank draws the frame, generates the audio that plays durring the next frame and then raises the VBlank
+// interrupt just like the GBA does when the screen enters the vertical blank
+func (e *emulator) vBlank() {
+	// the crash screen is drawn straight into the hardware registers so the engine must not flush over it
+	if !e.halted {
+		memmap.SetReg(hw_display.VCount, hw_display.Height)
+		e.E.flush()
 	}
-}
 
-// Layout just returns the resolution of the GBA
-func (h *Harness) Layout(outsideWidth, outsizeHeight int) (int, int) {
-	return display.Width, display.Height
+	e.PPU.Update()
+	e.APU.Update()
+	interrupt.Raise(interrupt.VBlank)
 }
 
-// Run init's the game and starts running it
-func (h *Harness) Run(run Runable) {
-	ebiten.SetWindowSize(display.Width*4, display.Height*4)
-	ebiten.SetWindowTitle("Flappy Boot Advance")
-	ebiten.SetTPS(60) // match the refresh rate of the GBA (more or less)
+// hBlank raises the HBlank interrupt after each scan line if it's enabled in the display stat register
+func hBlank() {
+	if memmap.GetReg(hw_display.Stat)&hw_display.HBlankIRQ > 0 {
+		interrupt.Raise(interrupt.HBlank)
+	}
+}
 
-	h.E.Init(run)
-	h.R = run
+// recoverHalt recovers from the panic caused by the engine halting, any other panics are re-raised
+func (e *emulator) recoverHalt() {
+	r := recover()
+	if r == nil {
+		return
+	}
 
-	if err := ebiten.RunGame(h); err != nil {
-		log.Fatal(err)
+	if r != errHalt {
+		panic(r)
 	}
+
+	e.halted = true
 }
@@ -0,0 +1,62 @@
+package game
+
+import (
+	"testing"
+
+	hw_sprite "github.com/bjatkin/flappy_boot/internal/hardware/sprite"
+)
+
+func Test_sortSprites(t *testing.T) {
+	a := &Sprite{Priority: hw_sprite.Priority0, seq: 1}
+	b := &Sprite{Priority: hw_sprite.Priority1, seq: 2}
+	c := &Sprite{Priority: hw_sprite.Priority1, Z: 1, seq: 3}
+	d := &Sprite{Priority: hw_sprite.Priority1, seq: 4}
+	e := &Sprite{Priority: hw_sprite.Priority3, Z: 10, seq: 5}
+
+	tests := []struct {
+		name    string
+		sprites []*Sprite
+		want    []*Sprite
+	}{
+		{
+			"empty",
+			[]*Sprite{},
+			[]*Sprite{},
+		},
+		{
+			"already sorted",
+			[]*Sprite{a, c, d, b, e},
+			[]*Sprite{a, c, d, b, e},
+		},
+		{
+			"priority",
+			[]*Sprite{e, b, a},
+			[]*Sprite{a, b, e},
+		},
+		{
+			"z order",
+			[]*Sprite{b, c},
+			[]*Sprite{c, b},
+		},
+		{
+			"most recently shown on top",
+			[]*Sprite{b, d},
+			[]*Sprite{d, b},
+		},
+		{
+			"reversed",
+			[]*Sprite{e, b, d, c, a},
+			[]*Sprite{a, c, d, b, e},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortSprites(tt.sprites)
+			for i := range tt.want {
+				if tt.sprites[i] != tt.want[i] {
+					t.Errorf("sortSprites() sprite %d has seq %d, want seq %d", i, tt.sprites[i].seq, tt.want[i].seq)
+				}
+			}
+		})
+	}
+}
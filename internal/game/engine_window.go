@@ -0,0 +1,257 @@
+//go:build standalone && !headless
+
+package game
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"log"
+	"time"
+
+	"github.com/bjatkin/flappy_boot/internal/emu/apu"
+	"github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/save"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	saveFile  = "flappy_boot_stand.sav"
+	stateFile = "flappy_boot_stand.state"
+)
+
+var (
+	recordFlag = flag.String("record", "", "record the keys for every frame to a replay file at this path")
+	replayFlag = flag.String("replay", "", "play back the replay file at this path before handing control back to the player")
+)
+
+// audioLatency is the most audio the harness will buffer, a 10th of a second is enough to cover
+// frames that are run late without the sound falling noticeably behind the game
+const audioLatency = apu.SampleRate / 10
+
+// statusBG is the background color of the debugger status overlay
+var statusBG = color.RGBA{A: 0xC0}
+
+// Harness is the standalone harness that allows the emulator to be used in standalone mode
+type Harness struct {
+	emulator
+	saveData [save.DataLen]byte
+
+	// audio is the stream of samples from the APU to the audio player. The web build plays the
+	// audio using Web Audio and the other builds use the systems audio device
+	audio *apu.Stream
+
+	// input maps the keyboard and gamepads onto the GBA buttons
+	input *keyInput
+
+	// debug pauses, steps and changes the speed of the game
+	debug debugger
+
+	// capture saves screenshots and gifs of the screen
+	capture capturer
+}
+
+// NewHarness creates a new engine harness
+func NewHarness() *Harness {
+	save.LoadData(saveFile)
+
+	h := &Harness{
+		emulator: newEmulator(),
+		audio:    apu.NewStream(audioLatency),
+		input:    newKeyInput(),
+		debug:    newDebugger(),
+	}
+	h.initReplay()
+
+	return h
+}
+
+// initReplay starts playing or recording a replay if the replay or record flags are set. The seed
+// must be set before the game is created so this is done when the harness is created
+func (h *Harness) initReplay() {
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+
+	if *replayFlag != "" {
+		replay, err := ReadReplay(*replayFlag)
+		if err != nil {
+			log.Fatalf("failed to read replay: %v", err)
+		}
+		h.E.Play(replay)
+	}
+
+	if *recordFlag != "" {
+		if _, ok := h.E.Seed(); !ok {
+			h.E.SetSeed(time.Now().UnixNano())
+		}
+		h.E.Record()
+	}
+}
+
+// saveRecording writes the recording to the replay file set by the record flag
+func (h *Harness) saveRecording() {
+	if *recordFlag == "" {
+		return
+	}
+
+	if err := WriteReplay(*recordFlag, h.E.Recording()); err != nil {
+		fmt.Printf("failed to save replay: %v\n", err)
+	}
+}
+
+// Update runs the GBA update/ draw code at 60TPS, the debugger can run more or less than one frame each tick
+func (h *Harness) Update() error {
+	// the game is paused while the buttons are being rebound
+	switch {
+	case h.input.rebind != nil:
+		h.input.updateRebind()
+		return nil
+	case h.input.openRebind():
+		return nil
+	case inpututil.IsKeyJustPressed(saveStateKey):
+		h.saveState(stateFile)
+	case inpututil.IsKeyJustPressed(loadStateKey):
+		h.loadState(stateFile)
+	case inpututil.IsKeyJustPressed(screenshotKey):
+		h.screenshot(h.captureName("png"))
+	case inpututil.IsKeyJustPressed(gifKey):
+		h.startGIF(h.captureName("gif"))
+	}
+	h.updateDebugger()
+
+	for i := h.debug.frames(); i > 0; i-- {
+		h.step(h.input.poll())
+		h.audio.Write(h.APU.Samples)
+		h.updateCapture()
+
+		if h.frame%10 == 0 {
+			// only check the save buffer every 10 frame to help improve performance
+			h.updateSaveData(saveFile)
+		}
+	}
+	return nil
+}
+
+// saveState writes a save state to the state file at path
+func (h *Harness) saveState(path string) {
+	data, err := h.SaveState()
+	if err != nil {
+		fmt.Printf("failed to save state: %v\n", err)
+		return
+	}
+
+	if err := writeStateFile(path, data); err != nil {
+		fmt.Printf("failed to save state: %v\n", err)
+	}
+}
+
+// loadState loads the save state in the state file at path
+func (h *Harness) loadState(path string) {
+	data, err := readStateFile(path)
+	if err != nil {
+		fmt.Printf("failed to load state: %v\n", err)
+		return
+	}
+
+	if err := h.LoadState(data); err != nil {
+		fmt.Printf("failed to load state: %v\n", err)
+		return
+	}
+
+	// the state restores the SRAM it was saved with, the save data is synced to it so the
+	// restored SRAM is not written over the save file
+	for i := range h.saveData {
+		h.saveData[i] = byte(save.SRAM[i])
+	}
+}
+
+// updateDebugger checks the debugger hotkeys
+func (h *Harness) updateDebugger() {
+	switch {
+	case inpututil.IsKeyJustPressed(pauseKey):
+		h.debug.pause()
+	case inpututil.IsKeyJustPressed(stepKey):
+		h.debug.stepFrame()
+	case inpututil.IsKeyJustPressed(slowerKey):
+		h.debug.slower()
+	case inpututil.IsKeyJustPressed(fasterKey):
+		h.debug.faster()
+	}
+}
+
+// updateSaveData updates the save data in the save file
+func (h *Harness) updateSaveData(path string) {
+	var delta bool
+	for i := 0; i < len(h.saveData); i++ {
+		if h.saveData[i] != byte(save.SRAM[i]) {
+			delta = true
+		}
+		// update save data so we can tell if something changes
+		h.saveData[i] = byte(save.SRAM[i])
+	}
+
+	if !delta {
+		// save data has not changed, no reason to do an update
+		return
+	}
+
+	save.SaveData(saveFile, h.saveData[:])
+
+	return
+}
+
+// Draw takes the frame rendered by the PPU and draws it onto the screen
+// this can happy more than 60 times a second which is why the actuall GBA draw call needs to be in the Update function
+func (h *Harness) Draw(screen *ebiten.Image) {
+	if h.input.rebind != nil {
+		h.input.drawRebind(screen)
+		return
+	}
+
+	screen.WritePixels(h.PPU.Screen.Pix)
+
+	if h.debug.active() {
+		h.drawStatus(screen)
+	}
+}
+
+// drawStatus draws the debugger status overlay in the top left corner of the screen
+func (h *Harness) drawStatus(screen *ebiten.Image) {
+	status := h.debug.status(h.E.Frame())
+
+	// the debug font is 6 pixels wide and 16 pixels tall
+	vector.DrawFilledRect(screen, 0, 0, float32(len(status)*6+4), 16, statusBG, false)
+	ebitenutil.DebugPrintAt(screen, status, 2, 0)
+}
+
+// Layout just returns the resolution of the GBA
+func (h *Harness) Layout(outsideWidth, outsizeHeight int) (int, int) {
+	return display.Width, display.Height
+}
+
+// Run init's the game and starts running it
+func (h *Harness) Run(run Runable) {
+	ebiten.SetWindowSize(display.Width*4, display.Height*4)
+	ebiten.SetWindowTitle("Flappy Boot Advance")
+	ebiten.SetTPS(60) // match the refresh rate of the GBA (more or less)
+
+	h.init(run)
+
+	player, err := audio.NewContext(apu.SampleRate).NewPlayer(h.audio)
+	if err != nil {
+		log.Fatal(err)
+	}
+	player.Play()
+
+	err = ebiten.RunGame(h)
+	h.saveRecording()
+	h.saveGIF()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,278 @@
+//go:build standalone && !headless
+
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/bjatkin/flappy_boot/internal/emu/input"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	// mappingFile is the file the key and gamepad mapping is loaded from
+	mappingFile = "flappy_boot_keys.yaml"
+
+	// rebindKey opens the rebinding screen, skipKey skips the button that's being rebound.
+	// saveStateKey and loadStateKey save and load the emulator state. pauseKey, stepKey, slowerKey and fasterKey
+	// are the debugger controlls. screenshotKey saves a screenshot and gifKey records a gif.
+	// None of the hotkeys can be bound to a GBA button
+	rebindKey     = ebiten.KeyF1
+	skipKey       = ebiten.KeyEscape
+	saveStateKey  = ebiten.KeyF5
+	loadStateKey  = ebiten.KeyF9
+	pauseKey      = ebiten.KeyF2
+	stepKey       = ebiten.KeyF3
+	slowerKey     = ebiten.KeyF6
+	fasterKey     = ebiten.KeyF7
+	screenshotKey = ebiten.KeyF8
+	gifKey        = ebiten.KeyF10
+
+	// stickDeadZone is how far the left stick needs to be pushed before it presses a direction
+	stickDeadZone = 0.5
+)
+
+// hotkeys are the keys used by the harness, they can not be bound to a GBA button
+var hotkeys = []ebiten.Key{rebindKey, skipKey, saveStateKey, loadStateKey, pauseKey, stepKey, slowerKey, fasterKey, screenshotKey, gifKey}
+
+// rebindBG is the background color of the rebinding screen
+var rebindBG = color.RGBA{R: 0x10, G: 0x18, B: 0x40, A: 0xFF}
+
+// gamepadButtons are the names of the standard gamepad buttons in the mapping file
+var gamepadButtons = map[string]ebiten.StandardGamepadButton{
+	"RightBottom":      ebiten.StandardGamepadButtonRightBottom,
+	"RightRight":       ebiten.StandardGamepadButtonRightRight,
+	"RightLeft":        ebiten.StandardGamepadButtonRightLeft,
+	"RightTop":         ebiten.StandardGamepadButtonRightTop,
+	"FrontTopLeft":     ebiten.StandardGamepadButtonFrontTopLeft,
+	"FrontTopRight":    ebiten.StandardGamepadButtonFrontTopRight,
+	"FrontBottomLeft":  ebiten.StandardGamepadButtonFrontBottomLeft,
+	"FrontBottomRight": ebiten.StandardGamepadButtonFrontBottomRight,
+	"CenterLeft":       ebiten.StandardGamepadButtonCenterLeft,
+	"CenterRight":      ebiten.StandardGamepadButtonCenterRight,
+	"LeftStick":        ebiten.StandardGamepadButtonLeftStick,
+	"RightStick":       ebiten.StandardGamepadButtonRightStick,
+	"LeftTop":          ebiten.StandardGamepadButtonLeftTop,
+	"LeftBottom":       ebiten.StandardGamepadButtonLeftBottom,
+	"LeftLeft":         ebiten.StandardGamepadButtonLeftLeft,
+	"LeftRight":        ebiten.StandardGamepadButtonLeftRight,
+	"CenterCenter":     ebiten.StandardGamepadButtonCenterCenter,
+}
+
+// stickDirection is a direction of the left stick that can be bound like a gamepad button
+type stickDirection struct {
+	axis ebiten.StandardGamepadAxis
+	sign float64
+}
+
+// stickDirections are the names of the left stick directions in the mapping file
+var stickDirections = map[string]stickDirection{
+	"LeftStickRight": {ebiten.StandardGamepadAxisLeftStickHorizontal, 1},
+	"LeftStickLeft":  {ebiten.StandardGamepadAxisLeftStickHorizontal, -1},
+	"LeftStickUp":    {ebiten.StandardGamepadAxisLeftStickVertical, -1},
+	"LeftStickDown":  {ebiten.StandardGamepadAxisLeftStickVertical, 1},
+}
+
+// keyInput polls the keyboard and gamepads and converts them into the GBA key input register
+type keyInput struct {
+	mapping *input.Mapping
+	keys    map[string]ebiten.Key
+
+	// gamepads are the connected gamepads that have a standard layout
+	gamepads []ebiten.GamepadID
+
+	// rebind is the rebinding screen, it's nil unless the buttons are being rebound
+	rebind *input.Rebinder
+	// sticks are the stick directions that were pressed on the last frame of the rebinding screen
+	sticks map[string]bool
+}
+
+// newKeyInput loads the mapping file, the default mapping is used if the file is invalid
+func newKeyInput() *keyInput {
+	k := &keyInput{
+		keys:   make(map[string]ebiten.Key),
+		sticks: make(map[string]bool),
+	}
+
+	mapping, err := input.Load(mappingFile)
+	if err == nil {
+		err = k.setMapping(mapping)
+	}
+	if err != nil {
+		// don't error out just because the mapping file is invalid
+		fmt.Printf("failed to load key mapping, using the default mapping: %v\n", err)
+		_ = k.setMapping(input.DefaultMapping())
+	}
+
+	return k
+}
+
+// setMapping checks every key and gamepad button in the mapping and then starts using it
+func (k *keyInput) setMapping(m *input.Mapping) error {
+	keys := make(map[string]ebiten.Key)
+	for b, binding := range m {
+		for _, name := range binding.Keys {
+			var key ebiten.Key
+			if err := key.UnmarshalText([]byte(name)); err != nil || isHotkey(key) {
+				return fmt.Errorf("%s can not be bound to the key %q", input.Button(b), name)
+			}
+			keys[name] = key
+		}
+
+		for _, name := range binding.Gamepad {
+			_, button := gamepadButtons[name]
+			_, stick := stickDirections[name]
+			if !button && !stick {
+				return fmt.Errorf("%s can not be bound to the gamepad button %q", input.Button(b), name)
+			}
+		}
+	}
+
+	k.mapping = m
+	k.keys = keys
+	return nil
+}
+
+// isHotkey returns true if the key is one of the harness hotkeys
+func isHotkey(key ebiten.Key) bool {
+	for _, hotkey := range hotkeys {
+		if key == hotkey {
+			return true
+		}
+	}
+
+	return false
+}
+
+// poll returns the key input register for the keys and gamepad buttons that are pressed
+func (k *keyInput) poll() memmap.Input {
+	k.updateGamepads()
+	return k.mapping.Input(k.keyPressed, k.gamepadPressed)
+}
+
+// updateGamepads updates the list of connected gamepads
+func (k *keyInput) updateGamepads() {
+	k.gamepads = k.gamepads[:0]
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			k.gamepads = append(k.gamepads, id)
+		}
+	}
+}
+
+// keyPressed returns true if the key is held down
+func (k *keyInput) keyPressed(name string) bool {
+	key, ok := k.keys[name]
+	return ok && ebiten.IsKeyPressed(key)
+}
+
+// gamepadPressed returns true if the gamepad button or stick direction is held down on any gamepad
+func (k *keyInput) gamepadPressed(name string) bool {
+	for _, id := range k.gamepads {
+		if button, ok := gamepadButtons[name]; ok && ebiten.IsStandardGamepadButtonPressed(id, button) {
+			return true
+		}
+		if dir, ok := stickDirections[name]; ok && ebiten.StandardGamepadAxisValue(id, dir.axis)*dir.sign > stickDeadZone {
+			return true
+		}
+	}
+
+	return false
+}
+
+// openRebind opens the rebinding screen if the rebind key was just pressed
+func (k *keyInput) openRebind() bool {
+	if !inpututil.IsKeyJustPressed(rebindKey) {
+		return false
+	}
+
+	// sticks that are already pushed are not bound until they're released
+	k.updateGamepads()
+	for name := range stickDirections {
+		k.sticks[name] = k.gamepadPressed(name)
+	}
+
+	k.rebind = input.NewRebinder(k.mapping)
+	return true
+}
+
+// updateRebind binds the next key or gamepad button that's pressed. Once every button has been bound
+// the new mapping is saved and the rebinding screen is closed
+func (k *keyInput) updateRebind() {
+	k.updateGamepads()
+
+	for _, key := range inpututil.AppendJustPressedKeys(nil) {
+		k.rebindPress(key)
+		if k.rebind == nil {
+			return
+		}
+	}
+
+	for _, id := range k.gamepads {
+		for _, button := range inpututil.AppendJustPressedStandardGamepadButtons(id, nil) {
+			for name, b := range gamepadButtons {
+				if b == button {
+					k.rebind.BindGamepad(name)
+				}
+			}
+		}
+	}
+
+	// stick directions are bound when they're first pushed past the dead zone
+	for name := range stickDirections {
+		pressed := k.gamepadPressed(name)
+		if pressed && !k.sticks[name] {
+			k.rebind.BindGamepad(name)
+		}
+		k.sticks[name] = pressed
+	}
+
+	if !k.rebind.Done() {
+		return
+	}
+
+	mapping := k.rebind.Mapping()
+	k.rebind = nil
+	if err := k.setMapping(mapping); err != nil {
+		fmt.Printf("failed to set key mapping: %v\n", err)
+		return
+	}
+	if err := input.Save(mappingFile, mapping); err != nil {
+		fmt.Printf("failed to save key mapping: %v\n", err)
+	}
+}
+
+// rebindPress binds a key that was just pressed on the rebinding screen. The harness hotkeys can't be
+// bound so they're ignored, otherwise the new mapping would be rejected once every button was bound
+func (k *keyInput) rebindPress(key ebiten.Key) {
+	switch {
+	case key == skipKey:
+		k.rebind.Skip()
+	case key == rebindKey:
+		// the rebind key closes the rebinding screen without saving
+		k.rebind = nil
+	case isHotkey(key):
+		return
+	case key.String() != "":
+		k.rebind.BindKey(key.String())
+	}
+}
+
+// drawRebind draws the rebinding screen over the top of the screen
+func (k *keyInput) drawRebind(screen *ebiten.Image) {
+	screen.Fill(rebindBG)
+
+	binding := k.rebind.Binding()
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf(
+		"Press a key or button for %s\n\nKeys: %s\nGamepad: %s\n\nEsc skips %s\nF1 cancels",
+		k.rebind.Button(),
+		strings.Join(binding.Keys, ", "),
+		strings.Join(binding.Gamepad, ", "),
+		k.rebind.Button(),
+	), 8, 8)
+}
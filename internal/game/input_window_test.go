@@ -0,0 +1,76 @@
+//go:build standalone && !headless
+
+package game
+
+import (
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/emu/input"
+	"github.com/go-test/deep"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func Test_keyInput_rebindPress(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   []ebiten.Key
+		button input.Button
+		closed bool
+		want   *input.Mapping
+	}{
+		{
+			"bind a key",
+			[]ebiten.Key{ebiten.KeyQ},
+			input.B,
+			false,
+			func() *input.Mapping {
+				m := input.DefaultMapping()
+				m[input.A].Keys = []string{"Q"}
+				return m
+			}(),
+		},
+		{
+			"skip a button",
+			[]ebiten.Key{skipKey},
+			input.B,
+			false,
+			input.DefaultMapping(),
+		},
+		{
+			"hotkeys are ignored",
+			[]ebiten.Key{saveStateKey, loadStateKey, pauseKey, stepKey, slowerKey, fasterKey, screenshotKey, gifKey},
+			input.A,
+			false,
+			input.DefaultMapping(),
+		},
+		{
+			"cancel",
+			[]ebiten.Key{rebindKey},
+			input.A,
+			true,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &keyInput{rebind: input.NewRebinder(input.DefaultMapping())}
+			r := k.rebind
+			for _, key := range tt.keys {
+				k.rebindPress(key)
+			}
+
+			if closed := k.rebind == nil; closed != tt.closed {
+				t.Fatalf("keyInput.rebindPress() closed the rebinding screen = %v, want %v", closed, tt.closed)
+			}
+			if tt.closed {
+				return
+			}
+			if r.Button() != tt.button {
+				t.Errorf("keyInput.rebindPress() button = %v, want %v", r.Button(), tt.button)
+			}
+			if diff := deep.Equal(r.Mapping(), tt.want); diff != nil {
+				t.Errorf("keyInput.rebindPress() mapping %v", diff)
+			}
+		})
+	}
+}
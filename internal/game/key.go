@@ -6,10 +6,11 @@ import (
 	"github.com/bjatkin/flappy_boot/internal/key"
 )
 
-// keyPoll reads they key input register and the current key state
+// keyPoll reads they key input register and the current key state, the keys are replaced by the
+// replay if one is playing
 func (e *Engine) keyPoll() {
 	e.previousKeys = e.currentKeys
-	e.currentKeys = memmap.GetReg(hw_key.Input)
+	e.currentKeys = e.replayKeys(memmap.GetReg(hw_key.Input))
 }
 
 // StillPressed returns true if the key is being held down
@@ -29,11 +29,14 @@ func (e *Engine) NewMetaSprite(offset []math.V2, indexes []int, asset *assets.Ti
 		sprites[i] = sprite
 	}
 
-	return &MetaSprite{
+	m := &MetaSprite{
 		engine:  e,
 		offsets: offset,
 		sprites: sprites,
-	}, nil
+	}
+	e.objects.track(m)
+
+	return m, nil
 }
 
 // Set sets the x and y position of the meta sprite
@@ -54,8 +57,7 @@ func (s *MetaSprite) Move(delta math.V2) {
 
 // Show adds the meta sprite's component sprites to the list of active sprites.
 // if the sprites associated assets have not been loaded yet, Show will automatically attempt to load them.
-// all active sprites are drawn every frame, if more than 128 sprites are active at a time all active
-// sprites will be randomly flickered to ensure all sprites continue to be drawn
+// all active sprites are drawn every frame, if 128 sprites are already active an error will be returned
 func (s *MetaSprite) Show() error {
 	for i := range s.sprites {
 		err := s.sprites[i].Show()
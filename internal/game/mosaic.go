@@ -0,0 +1,23 @@
+package game
+
+import (
+	hw_display "github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+// SetMosaic sets the size of the mosaic blocks in pixels for all backgrounds and sprites that have Mosaic set.
+// w and h are clamped between 1 and 16, a size of 1 x 1 has no visible effect
+func (e *Engine) SetMosaic(w, h int) {
+	w = clampInt(w, 1, 16) - 1
+	h = clampInt(h, 1, 16) - 1
+
+	e.mosaic = memmap.MosaicSize(w) |
+		memmap.MosaicSize(h)<<hw_display.MosaicBGVShift |
+		memmap.MosaicSize(w)<<hw_display.MosaicSprHShift |
+		memmap.MosaicSize(h)<<hw_display.MosaicSprVShift
+}
+
+// drawMosaic copies the engines mosaic size into the shadow mosaic register
+func (e *Engine) drawMosaic() {
+	e.regs.mosaic = e.mosaic
+}
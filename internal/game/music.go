@@ -0,0 +1,300 @@
+package game
+
+import (
+	"github.com/bjatkin/flappy_boot/internal/assets"
+	"github.com/bjatkin/flappy_boot/internal/math"
+)
+
+const (
+	// musicChannels is the number of voices reserved for music, songs can have at most this many channels
+	musicChannels = 4
+
+	// amigaClock converts an amiga period into a sample rate, a sample played at period p plays at amigaClock/p Hz
+	amigaClock = 3_546_895
+
+	// tickFrames is the length of a frame in 1/300ths of a second, a song plays tempo*2/5 ticks each second
+	// so adding tempo*2 each frame counts up to a tick every tickFrames
+	tickFrames = 300
+
+	// maxPeriod and minPeriod are the periods of the lowest and highest keys
+	maxPeriod = 1712
+	minPeriod = 28
+)
+
+// Tracker effects supported by the music player, all other effects are ignored
+const (
+	effectArpeggio    = 0x0
+	effectPortaUp     = 0x1
+	effectPortaDown   = 0x2
+	effectTonePorta   = 0x3
+	effectVolumeSlide = 0xA
+	effectJump        = 0xB
+	effectVolume      = 0xC
+	effectBreak       = 0xD
+	effectExtended    = 0xE
+	effectSpeed       = 0xF
+
+	// extendedNoteCut is the extended effect (ECx) that cuts the note after x ticks
+	extendedNoteCut = 0xC
+)
+
+// periods are the amiga periods for each key in octave 0, each octave up halves the period
+var periods = [12]int{1712, 1616, 1525, 1440, 1357, 1281, 1209, 1141, 1077, 1017, 961, 907}
+
+// musicChannel is the state of a single channel of the playing song
+type musicChannel struct {
+	inst   *assets.Instrument
+	key    int
+	period int
+	volume int
+
+	effect uint8
+	param  uint8
+
+	// target and portaSpeed are the period and speed of the last tone portamento
+	target     int
+	portaSpeed int
+}
+
+// musicPlayer steps through the patterns of a song and plays the notes on the music voices
+type musicPlayer struct {
+	song *assets.Song
+
+	pos   int
+	row   int
+	tick  int
+	speed int
+	tempo int
+
+	// ticks counts up towards the next tick in 1/300ths of a second
+	ticks int
+
+	// jump and skip are the position and row the song moves to after the current row, they're -1 if
+	// the song moves to the next row as normal
+	jump int
+	skip int
+
+	channels [musicChannels]musicChannel
+}
+
+// PlayMusic starts playing the song from the beginning on the music voices, the song loops until it's stopped
+func (e *Engine) PlayMusic(song *assets.Song) {
+	e.StopMusic()
+	e.music = musicPlayer{
+		song:  song,
+		speed: song.Speed(),
+		tempo: song.Tempo(),
+		jump:  -1,
+		skip:  -1,
+
+		// start a frame before a tick so the first row plays on the first frame
+		ticks: tickFrames - song.Tempo()*2,
+	}
+}
+
+// StopMusic stops the song that's playing
+func (e *Engine) StopMusic() {
+	e.music = musicPlayer{}
+	for i := range e.voices[maxVoices:] {
+		e.voices[maxVoices+i].Stop()
+	}
+}
+
+// drawMusic advances the song by one frame
+func (e *Engine) drawMusic() {
+	e.music.update(e.voices[maxVoices:])
+}
+
+// update runs all the ticks that happen durring a frame and updates the voices that play each channel
+func (m *musicPlayer) update(voices []Voice) {
+	if m.song == nil {
+		return
+	}
+
+	m.ticks += m.tempo * 2
+	for m.ticks >= tickFrames {
+		m.ticks -= tickFrames
+		m.step(voices)
+	}
+}
+
+// step runs a single tick of the song. New rows are played on the first tick of each row and
+// effects are run on the rest of the ticks
+func (m *musicPlayer) step(voices []Voice) {
+	if m.tick == 0 {
+		m.playRow(voices)
+	} else {
+		for i := range m.channels {
+			m.runEffect(i)
+		}
+	}
+
+	for i := range m.channels {
+		m.channels[i].apply(&voices[i], m.tick)
+	}
+
+	m.tick++
+	if m.tick >= m.speed {
+		m.tick = 0
+		m.nextRow()
+	}
+}
+
+// playRow starts the notes and effects in the current row
+func (m *musicPlayer) playRow(voices []Voice) {
+	channels := m.song.Channels()
+	row := m.song.Pattern(m.pos)[m.row*channels : (m.row+1)*channels]
+	for i, note := range row {
+		if i >= musicChannels {
+			break
+		}
+
+		ch := &m.channels[i]
+		ch.effect, ch.param = note.Effect, note.Param
+
+		if inst := m.song.Instrument(int(note.Instrument)); inst != nil {
+			ch.inst = inst
+			ch.volume = inst.Volume
+		}
+
+		if note.Key > 0 {
+			period := keyPeriod(int(note.Key))
+			if note.Effect == effectTonePorta {
+				// tone portamento slides to the new note instead of starting it
+				ch.target = period
+			} else {
+				ch.key = int(note.Key)
+				ch.period = period
+				if ch.inst != nil {
+					voices[i] = Voice{sound: ch.inst.Sound}
+				}
+			}
+		}
+
+		m.startEffect(i, note.Effect, note.Param)
+	}
+}
+
+// startEffect runs the effects that happen on the first tick of a row
+func (m *musicPlayer) startEffect(channel int, effect, param uint8) {
+	ch := &m.channels[channel]
+	switch effect {
+	case effectTonePorta:
+		if param > 0 {
+			ch.portaSpeed = int(param)
+		}
+	case effectJump:
+		m.jump = int(param)
+	case effectVolume:
+		ch.volume = clampInt(int(param), 0, 64)
+	case effectBreak:
+		// the break row is stored as a decimal number
+		m.skip = int(param>>4)*10 + int(param&0xF)
+		if m.jump < 0 {
+			m.jump = m.pos + 1
+		}
+	case effectExtended:
+		if param>>4 == extendedNoteCut && param&0xF == 0 {
+			ch.volume = 0
+		}
+	case effectSpeed:
+		switch {
+		case param == 0:
+			// a speed of 0 would stop the song so it's ignored
+		case param < 32:
+			m.speed = int(param)
+		default:
+			m.tempo = int(param)
+		}
+	}
+}
+
+// runEffect runs the effects that happen on every tick after the first tick of a row
+func (m *musicPlayer) runEffect(channel int) {
+	ch := &m.channels[channel]
+	switch ch.effect {
+	case effectPortaUp:
+		ch.period = clampInt(ch.period-int(ch.param), minPeriod, maxPeriod)
+	case effectPortaDown:
+		ch.period = clampInt(ch.period+int(ch.param), minPeriod, maxPeriod)
+	case effectTonePorta:
+		if ch.target == 0 {
+			break
+		}
+		if ch.period < ch.target {
+			ch.period = clampInt(ch.period+ch.portaSpeed, ch.period, ch.target)
+		} else {
+			ch.period = clampInt(ch.period-ch.portaSpeed, ch.target, ch.period)
+		}
+	case effectVolumeSlide:
+		if up := int(ch.param >> 4); up > 0 {
+			ch.volume = clampInt(ch.volume+up, 0, 64)
+		} else {
+			ch.volume = clampInt(ch.volume-int(ch.param&0xF), 0, 64)
+		}
+	case effectExtended:
+		if ch.param>>4 == extendedNoteCut && int(ch.param&0xF) == m.tick {
+			ch.volume = 0
+		}
+	}
+}
+
+// nextRow moves the song to the next row, following any jumps or breaks in the row that was just played.
+// Once the song reaches the end of it's order it returns to the restart position
+func (m *musicPlayer) nextRow() {
+	m.row++
+	if m.jump < 0 && m.row < len(m.song.Pattern(m.pos))/m.song.Channels() {
+		return
+	}
+
+	pos, row := m.pos+1, 0
+	if m.jump >= 0 {
+		pos = m.jump
+	}
+	if m.skip >= 0 {
+		row = m.skip
+	}
+	m.jump, m.skip = -1, -1
+
+	if pos >= m.song.Length() {
+		pos = m.song.Restart()
+	}
+	if row >= len(m.song.Pattern(pos))/m.song.Channels() {
+		row = 0
+	}
+
+	m.pos, m.row = pos, row
+}
+
+// apply sets the volume and rate of the voice that plays the channel
+func (ch *musicChannel) apply(voice *Voice, tick int) {
+	if ch.inst == nil || ch.period == 0 {
+		return
+	}
+
+	period := ch.period
+	if ch.effect == effectArpeggio && ch.param > 0 {
+		// arpeggio cycles between the note and the 2 keys above it set by the effect
+		switch tick % 3 {
+		case 1:
+			period = keyPeriod(ch.key + int(ch.param>>4))
+		case 2:
+			period = keyPeriod(ch.key + int(ch.param&0xF))
+		}
+	}
+
+	voice.Volume = math.Fix8(ch.volume * int(math.FixOne) / 64)
+	voice.Rate = periodRate(period, ch.inst.Tune)
+}
+
+// keyPeriod returns the amiga period of the key, keys start with C-0 at 1
+func keyPeriod(key int) int {
+	key = clampInt(key, 1, len(periods)*6)
+	return periods[(key-1)%12] >> ((key - 1) / 12)
+}
+
+// periodRate converts an amiga period into a voice playback rate. tune is the instruments tune
+// where 256 is the instruments normal pitch
+func periodRate(period, tune int) math.Fix8 {
+	return math.Fix8(amigaClock / period * tune / SampleRate)
+}
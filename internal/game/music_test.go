@@ -0,0 +1,258 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/assets"
+	"github.com/bjatkin/flappy_boot/internal/math"
+)
+
+// testSong creates a single channel song with one instrument from the rows of each pattern
+func testSong(speed, tempo, restart int, order []uint8, patterns ...[]assets.Note) *assets.Song {
+	inst := []assets.Instrument{
+		{Sound: assets.NewSound(make([]int8, 16), 0), Volume: 32, Tune: 256},
+	}
+
+	return assets.NewSong(1, speed, tempo, restart, order, patterns, inst)
+}
+
+func TestEngine_drawMusic(t *testing.T) {
+	note := assets.Note{Key: 25, Instrument: 1}
+	tests := []struct {
+		name   string
+		song   *assets.Song
+		frames int
+		pos    int
+		row    int
+		tick   int
+	}{
+		{
+			"first row",
+			testSong(6, 150, 0, []uint8{0}, []assets.Note{note, {}, {}, {}}),
+			1,
+			0, 0, 1,
+		},
+		{
+			"next row",
+			testSong(6, 150, 0, []uint8{0}, []assets.Note{note, {}, {}, {}}),
+			6,
+			0, 1, 0,
+		},
+		{
+			"next pattern",
+			testSong(2, 150, 0, []uint8{0, 1}, []assets.Note{note, {}}, []assets.Note{note, {}}),
+			4,
+			1, 0, 0,
+		},
+		{
+			"restart",
+			testSong(2, 150, 1, []uint8{0, 1}, []assets.Note{note, {}}, []assets.Note{note, {}}),
+			8,
+			1, 0, 0,
+		},
+		{
+			"slow tempo",
+			testSong(2, 75, 0, []uint8{0}, []assets.Note{note, {}, {}, {}}),
+			4,
+			0, 1, 0,
+		},
+		{
+			"speed effect",
+			testSong(6, 150, 0, []uint8{0}, []assets.Note{{Key: 25, Instrument: 1, Effect: effectSpeed, Param: 2}, {}, {}, {}}),
+			4,
+			0, 2, 0,
+		},
+		{
+			"tempo effect",
+			testSong(2, 150, 0, []uint8{0}, []assets.Note{{Key: 25, Instrument: 1, Effect: effectSpeed, Param: 75}, {}, {}, {}}),
+			3,
+			0, 1, 0,
+		},
+		{
+			"jump",
+			testSong(1, 150, 0, []uint8{0, 1, 2}, []assets.Note{{Effect: effectJump, Param: 2}, {}}, []assets.Note{{}, {}}, []assets.Note{{}, {}}),
+			1,
+			2, 0, 0,
+		},
+		{
+			"break",
+			testSong(1, 150, 0, []uint8{0, 1}, []assets.Note{{Effect: effectBreak, Param: 0x12}, {}}, make([]assets.Note, 16)),
+			1,
+			1, 12, 0,
+		},
+		{
+			"break past the end of the pattern",
+			testSong(1, 150, 0, []uint8{0, 1}, []assets.Note{{Effect: effectBreak, Param: 0x20}, {}}, []assets.Note{{}, {}}),
+			1,
+			1, 0, 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Engine{}
+			e.PlayMusic(tt.song)
+			for i := 0; i < tt.frames; i++ {
+				e.drawMusic()
+			}
+
+			if e.music.pos != tt.pos || e.music.row != tt.row || e.music.tick != tt.tick {
+				t.Errorf("Engine.drawMusic() pos, row, tick = %d, %d, %d, want %d, %d, %d",
+					e.music.pos, e.music.row, e.music.tick, tt.pos, tt.row, tt.tick)
+			}
+		})
+	}
+}
+
+func TestEngine_drawMusic_voice(t *testing.T) {
+	tests := []struct {
+		name   string
+		notes  []assets.Note
+		frames int
+		volume math.Fix8
+		rate   math.Fix8
+	}{
+		{
+			"instrument volume",
+			[]assets.Note{{Key: 25, Instrument: 1}},
+			1,
+			math.FixOne / 2,
+			periodRate(428, 256),
+		},
+		{
+			"volume effect",
+			[]assets.Note{{Key: 25, Instrument: 1, Effect: effectVolume, Param: 64}},
+			1,
+			math.FixOne,
+			periodRate(428, 256),
+		},
+		{
+			"volume slide down",
+			[]assets.Note{{Key: 25, Instrument: 1, Effect: effectVolumeSlide, Param: 0x04}},
+			5,
+			math.FixOne / 4,
+			periodRate(428, 256),
+		},
+		{
+			"porta up",
+			[]assets.Note{{Key: 25, Instrument: 1, Effect: effectPortaUp, Param: 8}},
+			2,
+			math.FixOne / 2,
+			periodRate(420, 256),
+		},
+		{
+			"tone porta",
+			[]assets.Note{{Key: 25, Instrument: 1}, {Key: 26, Effect: effectTonePorta, Param: 20}},
+			11,
+			math.FixOne / 2,
+			periodRate(404, 256),
+		},
+		{
+			"arpeggio",
+			[]assets.Note{{Key: 25, Instrument: 1, Effect: effectArpeggio, Param: 0x47}},
+			2,
+			math.FixOne / 2,
+			periodRate(340, 256),
+		},
+		{
+			"note cut",
+			[]assets.Note{{Key: 25, Instrument: 1, Effect: effectExtended, Param: 0xC2}},
+			3,
+			0,
+			periodRate(428, 256),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern := make([]assets.Note, 4)
+			copy(pattern, tt.notes)
+
+			e := &Engine{}
+			e.PlayMusic(testSong(8, 150, 0, []uint8{0}, pattern))
+			for i := 0; i < tt.frames; i++ {
+				e.drawMusic()
+			}
+
+			voice := e.voices[maxVoices]
+			if !voice.Playing() {
+				t.Fatalf("Engine.drawMusic() voice is not playing")
+			}
+			if voice.Volume != tt.volume || voice.Rate != tt.rate {
+				t.Errorf("Engine.drawMusic() volume, rate = %v, %v, want %v, %v", voice.Volume, voice.Rate, tt.volume, tt.rate)
+			}
+		})
+	}
+}
+
+func TestEngine_StopMusic(t *testing.T) {
+	e := &Engine{}
+	e.PlayMusic(testSong(6, 125, 0, []uint8{0}, []assets.Note{{Key: 25, Instrument: 1}}))
+	e.drawMusic()
+	e.StopMusic()
+
+	if e.voices[maxVoices].Playing() {
+		t.Errorf("Engine.StopMusic() voice is still playing")
+	}
+
+	e.drawMusic()
+	if e.voices[maxVoices].Playing() {
+		t.Errorf("Engine.drawMusic() started a note after the music was stopped")
+	}
+}
+
+func TestEngine_PlaySound_music(t *testing.T) {
+	e := &Engine{}
+	e.PlayMusic(testSong(6, 125, 0, []uint8{0}, []assets.Note{{Key: 25, Instrument: 1}}))
+	e.drawMusic()
+
+	sound := assets.NewSound(make([]int8, 16), -1)
+	for i := 0; i < maxVoices*2; i++ {
+		e.PlaySound(sound)
+	}
+
+	if e.voices[maxVoices].sound == sound {
+		t.Errorf("Engine.PlaySound() used a music voice")
+	}
+}
+
+func Test_keyPeriod(t *testing.T) {
+	tests := []struct {
+		name string
+		key  int
+		want int
+	}{
+		{"C-0", 1, 1712},
+		{"B-0", 12, 907},
+		{"C-2", 25, 428},
+		{"A-3", 46, 127},
+		{"B-5", 72, 28},
+		{"too high", 80, 28},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keyPeriod(tt.key); got != tt.want {
+				t.Errorf("keyPeriod() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_periodRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		period int
+		tune   int
+		want   math.Fix8
+	}{
+		{"C-2", 428, 256, 116},
+		{"C-3", 214, 256, 233},
+		{"octave up", 428, 512, 233},
+		{"sample rate", amigaClock / SampleRate, 256, math.FixOne},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := periodRate(tt.period, tt.tune); got != tt.want {
+				t.Errorf("periodRate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package game
+
+import (
+	"github.com/bjatkin/flappy_boot/internal/assets"
+	hw_display "github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/dma"
+	hw_sprite "github.com/bjatkin/flappy_boot/internal/hardware/sprite"
+	"github.com/bjatkin/flappy_boot/internal/math"
+)
+
+// ProfileSection is a part of the frame that's timed by the profiler
+type ProfileSection int
+
+const (
+	// ProfileUpdate is the time spent polling keys and running the Runables Update
+	ProfileUpdate ProfileSection = iota
+
+	// ProfileDraw is the time spent in Draw, not including the palette and sprites
+	ProfileDraw
+
+	// ProfilePalette is the time spent updating the shadow palette, this includes software palette fades
+	ProfilePalette
+
+	// ProfileSprites is the time spent drawing sprites into the OAM buffer and copying the buffer into OAM
+	ProfileSprites
+
+	// ProfileFlush is the time spent flushing the shadow state in VBlank, not including the sprites
+	ProfileFlush
+
+	// profileSections is the number of profile sections
+	profileSections
+)
+
+const (
+	// FrameCycles is the number of cpu cycles in a single frame, the frame budget
+	FrameCycles = dma.ScreenRefresh
+
+	// profileBudget is the width of the frame budget in the profiler bar, the bar is drawn past the
+	// budget marker once a frame takes longer than FrameCycles
+	profileBudget = 160
+
+	// profileSprites is the number of sprites in the profiler bar. Each section ends with a partial
+	// tile so a full width bar needs one sprite for each tile, one for each section and the marker
+	profileSprites = hw_display.Width/8 + int(profileSections) + 1
+
+	// profileMarker is the tile of the frame budget marker, it's the first tile after the bar segments
+	profileMarker = int(profileSections) * 8
+)
+
+// profileTileIndex returns the tile in the profile tile set for a section and width. The tile set is
+// ordered so each section is a row of 8 tiles and the nth tile of each row fills n+1 columns
+func profileTileIndex(section ProfileSection, width int) int {
+	return int(section)*8 + width - 1
+}
+
+// Profile is the number of cpu cycles spent in each section of a frame
+type Profile [profileSections]int
+
+// Total returns the total number of cycles in the profile
+func (p Profile) Total() int {
+	var total int
+	for _, cycles := range p {
+		total += cycles
+	}
+
+	return total
+}
+
+// profiler times each section of the frame. Sections can be nested, the time spent in a nested
+// section is not counted towards the section it's nested in
+type profiler struct {
+	stack [4]ProfileSection
+	depth int
+	start uint32
+
+	// cycles are the cycles counted so far this frame and last is the last complete frame
+	cycles Profile
+	last   Profile
+
+	show    bool
+	sprites [profileSprites]*Sprite
+}
+
+// profileTile is a single sprite in the profiler bar
+type profileTile struct {
+	x    int
+	tile int
+}
+
+// Profile returns the number of cpu cycles spent in each section of the last frame.
+// Standalone builds estimate the cycles from the time spent on the host cpu
+func (e *Engine) Profile() Profile {
+	return e.profiler.last
+}
+
+// ShowProfiler shows or hides the profiler bar at the bottom of the screen. Each section of the frame is drawn
+// in a different color, Update is green, Draw is blue, Palette is orange, Sprites are purple and Flush is yellow.
+// The white marker is the frame budget
+func (e *Engine) ShowProfiler(show bool) error {
+	e.profiler.show = show
+	for _, s := range e.profiler.sprites {
+		if !show {
+			s.Hide()
+		}
+	}
+
+	if show {
+		return e.profiler.sprites[0].Load()
+	}
+
+	return nil
+}
+
+// initProfiler creates the profiler bar sprites. They're created with the engine rather than when the profiler is
+// first shown so every engine creates the same sprites in the same order, save states rely on this
+func (e *Engine) initProfiler() {
+	for i := range e.profiler.sprites {
+		s := e.NewSprite(assets.ProfileTileSet)
+		s.Priority = hw_sprite.Priority0
+		e.profiler.sprites[i] = s
+	}
+}
+
+// begin starts timing the section, the section that's currently being timed is paused until end is called
+func (p *profiler) begin(section ProfileSection, now uint32) {
+	if p.depth > 0 {
+		p.cycles[p.stack[p.depth-1]] += int(now - p.start)
+	}
+
+	if p.depth < len(p.stack) {
+		p.stack[p.depth] = section
+		p.depth++
+	}
+	p.start = now
+}
+
+// end stops timing the current section and resumes timing the section it was nested in
+func (p *profiler) end(now uint32) {
+	if p.depth == 0 {
+		return
+	}
+
+	p.depth--
+	p.cycles[p.stack[p.depth]] += int(now - p.start)
+	p.start = now
+}
+
+// finish ends the frame, the frames cycles can be read from last until the next frame finishes
+func (p *profiler) finish() {
+	p.last = p.cycles
+	p.cycles = Profile{}
+}
+
+// drawProfiler moves the profiler bar sprites to show the last frames profile
+func (e *Engine) drawProfiler() {
+	if !e.profiler.show {
+		return
+	}
+
+	var bar [profileSprites]profileTile
+	n := profileBar(e.profiler.last, &bar)
+	for i, s := range e.profiler.sprites {
+		if i >= n {
+			s.Hide()
+			continue
+		}
+
+		s.Pos = math.V2{X: math.NewFix8(bar[i].x, 0), Y: math.NewFix8(hw_display.Height-8, 0)}
+		s.TileIndex = bar[i].tile
+
+		// the bar is only for debugging so it's left incomplete if there are no free sprites
+		_ = s.Show()
+	}
+}
+
+// profileBar lays out the tiles of the profiler bar for the profile and returns the number of tiles
+func profileBar(p Profile, bar *[profileSprites]profileTile) int {
+	bar[0] = profileTile{x: profileBudget, tile: profileMarker}
+	n := 1
+
+	var x int
+	for section, cycles := range p {
+		w := cycles * profileBudget / FrameCycles
+		if x+w > hw_display.Width {
+			w = hw_display.Width - x
+		}
+
+		for ; w > 0; w -= 8 {
+			tw := w
+			if tw > 8 {
+				tw = 8
+			}
+
+			bar[n] = profileTile{x: x, tile: profileTileIndex(ProfileSection(section), tw)}
+			n++
+			x += tw
+		}
+	}
+
+	return n
+}
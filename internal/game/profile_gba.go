@@ -0,0 +1,32 @@
+//go:build !standalone
+
+package game
+
+import (
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	"github.com/bjatkin/flappy_boot/internal/hardware/timer"
+)
+
+// initClock starts timer 2 ticking once per cycle and cascades timer 3 off of it,
+// together they count cpu cycles as a single 32 bit counter
+func initClock() {
+	memmap.SetReg(timer.Controll2, 0)
+	memmap.SetReg(timer.Controll3, 0)
+	memmap.SetReg(timer.Counter2, 0)
+	memmap.SetReg(timer.Counter3, 0)
+
+	memmap.SetReg(timer.Controll3, timer.CountUpEnable|timer.TimerStart)
+	memmap.SetReg(timer.Controll2, timer.Freq1|timer.TimerStart)
+}
+
+// clock returns the number of cpu cycles counted by the cascaded timers, it wraps every 2^32 cycles
+func clock() uint32 {
+	for {
+		// timer 2 can overflow between the reads so timer 3 is read twice to make sure the values match
+		high := memmap.GetReg(timer.Counter3)
+		low := memmap.GetReg(timer.Counter2)
+		if memmap.GetReg(timer.Counter3) == high {
+			return uint32(high)<<16 | uint32(low)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+//go:build standalone
+
+package game
+
+import (
+	"time"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/dma"
+)
+
+// clockStart is the wall clock time the clock counts from
+var clockStart = time.Now()
+
+// initClock does nothing, the emulated timers never tick so the standalone clock uses the wall clock instead
+func initClock() {}
+
+// clock returns the time since the clock started in cpu cycles, it wraps every 2^32 cycles.
+// The time is measured on the host cpu so it's much shorter than the same code would take on a GBA
+func clock() uint32 {
+	cycles := float64(time.Since(clockStart)) * dma.SystemClock / float64(time.Second)
+	return uint32(uint64(cycles))
+}
@@ -0,0 +1,84 @@
+package game
+
+import "testing"
+
+func Test_profiler(t *testing.T) {
+	p := profiler{}
+	p.begin(ProfileDraw, 100)
+	p.begin(ProfilePalette, 110)
+	p.end(140)
+	p.begin(ProfileSprites, 150)
+	p.end(155)
+	p.end(160)
+
+	// the clock wraps so sections that cross the wrap are still timed correctly
+	p.begin(ProfileFlush, 0xFFFF_FFF0)
+	p.end(0x10)
+	p.finish()
+
+	want := Profile{ProfileDraw: 25, ProfilePalette: 30, ProfileSprites: 5, ProfileFlush: 0x20}
+	if p.last != want {
+		t.Errorf("profiler.last = %v, want %v", p.last, want)
+	}
+	if p.cycles != (Profile{}) {
+		t.Errorf("profiler.cycles = %v, want an empty profile", p.cycles)
+	}
+	if p.last.Total() != 60+0x20 {
+		t.Errorf("Profile.Total() = %v, want %v", p.last.Total(), 60+0x20)
+	}
+}
+
+// profilePixels returns the smallest number of cycles that fill w pixels of the profiler bar
+func profilePixels(w int) int {
+	return (w*FrameCycles + profileBudget - 1) / profileBudget
+}
+
+func Test_profileBar(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		want    []profileTile
+	}{
+		{
+			"empty",
+			Profile{},
+			[]profileTile{{profileBudget, profileMarker}},
+		},
+		{
+			"partial tiles",
+			Profile{ProfileUpdate: profilePixels(10), ProfileDraw: profilePixels(3)},
+			[]profileTile{{profileBudget, profileMarker}, {0, profileTileIndex(ProfileUpdate, 8)}, {8, profileTileIndex(ProfileUpdate, 2)}, {10, profileTileIndex(ProfileDraw, 3)}},
+		},
+		{
+			"sections",
+			Profile{ProfilePalette: profilePixels(4), ProfileFlush: profilePixels(8)},
+			[]profileTile{{profileBudget, profileMarker}, {0, profileTileIndex(ProfilePalette, 4)}, {4, profileTileIndex(ProfileFlush, 8)}},
+		},
+		{
+			"over budget",
+			Profile{ProfileDraw: FrameCycles * 2},
+			func() []profileTile {
+				tiles := []profileTile{{profileBudget, profileMarker}}
+				for x := 0; x < 240; x += 8 {
+					tiles = append(tiles, profileTile{x, profileTileIndex(ProfileDraw, 8)})
+				}
+				return tiles
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bar [profileSprites]profileTile
+			n := profileBar(tt.profile, &bar)
+			if n != len(tt.want) {
+				t.Fatalf("profileBar() = %d, want %d", n, len(tt.want))
+			}
+
+			for i := range tt.want {
+				if bar[i] != tt.want[i] {
+					t.Errorf("profileBar() tile %d = %v, want %v", i, bar[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
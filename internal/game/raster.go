@@ -0,0 +1,216 @@
+package game
+
+import (
+	"unsafe"
+
+	"github.com/bjatkin/flappy_boot/internal/alloc"
+	hw_display "github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/dma"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+// maxRasters is the maximum number of raster effects that can be shown at once.
+// every active raster is updated durring each HBlank so this is kept small
+const maxRasters = 4
+
+// rasterKind is the register a raster effect updates
+type rasterKind int
+
+const (
+	rasterHScroll rasterKind = iota
+	rasterVScroll
+	rasterPalette
+)
+
+// Raster is a raster effect, it changes a register at the start of every scan line using the HBlank interrupt.
+// This can be used for effects like wavy backgrounds, split screen scrolling or color gradients
+type Raster struct {
+	// engine is a reference to the rasters parent engine
+	engine *Engine
+	kind   rasterKind
+	bg     *Background
+	index  int
+
+	// target is the register the raster writes to, it's nil if the rasters background is not being shown
+	target *uint16
+
+	// table is the final register value for each line, it's read by the HBlank interrupt
+	table [hw_display.Height]uint16
+
+	// nextTarget and next are the target and table for the next frame, they're swapped in when the engine flushes
+	// so the table is never changed while the screen is being drawn
+	nextTarget *uint16
+	next       [hw_display.Height]uint16
+
+	// Lines are the values for each scan line. For scroll rasters they're the offset in pixels that's added to the
+	// backgrounds scroll for that line. For palette rasters they're the color for that line as a memmap.PaletteValue
+	Lines [hw_display.Height]int
+}
+
+// NewHScrollRaster returns a new Raster that changes the horizontal scroll of the background on each scan line
+func (e *Engine) NewHScrollRaster(bg *Background) *Raster {
+	r := &Raster{
+		engine: e,
+		kind:   rasterHScroll,
+		bg:     bg,
+	}
+	e.objects.track(r)
+
+	return r
+}
+
+// NewVScrollRaster returns a new Raster that changes the vertical scroll of the background on each scan line
+func (e *Engine) NewVScrollRaster(bg *Background) *Raster {
+	r := &Raster{
+		engine: e,
+		kind:   rasterVScroll,
+		bg:     bg,
+	}
+	e.objects.track(r)
+
+	return r
+}
+
+// NewPaletteRaster returns a new Raster that changes a color in the background palette on each scan line.
+// index 0 is the backdrop color
+func (e *Engine) NewPaletteRaster(index int) *Raster {
+	r := &Raster{
+		engine: e,
+		kind:   rasterPalette,
+		index:  index,
+	}
+	e.objects.track(r)
+
+	return r
+}
+
+// Show adds the raster to the list of active rasters. If the maximum number of rasters are
+// already active an error will be returned
+func (r *Raster) Show() error {
+	return r.engine.addRaster(r)
+}
+
+// Hide removes the raster from the list of active rasters
+func (r *Raster) Hide() {
+	r.engine.removeRaster(r)
+}
+
+// update sets the rasters next target register and fills in the next register value for each line
+func (r *Raster) update() {
+	r.nextTarget = nil
+
+	var base int
+	switch r.kind {
+	case rasterHScroll, rasterVScroll:
+		offsets := [4][2]*uint16{
+			{hw_display.BG0HOffset, hw_display.BG0VOffset},
+			{hw_display.BG1HOffset, hw_display.BG1VOffset},
+			{hw_display.BG2HOffset, hw_display.BG2VOffset},
+			{hw_display.BG3HOffset, hw_display.BG3VOffset},
+		}
+
+		for i := range r.engine.activeBackgrounds {
+			if r.engine.activeBackgrounds[i] != r.bg {
+				continue
+			}
+
+			if r.kind == rasterHScroll {
+				r.nextTarget = offsets[i][0]
+				base = int(r.bg.HScroll.Uint16())
+			} else {
+				r.nextTarget = offsets[i][1]
+				base = int(r.bg.VScroll.Uint16())
+			}
+		}
+	case rasterPalette:
+		r.nextTarget = (*uint16)(unsafe.Pointer(&memmap.Palette[r.index]))
+	}
+
+	for i, v := range r.Lines {
+		r.next[i] = uint16(base + v)
+	}
+}
+
+// addRaster adds a new raster to the list of active rasters
+func (e *Engine) addRaster(r *Raster) error {
+	for i := range e.activeRasters {
+		if e.activeRasters[i] == r {
+			return nil
+		}
+	}
+
+	for i := range e.activeRasters {
+		if e.activeRasters[i] == nil {
+			e.activeRasters[i] = r
+			return nil
+		}
+	}
+
+	return alloc.ErrOOM
+}
+
+// removeRaster removes a raster from the list of active rasters
+func (e *Engine) removeRaster(r *Raster) {
+	for i := range e.activeRasters {
+		if e.activeRasters[i] == r {
+			e.activeRasters[i] = nil
+		}
+	}
+
+	if r.kind == rasterPalette {
+		// restore the original color on the next draw
+		e.doFade = true
+	}
+}
+
+// drawRasters updates the tables for all the active rasters
+func (e *Engine) drawRasters() {
+	for _, r := range e.activeRasters {
+		if r == nil {
+			continue
+		}
+
+		r.update()
+	}
+}
+
+// flushRasters swaps in the next table for each active raster and sets the registers for the first scan line.
+// The HBlank interrupt is only enabled while there are active rasters
+func (e *Engine) flushRasters() {
+	stat := memmap.GetReg(hw_display.Stat) &^ hw_display.HBlankIRQ
+	for _, r := range e.activeRasters {
+		if r == nil {
+			continue
+		}
+
+		r.target = r.nextTarget
+		dma.Copy32(r.table[:], r.next[:])
+		if r.target != nil {
+			memmap.SetReg(r.target, r.table[0])
+		}
+		stat |= hw_display.HBlankIRQ
+	}
+
+	memmap.SetReg(hw_display.Stat, stat)
+}
+
+// stopRasters turns off the HBlank interrupt so the active rasters stop writing to the registers
+func (e *Engine) stopRasters() {
+	memmap.SetReg(hw_display.Stat, memmap.GetReg(hw_display.Stat)&^hw_display.HBlankIRQ)
+}
+
+// hBlank sets the registers for the next scan line, it's called by the HBlank interrupt
+func (e *Engine) hBlank() {
+	line := int(memmap.GetReg(hw_display.VCount)) + 1
+	if line >= hw_display.Height {
+		return
+	}
+
+	for _, r := range e.activeRasters {
+		if r == nil || r.target == nil {
+			continue
+		}
+
+		memmap.SetReg(r.target, r.table[line])
+	}
+}
@@ -0,0 +1,123 @@
+//go:build standalone
+
+package game
+
+import (
+	"testing"
+
+	hw_display "github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	"github.com/bjatkin/flappy_boot/internal/math"
+)
+
+func TestEngine_hBlank(t *testing.T) {
+	tests := []struct {
+		name   string
+		raster func(e *Engine, bg *Background) *Raster
+		line   int
+		reg    *uint16
+		want   uint16
+	}{
+		{
+			"first line is set by flush",
+			func(e *Engine, bg *Background) *Raster {
+				r := e.NewHScrollRaster(bg)
+				r.Lines[0] = 2
+				return r
+			},
+			-1,
+			hw_display.BG1HOffset,
+			12,
+		},
+		{
+			"horizontal scroll",
+			func(e *Engine, bg *Background) *Raster {
+				r := e.NewHScrollRaster(bg)
+				r.Lines[5] = 3
+				return r
+			},
+			4,
+			hw_display.BG1HOffset,
+			13,
+		},
+		{
+			"negative vertical scroll",
+			func(e *Engine, bg *Background) *Raster {
+				r := e.NewVScrollRaster(bg)
+				r.Lines[80] = -25
+				return r
+			},
+			79,
+			hw_display.BG1VOffset,
+			0xFFFB,
+		},
+		{
+			"palette color",
+			func(e *Engine, bg *Background) *Raster {
+				r := e.NewPaletteRaster(3)
+				r.Lines[20] = int(White)
+				return r
+			},
+			19,
+			(*uint16)(&memmap.Palette[3]),
+			uint16(White),
+		},
+		{
+			"last line is ignored",
+			func(e *Engine, bg *Background) *Raster {
+				r := e.NewHScrollRaster(bg)
+				r.Lines[0] = 2
+				return r
+			},
+			hw_display.Height - 1,
+			hw_display.BG1HOffset,
+			12,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Engine{}
+			bg := &Background{engine: e, HScroll: math.FixOne * 10, VScroll: math.FixOne * 20}
+			e.activeBackgrounds[1] = bg
+
+			err := tt.raster(e, bg).Show()
+			if err != nil {
+				t.Fatalf("Raster.Show() error = %v", err)
+			}
+
+			e.drawRasters()
+			e.flushRasters()
+			if memmap.GetReg(hw_display.Stat)&hw_display.HBlankIRQ == 0 {
+				t.Errorf("flushRasters() did not enable the HBlank interrupt")
+			}
+
+			if tt.line >= 0 {
+				memmap.SetReg(hw_display.VCount, memmap.DisplayVCount(tt.line))
+				e.hBlank()
+			}
+
+			if got := memmap.GetReg(tt.reg); got != tt.want {
+				t.Errorf("Engine.hBlank() register = %#04x, want %#04x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRaster_Show(t *testing.T) {
+	e := &Engine{}
+	for i := 0; i < maxRasters; i++ {
+		if err := e.NewPaletteRaster(i).Show(); err != nil {
+			t.Fatalf("Raster.Show() error = %v", err)
+		}
+	}
+
+	r := e.NewPaletteRaster(maxRasters)
+	if err := r.Show(); err == nil {
+		t.Errorf("Raster.Show() error = nil, want an error when all the rasters are active")
+	}
+
+	e.activeRasters[0].Hide()
+	if err := r.Show(); err != nil {
+		t.Errorf("Raster.Show() error = %v after a raster was hidden", err)
+	}
+}
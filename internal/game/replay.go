@@ -0,0 +1,161 @@
+package game
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+const (
+	// replayMagic is the first 4 bytes of every replay file
+	replayMagic = "FBRP"
+
+	// replayVersion is the version of the replay file format
+	replayVersion = 1
+
+	// replayHeader is the length of the replay file header. The header is the magic, the version,
+	// the seed and the number of frames
+	replayHeader = 4 + 1 + 8 + 4
+
+	// maxReplayFrames is the longest replay that can be decoded, 24 hours of frames. The frame count is
+	// checked before the inputs are allocated so a bad header can't allocate gigabytes of memory
+	maxReplayFrames = 24 * 60 * 60 * 60
+)
+
+// ErrInvalidReplay is returned when a replay file can not be decoded
+var ErrInvalidReplay = errors.New("invalid replay file")
+
+// Replay is a recording of the key input register for every frame of a run. Along with the seed
+// it's enough to play the run back exactly
+type Replay struct {
+	// Seed is the seed that was used for the runs random numbers
+	Seed int64
+
+	// Inputs is the value of the key input register for each frame of the run
+	Inputs []memmap.Input
+}
+
+// MarshalBinary encodes the replay into the replay file format. The inputs are run length encoded since
+// the buttons are held down or released for many frames at a time.
+// Each run is the 16 bit input value followed by the number of frames as a uvarint, all values are little endian
+func (r *Replay) MarshalBinary() ([]byte, error) {
+	if len(r.Inputs) > maxReplayFrames {
+		return nil, fmt.Errorf("replay has %d frames, the most a replay can have is %d", len(r.Inputs), maxReplayFrames)
+	}
+
+	data := make([]byte, replayHeader, replayHeader+len(r.Inputs)/8)
+	copy(data, replayMagic)
+	data[4] = replayVersion
+	binary.LittleEndian.PutUint64(data[5:], uint64(r.Seed))
+	binary.LittleEndian.PutUint32(data[13:], uint32(len(r.Inputs)))
+
+	for i := 0; i < len(r.Inputs); {
+		run := 1
+		for i+run < len(r.Inputs) && r.Inputs[i+run] == r.Inputs[i] {
+			run++
+		}
+
+		data = binary.LittleEndian.AppendUint16(data, uint16(r.Inputs[i]))
+		data = binary.AppendUvarint(data, uint64(run))
+		i += run
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes a replay file, ErrInvalidReplay is returned if the data is not a valid replay file
+func (r *Replay) UnmarshalBinary(data []byte) error {
+	if len(data) < replayHeader || string(data[:4]) != replayMagic || data[4] != replayVersion {
+		return ErrInvalidReplay
+	}
+
+	seed := int64(binary.LittleEndian.Uint64(data[5:]))
+	frames := int(binary.LittleEndian.Uint32(data[13:]))
+	if frames > maxReplayFrames {
+		return ErrInvalidReplay
+	}
+
+	inputs := make([]memmap.Input, 0, frames)
+	data = data[replayHeader:]
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return ErrInvalidReplay
+		}
+		input := memmap.Input(binary.LittleEndian.Uint16(data))
+
+		run, n := binary.Uvarint(data[2:])
+		if n <= 0 || run == 0 || run > uint64(frames-len(inputs)) {
+			return ErrInvalidReplay
+		}
+		data = data[2+n:]
+
+		for i := 0; i < int(run); i++ {
+			inputs = append(inputs, input)
+		}
+	}
+
+	if len(inputs) != frames {
+		return ErrInvalidReplay
+	}
+
+	r.Seed = seed
+	r.Inputs = inputs
+	return nil
+}
+
+// SetSeed sets the seed for the games random numbers. It must be set before the game is created
+func (e *Engine) SetSeed(seed int64) {
+	e.seed = seed
+	e.seeded = true
+}
+
+// Seed returns the seed for the games random numbers, false is returned if no seed has been set
+func (e *Engine) Seed() (int64, bool) {
+	return e.seed, e.seeded
+}
+
+// Record starts recording the key input register on every frame. If no seed has been set a seed is
+// set from the current frame so the recording can always be played back
+func (e *Engine) Record() {
+	if !e.seeded {
+		e.SetSeed(int64(e.frame))
+	}
+
+	e.recording = &Replay{Seed: e.seed}
+}
+
+// Recording returns everything that has been recorded since Record was called, nil is returned if
+// nothing is being recorded
+func (e *Engine) Recording() *Replay {
+	return e.recording
+}
+
+// Play plays back the replay, the key input register is ignored until the replay ends. The replay sets
+// the seed so it must be played before the game is created
+func (e *Engine) Play(replay *Replay) {
+	e.SetSeed(replay.Seed)
+	e.replay = replay
+	e.replayFrame = 0
+}
+
+// Playing returns true if a replay is being played
+func (e *Engine) Playing() bool {
+	return e.replay != nil && e.replayFrame < len(e.replay.Inputs)
+}
+
+// replayKeys returns the keys for the current frame. If a replay is playing its keys replace the key input
+// register and if the keys are being recorded they're added to the recording
+func (e *Engine) replayKeys(keys memmap.Input) memmap.Input {
+	if e.Playing() {
+		keys = e.replay.Inputs[e.replayFrame]
+		e.replayFrame++
+	}
+
+	if e.recording != nil {
+		e.recording.Inputs = append(e.recording.Inputs, keys)
+	}
+
+	return keys
+}
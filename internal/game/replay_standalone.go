@@ -0,0 +1,32 @@
+//go:build standalone
+
+package game
+
+import (
+	"os"
+)
+
+// ReadReplay reads the replay file at path
+func ReadReplay(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := &Replay{}
+	if err := replay.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+
+	return replay, nil
+}
+
+// WriteReplay writes the replay to a replay file at path
+func WriteReplay(path string, replay *Replay) error {
+	data, err := replay.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o0664)
+}
@@ -0,0 +1,63 @@
+//go:build standalone
+
+package game
+
+import (
+	"testing"
+
+	hw_key "github.com/bjatkin/flappy_boot/internal/hardware/key"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	"github.com/bjatkin/flappy_boot/internal/key"
+	"github.com/go-test/deep"
+)
+
+func TestEngine_keyPoll_replay(t *testing.T) {
+	defer memmap.SetReg(hw_key.Input, 0xFFFF)
+
+	record := &Engine{}
+	record.SetSeed(42)
+	record.Record()
+
+	keys := []memmap.Input{0xFFFF, 0xFFFF &^ hw_key.AMask, 0xFFFF &^ hw_key.AMask, 0xFFFF}
+	for _, k := range keys {
+		memmap.SetReg(hw_key.Input, k)
+		record.keyPoll()
+	}
+
+	want := &Replay{Seed: 42, Inputs: keys}
+	if diff := deep.Equal(record.Recording(), want); diff != nil {
+		t.Fatalf("Engine.Recording() = %v", diff)
+	}
+
+	// the replay replaces the key input register until it ends
+	play := &Engine{}
+	play.Play(record.Recording())
+	if seed, ok := play.Seed(); !ok || seed != 42 {
+		t.Errorf("Engine.Seed() = %v, %v, want 42, true", seed, ok)
+	}
+
+	memmap.SetReg(hw_key.Input, 0xFFFF&^hw_key.StartMask)
+	var justPressed []int
+	for frame := range keys {
+		if !play.Playing() {
+			t.Fatalf("Engine.Playing() = false on frame %d", frame)
+		}
+
+		play.keyPoll()
+		if play.KeyJustPressed(key.A) {
+			justPressed = append(justPressed, frame)
+		}
+		if play.KeyPressed(key.Start) {
+			t.Errorf("Engine.KeyPressed() read the key input register durring the replay")
+		}
+	}
+
+	if diff := deep.Equal(justPressed, []int{1}); diff != nil {
+		t.Errorf("Engine.KeyJustPressed() frames = %v", diff)
+	}
+
+	play.keyPoll()
+	if play.Playing() || play.currentKeys != 0xFFFF&^hw_key.StartMask {
+		t.Errorf("Engine.keyPoll() did not return to the key input register after the replay")
+	}
+}
@@ -0,0 +1,87 @@
+package game
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	"github.com/go-test/deep"
+)
+
+// repeatInput returns the input repeated n times
+func repeatInput(input memmap.Input, n int) []memmap.Input {
+	inputs := make([]memmap.Input, n)
+	for i := range inputs {
+		inputs[i] = input
+	}
+
+	return inputs
+}
+
+func TestReplay_MarshalBinary(t *testing.T) {
+	tests := []struct {
+		name   string
+		replay *Replay
+		len    int
+	}{
+		{"empty", &Replay{Seed: 7, Inputs: []memmap.Input{}}, replayHeader},
+		{"single frame", &Replay{Seed: -1, Inputs: []memmap.Input{0xFFFE}}, replayHeader + 3},
+		{
+			"runs",
+			&Replay{Seed: 1 << 40, Inputs: append(repeatInput(0xFFFF, 300), repeatInput(0xFFF7, 2)...)},
+			replayHeader + 4 + 3,
+		},
+		{"changes every frame", &Replay{Inputs: []memmap.Input{0xFFFF, 0xFFFE, 0xFFFF, 0xFFFE}}, replayHeader + 4*3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.replay.MarshalBinary()
+			if err != nil {
+				t.Fatalf("Replay.MarshalBinary() error = %v", err)
+			}
+			if len(data) != tt.len {
+				t.Errorf("Replay.MarshalBinary() len = %d, want %d", len(data), tt.len)
+			}
+
+			got := &Replay{}
+			if err := got.UnmarshalBinary(data); err != nil {
+				t.Fatalf("Replay.UnmarshalBinary() error = %v", err)
+			}
+			if diff := deep.Equal(got, tt.replay); diff != nil {
+				t.Errorf("Replay.UnmarshalBinary() = %v", diff)
+			}
+		})
+	}
+}
+
+func TestReplay_MarshalBinary_tooLong(t *testing.T) {
+	replay := &Replay{Inputs: make([]memmap.Input, maxReplayFrames+1)}
+	if _, err := replay.MarshalBinary(); err == nil {
+		t.Errorf("Replay.MarshalBinary() error = nil, want an error for a replay with %d frames", len(replay.Inputs))
+	}
+}
+
+func TestReplay_UnmarshalBinary(t *testing.T) {
+	valid, _ := (&Replay{Seed: 3, Inputs: repeatInput(0xFFFF, 4)}).MarshalBinary()
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte{}},
+		{"short header", valid[:replayHeader-1]},
+		{"bad magic", append([]byte("RPBF"), valid[4:]...)},
+		{"bad version", append(append([]byte(replayMagic), 9), valid[5:]...)},
+		{"missing frames", valid[:replayHeader]},
+		{"truncated run", valid[:len(valid)-1]},
+		{"extra frames", append(append([]byte{}, valid...), 0xFF, 0xFF, 1)},
+		{"too many frames", append(append([]byte{}, valid[:13]...), 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x0F)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Replay{}).UnmarshalBinary(tt.data)
+			if !errors.Is(err, ErrInvalidReplay) {
+				t.Errorf("Replay.UnmarshalBinary() error = %v, want %v", err, ErrInvalidReplay)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !standalone
+
+package game
+
+// objects is empty on the GBA since save states are only supported by the emulator
+type objects struct{}
+
+// track does nothing on the GBA
+func (o *objects) track(obj any) {}
@@ -0,0 +1,15 @@
+//go:build standalone && !headless && local
+
+package game
+
+import "os"
+
+// writeStateFile writes the save state to a file at path
+func writeStateFile(path string, data []byte) error {
+	return os.WriteFile(path, data, 0o0664)
+}
+
+// readStateFile reads the save state file at path
+func readStateFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
@@ -0,0 +1,1068 @@
+//go:build standalone
+
+package game
+
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/bjatkin/flappy_boot/internal/alloc"
+	"github.com/bjatkin/flappy_boot/internal/assets"
+	"github.com/bjatkin/flappy_boot/internal/emu/snapshot"
+	"github.com/bjatkin/flappy_boot/internal/hardware/audio"
+	"github.com/bjatkin/flappy_boot/internal/hardware/dma"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	hw_sprite "github.com/bjatkin/flappy_boot/internal/hardware/sprite"
+	"github.com/bjatkin/flappy_boot/internal/math"
+)
+
+const (
+	// stateMagic is the first value in every save state
+	stateMagic = "FBST"
+
+	// stateVersion is the version of the save state format
+	stateVersion = 1
+
+	// maxStateLen is the longest slice that can be read from a save state, every value takes at least a byte
+	// so the reader also checks slices against the length of the state before they're allocated
+	maxStateLen = 1 << 24
+)
+
+var (
+	// ErrStateHalted is returned when a state is saved after the engine has halted
+	ErrStateHalted = errors.New("the state of a halted game can not be saved")
+
+	// ErrNotStateful is returned when the Runable does not implement Stateful
+	ErrNotStateful = errors.New("the game does not support save states")
+
+	// ErrStateMismatch is returned when a state is loaded into a game that created different engine objects
+	// than the game that saved it
+	ErrStateMismatch = errors.New("the save state was saved by a different game")
+)
+
+// Stateful is a Runable that can save it's state into a save state. The engine saves the state of the sprites,
+// backgrounds and other engine objects but not the objects themselves, so the game must create the same objects
+// in the same order every time it's run. The Runable only needs to save the values that change while it's running
+type Stateful interface {
+	SaveState(w *snapshot.Writer)
+	LoadState(r *snapshot.Reader)
+}
+
+// objects are the sprites, backgrounds, rasters, windows, meta sprites and bitmaps the engine has created in the
+// order they were created. Save states use the order to find each object
+type objects struct {
+	sprites     []*Sprite
+	backgrounds []*Background
+	rasters     []*Raster
+	windows     []*Window
+	metaSprites []*MetaSprite
+	bitmaps     []*Bitmap
+}
+
+// track adds a new engine object to the objects
+func (o *objects) track(obj any) {
+	switch obj := obj.(type) {
+	case *Sprite:
+		o.sprites = append(o.sprites, obj)
+	case *Background:
+		o.backgrounds = append(o.backgrounds, obj)
+	case *Raster:
+		o.rasters = append(o.rasters, obj)
+	case *Window:
+		o.windows = append(o.windows, obj)
+	case *MetaSprite:
+		o.metaSprites = append(o.metaSprites, obj)
+	case *Bitmap:
+		o.bitmaps = append(o.bitmaps, obj)
+	}
+}
+
+// counts returns the number of each kind of object
+func (o *objects) counts() [6]int {
+	return [6]int{
+		len(o.sprites),
+		len(o.backgrounds),
+		len(o.rasters),
+		len(o.windows),
+		len(o.metaSprites),
+		len(o.bitmaps),
+	}
+}
+
+// SaveState saves the state of the emulator into a save state. The state holds the emulated memory and the
+// state of the APU, the engine and the game, everything that's needed to put the game back where it was
+// even after the game has been closed. Only games that implement Stateful can be saved
+func (e *emulator) SaveState() ([]byte, error) {
+	if e.halted {
+		return nil, ErrStateHalted
+	}
+
+	run, ok := e.R.(Stateful)
+	if !ok {
+		return nil, ErrNotStateful
+	}
+
+	w := &snapshot.Writer{}
+	w.String(stateMagic)
+	w.Int(stateVersion)
+	e.E.saveState(w)
+	run.SaveState(w)
+
+	w.Bytes(memmap.IORegBlock[:])
+	w.Bytes(memmap.PaletteBlock[:])
+	w.Bytes(memmap.VRAMBlock[:])
+	w.Bytes(memmap.OAMBlock[:])
+	w.Bytes(memmap.SRAMBlock[:])
+	e.APU.SaveState(w)
+	w.Int(e.frame)
+
+	return w.Data(), nil
+}
+
+// LoadState puts the emulator back into the state it was in when the save state was saved.
+// snapshot.ErrInvalid is returned if the state can not be decoded, if loading fails the emulator is
+// put back the way it was before the state was loaded
+func (e *emulator) LoadState(data []byte) error {
+	backup, backupErr := e.SaveState()
+
+	err := e.loadState(data)
+	if err != nil {
+		if backupErr == nil {
+			_ = e.loadState(backup)
+		}
+		return err
+	}
+
+	e.halted = false
+
+	// draw the loaded frame so it's shown straight away, the rasters are flushed again so the registers
+	// are set for the first scan line the same way they were when the frame was drawn
+	e.E.flushRasters()
+	e.PPU.Redraw()
+	e.PPU.Update()
+
+	return nil
+}
+
+// loadState reads a save state into the emulator
+func (e *emulator) loadState(data []byte) error {
+	run, ok := e.R.(Stateful)
+	if !ok {
+		return ErrNotStateful
+	}
+
+	r := snapshot.NewReader(data)
+	if r.String(len(stateMagic)) != stateMagic || r.Int() != stateVersion {
+		return snapshot.ErrInvalid
+	}
+
+	if err := e.E.loadState(r); err != nil {
+		return err
+	}
+	run.LoadState(r)
+
+	r.Block(memmap.IORegBlock[:])
+	r.Block(memmap.PaletteBlock[:])
+	r.Block(memmap.VRAMBlock[:])
+	r.Block(memmap.OAMBlock[:])
+	r.Block(memmap.SRAMBlock[:])
+	e.APU.LoadState(r)
+	e.frame = r.Int()
+
+	return r.Err()
+}
+
+// saveState writes the state of the engine and every object it has created
+func (e *Engine) saveState(w *snapshot.Writer) {
+	o := &e.objects
+	counts := o.counts()
+	snapshot.Ints(w, counts[:])
+
+	for _, s := range o.sprites {
+		s.save(w)
+	}
+	for _, b := range o.backgrounds {
+		b.save(w)
+	}
+	for _, r := range o.rasters {
+		r.save(w)
+	}
+	for _, win := range o.windows {
+		win.save(w)
+	}
+	for _, m := range o.metaSprites {
+		writeV2(w, m.pos)
+	}
+	for _, b := range o.bitmaps {
+		w.Int(b.page)
+	}
+
+	// the allocators are saved before the assets so the asset allocations can be rebuilt from them
+	e.saveAlloc(w)
+	e.saveAssets(w)
+
+	w.Int(e.spriteCount)
+	for _, s := range e.activeSprites[:e.spriteCount] {
+		writeObject(w, o.sprites, s)
+	}
+	w.Int(e.spriteSeq)
+	for _, win := range e.activeWindows {
+		writeObject(w, o.windows, win)
+	}
+	writeObject(w, o.windows, e.spriteWindow)
+	w.Int(int(e.windowOutside))
+	for _, r := range e.activeRasters {
+		writeObject(w, o.rasters, r)
+	}
+	for _, b := range e.activeBackgrounds {
+		writeObject(w, o.backgrounds, b)
+	}
+	writeObject(w, o.bitmaps, e.bitmap)
+
+	snapshot.Ints(w, e.palBuff[:])
+	w.Int(int(e.fadeCol))
+	w.Int(int(e.fadeFrac))
+	w.Bool(e.doFade)
+	snapshot.Ints(w, e.palShadow[:])
+	w.Bool(e.palDirty)
+
+	e.regs.save(w)
+	w.Int(e.flushMisses)
+	w.Int(int(e.blendControll))
+	w.Int(int(e.blendAlpha))
+	w.Int(int(e.blendY))
+	w.Int(int(e.mosaic))
+	w.String(e.scene)
+
+	e.saveSound(w)
+
+	w.Int(int(e.previousKeys))
+	w.Int(int(e.currentKeys))
+	w.Int(e.frame)
+	w.Bool(e.profiler.show)
+
+	w.Int64(e.seed)
+	w.Bool(e.seeded)
+	writeReplay(w, e.recording)
+	writeReplay(w, e.replay)
+	w.Int(e.replayFrame)
+}
+
+// loadState reads the state written by saveState. ErrStateMismatch is returned without changing the engine
+// if the state was saved by an engine that created different objects
+func (e *Engine) loadState(r *snapshot.Reader) error {
+	o := &e.objects
+	var counts [6]int
+	snapshot.ReadArray(r, counts[:])
+	if counts != o.counts() {
+		return ErrStateMismatch
+	}
+
+	for _, s := range o.sprites {
+		s.load(r)
+	}
+	for _, b := range o.backgrounds {
+		b.load(r)
+	}
+	for _, raster := range o.rasters {
+		raster.load(r)
+	}
+	for _, win := range o.windows {
+		win.load(r)
+	}
+	for _, m := range o.metaSprites {
+		m.pos = readV2(r)
+	}
+	for _, b := range o.bitmaps {
+		b.page = r.Int()
+	}
+
+	e.loadAlloc(r)
+	e.loadAssets(r)
+
+	e.spriteCount = r.Len(len(e.activeSprites))
+	e.activeSprites = [hw_sprite.MaxAttrs]*Sprite{}
+	for i := range e.activeSprites[:e.spriteCount] {
+		e.activeSprites[i] = readObject(r, o.sprites)
+	}
+	e.spriteSeq = r.Int()
+	for i := range e.activeWindows {
+		e.activeWindows[i] = readObject(r, o.windows)
+	}
+	e.spriteWindow = readObject(r, o.windows)
+	e.windowOutside = memmap.WindowControll(r.Int())
+	for i := range e.activeRasters {
+		e.activeRasters[i] = readObject(r, o.rasters)
+	}
+	for i := range e.activeBackgrounds {
+		e.activeBackgrounds[i] = readObject(r, o.backgrounds)
+	}
+	e.bitmap = readObject(r, o.bitmaps)
+
+	snapshot.ReadArray(r, e.palBuff[:])
+	e.fadeCol = memmap.PaletteValue(r.Int())
+	e.fadeFrac = math.Fix8(r.Int())
+	e.doFade = r.Bool()
+	snapshot.ReadArray(r, e.palShadow[:])
+	e.palDirty = r.Bool()
+
+	e.regs.load(r)
+	e.flushMisses = r.Int()
+	e.blendControll = memmap.BlendControll(r.Int())
+	e.blendAlpha = memmap.BlendAlpha(r.Int())
+	e.blendY = memmap.BlendY(r.Int())
+	e.mosaic = memmap.MosaicSize(r.Int())
+	e.scene = r.String(maxStateLen)
+
+	e.loadSound(r)
+
+	e.previousKeys = memmap.Input(r.Int())
+	e.currentKeys = memmap.Input(r.Int())
+	e.frame = r.Int()
+	e.profiler.show = r.Bool()
+
+	e.seed = r.Int64()
+	e.seeded = r.Bool()
+	e.recording = readReplay(r)
+	e.replay = readReplay(r)
+	e.replayFrame = r.Int()
+
+	return nil
+}
+
+// saveAlloc writes the state of the engines allocators
+func (e *Engine) saveAlloc(w *snapshot.Writer) {
+	for _, p := range []*alloc.Pal{e.bgPalAlloc, e.sprPalAlloc} {
+		slots := p.Slots()
+		for _, used := range slots {
+			w.Bool(used)
+		}
+		w.Bool(p.IsDirty())
+	}
+
+	for _, v := range []*alloc.VRAM{e.bgTileAlloc, e.sprTileAlloc, e.mapAlloc} {
+		snapshot.Ints(w, v.Cells())
+	}
+}
+
+// loadAlloc reads the state written by saveAlloc
+func (e *Engine) loadAlloc(r *snapshot.Reader) {
+	for _, p := range []*alloc.Pal{e.bgPalAlloc, e.sprPalAlloc} {
+		var slots [8]bool
+		for i := range slots {
+			slots[i] = r.Bool()
+		}
+		p.SetSlots(slots, r.Bool())
+	}
+
+	for _, v := range []*alloc.VRAM{e.bgTileAlloc, e.sprTileAlloc, e.mapAlloc} {
+		if !v.SetCells(snapshot.ReadInts[int](r, maxStateLen)) {
+			r.Fail()
+		}
+	}
+}
+
+// stateAssets are the tile maps and tile sets used by the engines backgrounds and sprites
+type stateAssets struct {
+	tileMaps   []*assets.TileMap
+	bgSets     []*assets.TileSet
+	spriteSets []*assets.TileSet
+}
+
+// assets returns the tile maps and tile sets used by the engines objects in the order they're first used
+func (e *Engine) assets() stateAssets {
+	var a stateAssets
+	seenMaps := map[*assets.TileMap]bool{}
+	seenSets := map[*assets.TileSet]bool{}
+
+	for _, b := range e.objects.backgrounds {
+		if !seenMaps[b.tileMap] {
+			seenMaps[b.tileMap] = true
+			a.tileMaps = append(a.tileMaps, b.tileMap)
+		}
+		if set := b.tileMap.TileSet(); !seenSets[set] {
+			seenSets[set] = true
+			a.bgSets = append(a.bgSets, set)
+		}
+	}
+
+	for _, s := range e.objects.sprites {
+		if !seenSets[s.tileSet] {
+			seenSets[s.tileSet] = true
+			a.spriteSets = append(a.spriteSets, s.tileSet)
+		}
+	}
+
+	return a
+}
+
+// saveAssets writes the state of the tile maps and tile sets used by the engines objects. Assets are
+// package variables so only the values that change when they're loaded or edited are saved
+func (e *Engine) saveAssets(w *snapshot.Writer) {
+	a := e.assets()
+	for _, m := range a.tileMaps {
+		snapshot.Ints(w, m.Tiles())
+		snapshot.Ints(w, m.DirtyTiles())
+		writeVMem(w, m.Alloc())
+	}
+
+	for _, set := range append(a.bgSets, a.spriteSets...) {
+		writeVMem(w, set.Alloc())
+		writePMem(w, set.Palette().Alloc())
+	}
+}
+
+// loadAssets reads the state written by saveAssets, the allocators must be loaded first
+func (e *Engine) loadAssets(r *snapshot.Reader) {
+	a := e.assets()
+	for _, m := range a.tileMaps {
+		tiles := snapshot.ReadInts[memmap.VRAMValue](r, len(m.Tiles()))
+		if len(tiles) != len(m.Tiles()) {
+			r.Fail()
+		}
+		m.SetTiles(tiles, snapshot.ReadInts[int](r, maxStateLen))
+		m.SetAlloc(readVMem(r, e.mapAlloc))
+	}
+
+	for _, set := range a.bgSets {
+		set.SetAlloc(readVMem(r, e.bgTileAlloc))
+		set.Palette().SetAlloc(readPMem(r, e.bgPalAlloc))
+	}
+	for _, set := range a.spriteSets {
+		set.SetAlloc(readVMem(r, e.sprTileAlloc))
+		set.Palette().SetAlloc(readPMem(r, e.sprPalAlloc))
+	}
+}
+
+// releaseAssets marks the tile maps and tile sets used by the engines objects as not loaded without
+// freeing them from the engines allocators
+func (e *Engine) releaseAssets() {
+	a := e.assets()
+	for _, m := range a.tileMaps {
+		m.SetAlloc(nil)
+	}
+	for _, set := range append(a.bgSets, a.spriteSets...) {
+		set.SetAlloc(nil)
+		set.Palette().SetAlloc(nil)
+	}
+}
+
+// writeVMem writes the offset of a VRAM allocation, -1 is written if mem is nil
+func writeVMem(w *snapshot.Writer, mem *alloc.VMem) {
+	if mem == nil {
+		w.Int(-1)
+		return
+	}
+	w.Int(mem.Offset)
+}
+
+// readVMem reads an allocation written by writeVMem from the allocator
+func readVMem(r *snapshot.Reader, v *alloc.VRAM) *alloc.VMem {
+	offset := r.Int()
+	if offset < 0 {
+		return nil
+	}
+
+	mem := v.At(offset)
+	if mem == nil {
+		r.Fail()
+	}
+	return mem
+}
+
+// writePMem writes the offset of a palette allocation, -1 is written if mem is nil
+func writePMem(w *snapshot.Writer, mem *alloc.PMem) {
+	if mem == nil {
+		w.Int(-1)
+		return
+	}
+	w.Int(mem.Offset)
+}
+
+// readPMem reads an allocation written by writePMem from the allocator
+func readPMem(r *snapshot.Reader, p *alloc.Pal) *alloc.PMem {
+	offset := r.Int()
+	if offset < 0 {
+		return nil
+	}
+
+	mem := p.At(offset)
+	if mem == nil {
+		r.Fail()
+	}
+	return mem
+}
+
+// saveSound writes the state of the voices, the music, the sound effects and the sound stream
+func (e *Engine) saveSound(w *snapshot.Writer) {
+	for i := range e.voices {
+		v := &e.voices[i]
+		writeSound(w, v.sound)
+		w.Int(int(v.pos))
+		w.Int(v.seq)
+		w.Int(int(v.Volume))
+		w.Int(int(v.Rate))
+	}
+	w.Int(e.voiceSeq)
+
+	e.music.save(w)
+
+	for i := range e.sfx {
+		writeSFX(w, e.sfx[i].sfx)
+		w.Int(e.sfx[i].frame)
+	}
+	for i := range e.dmg {
+		e.dmg[i].save(w)
+	}
+
+	for i := range e.soundBuff {
+		snapshot.Ints(w, e.soundBuff[i][:])
+	}
+	w.Int(e.soundPage)
+
+	// the sound stream is a go slice so it's saved as the sound buffer it's streaming
+	_, src, pos := dma.StreamState(soundDMA)
+	page := -1
+	for i := range e.soundBuff {
+		if len(src) > 0 && &src[0] == &e.soundBuff[i][0] {
+			page = i
+		}
+	}
+	w.Int(page)
+	w.Int(pos)
+}
+
+// loadSound reads the state written by saveSound
+func (e *Engine) loadSound(r *snapshot.Reader) {
+	for i := range e.voices {
+		v := &e.voices[i]
+		v.sound = readSound(r)
+		v.pos = math.Fix8(r.Int())
+		v.seq = r.Int()
+		v.Volume = math.Fix8(r.Int())
+		v.Rate = math.Fix8(r.Int())
+	}
+	e.voiceSeq = r.Int()
+
+	e.music.load(r)
+
+	for i := range e.sfx {
+		e.sfx[i].sfx = readSFX(r)
+		e.sfx[i].frame = r.Int()
+	}
+	for i := range e.dmg {
+		e.dmg[i].load(r)
+	}
+
+	for i := range e.soundBuff {
+		snapshot.ReadArray(r, e.soundBuff[i][:])
+	}
+	e.soundPage = r.Int() & 1
+
+	page, pos := r.Int(), r.Int()
+	switch page {
+	case -1:
+		dma.RestoreStream(soundDMA, nil, nil, 0)
+	case 0, 1:
+		if pos < 0 {
+			r.Fail()
+			return
+		}
+		dma.RestoreStream(soundDMA, audio.FIFOA, e.soundBuff[page][:], pos)
+	default:
+		r.Fail()
+	}
+}
+
+// save writes the state of the music player. The song is saved by value since it may not be a package
+// variable, the channels instruments are saved as their instrument number in the song
+func (m *musicPlayer) save(w *snapshot.Writer) {
+	writeSong(w, m.song)
+	w.Int(m.pos)
+	w.Int(m.row)
+	w.Int(m.tick)
+	w.Int(m.speed)
+	w.Int(m.tempo)
+	w.Int(m.ticks)
+	w.Int(m.jump)
+	w.Int(m.skip)
+
+	for i := range m.channels {
+		ch := &m.channels[i]
+
+		var inst int
+		for n := 1; m.song != nil && m.song.Instrument(n) != nil; n++ {
+			if m.song.Instrument(n) == ch.inst {
+				inst = n
+			}
+		}
+		w.Int(inst)
+
+		w.Int(ch.key)
+		w.Int(ch.period)
+		w.Int(ch.volume)
+		w.Int(int(ch.effect))
+		w.Int(int(ch.param))
+		w.Int(ch.target)
+		w.Int(ch.portaSpeed)
+	}
+}
+
+// load reads the state written by save
+func (m *musicPlayer) load(r *snapshot.Reader) {
+	m.song = readSong(r)
+	m.pos = r.Int()
+	m.row = r.Int()
+	m.tick = r.Int()
+	m.speed = r.Int()
+	m.tempo = r.Int()
+	m.ticks = r.Int()
+	m.jump = r.Int()
+	m.skip = r.Int()
+
+	for i := range m.channels {
+		ch := &m.channels[i]
+
+		ch.inst = nil
+		if inst := r.Int(); inst > 0 {
+			if m.song == nil || m.song.Instrument(inst) == nil {
+				r.Fail()
+				return
+			}
+			ch.inst = m.song.Instrument(inst)
+		}
+
+		ch.key = r.Int()
+		ch.period = r.Int()
+		ch.volume = r.Int()
+		ch.effect = uint8(r.Int())
+		ch.param = uint8(r.Int())
+		ch.target = r.Int()
+		ch.portaSpeed = r.Int()
+	}
+}
+
+// writeSound writes a sound by value
+func writeSound(w *snapshot.Writer, s *assets.Sound) {
+	w.Bool(s != nil)
+	if s == nil {
+		return
+	}
+
+	snapshot.Ints(w, s.Samples())
+	w.Int(s.Loop())
+	w.Int(s.Rate())
+}
+
+// readSound reads a sound written by writeSound
+func readSound(r *snapshot.Reader) *assets.Sound {
+	if !r.Bool() {
+		return nil
+	}
+
+	samples := snapshot.ReadInts[int8](r, maxStateLen)
+	loop := r.Int()
+	return assets.NewSoundRate(samples, loop, r.Int())
+}
+
+// writeSong writes a song by value. The patterns are written in the order they're played so the song
+// can be rebuilt without knowing how the patterns were ordered
+func writeSong(w *snapshot.Writer, s *assets.Song) {
+	w.Bool(s != nil)
+	if s == nil {
+		return
+	}
+
+	w.Int(s.Channels())
+	w.Int(s.Speed())
+	w.Int(s.Tempo())
+	w.Int(s.Restart())
+
+	w.Int(s.Length())
+	for pos := 0; pos < s.Length(); pos++ {
+		notes := s.Pattern(pos)
+		data := make([]byte, 0, len(notes)*4)
+		for _, n := range notes {
+			data = append(data, n.Key, n.Instrument, n.Effect, n.Param)
+		}
+		w.Bytes(data)
+	}
+
+	var count int
+	for s.Instrument(count+1) != nil {
+		count++
+	}
+	w.Int(count)
+	for n := 1; n <= count; n++ {
+		inst := s.Instrument(n)
+		writeSound(w, inst.Sound)
+		w.Int(inst.Volume)
+		w.Int(inst.Tune)
+	}
+}
+
+// readSong reads a song written by writeSong
+func readSong(r *snapshot.Reader) *assets.Song {
+	if !r.Bool() {
+		return nil
+	}
+
+	channels, speed, tempo, restart := r.Int(), r.Int(), r.Int(), r.Int()
+
+	// the song order is a list of 8 bit pattern numbers so a song has at most 256 positions
+	length := r.Len(256)
+	order := make([]uint8, length)
+	patterns := make([][]assets.Note, length)
+	for pos := range patterns {
+		order[pos] = uint8(pos)
+
+		data := r.Bytes(maxStateLen)
+		notes := make([]assets.Note, len(data)/4)
+		for i := range notes {
+			notes[i] = assets.Note{Key: data[i*4], Instrument: data[i*4+1], Effect: data[i*4+2], Param: data[i*4+3]}
+		}
+		patterns[pos] = notes
+	}
+
+	// note instruments are 8 bit numbers
+	instruments := make([]assets.Instrument, r.Len(255))
+	for i := range instruments {
+		instruments[i] = assets.Instrument{Sound: readSound(r), Volume: r.Int(), Tune: r.Int()}
+	}
+
+	if channels < 1 || channels > musicChannels {
+		r.Fail()
+		return nil
+	}
+	return assets.NewSong(channels, speed, tempo, restart, order, patterns, instruments)
+}
+
+// writeSFX writes a sound effect by value
+func writeSFX(w *snapshot.Writer, sfx *SFX) {
+	w.Bool(sfx != nil)
+	if sfx == nil {
+		return
+	}
+
+	w.Int(int(sfx.Channel))
+	snapshot.Ints(w, sfx.Notes)
+	w.Int(sfx.NoteFrames)
+	w.Int(sfx.Length)
+	w.Int(int(sfx.Duty))
+	w.Int(sfx.Envelope.Volume)
+	w.Int(sfx.Envelope.Step)
+	w.Bool(sfx.Envelope.Up)
+	w.Int(sfx.Sweep.Time)
+	w.Int(sfx.Sweep.Shift)
+	w.Bool(sfx.Sweep.Down)
+	w.Bytes(sfx.Wave[:])
+	w.Bool(sfx.Metallic)
+}
+
+// readSFX reads a sound effect written by writeSFX
+func readSFX(r *snapshot.Reader) *SFX {
+	if !r.Bool() {
+		return nil
+	}
+
+	sfx := &SFX{}
+	sfx.Channel = DMGChannel(r.Int())
+	sfx.Notes = snapshot.ReadInts[int](r, maxStateLen)
+	sfx.NoteFrames = r.Int()
+	sfx.Length = r.Int()
+	sfx.Duty = Duty(r.Int())
+	sfx.Envelope.Volume = r.Int()
+	sfx.Envelope.Step = r.Int()
+	sfx.Envelope.Up = r.Bool()
+	sfx.Sweep.Time = r.Int()
+	sfx.Sweep.Shift = r.Int()
+	sfx.Sweep.Down = r.Bool()
+	r.Block(sfx.Wave[:])
+	sfx.Metallic = r.Bool()
+
+	if sfx.Channel < ChannelSweep || sfx.Channel > ChannelNoise || sfx.Duty < Duty50 || sfx.Duty > Duty75 {
+		r.Fail()
+		return nil
+	}
+	return sfx
+}
+
+// save writes the DMG channel registers
+func (d *dmgRegs) save(w *snapshot.Writer) {
+	w.Bool(d.pending)
+	w.Int(int(d.sweep))
+	w.Int(int(d.envelope))
+	w.Int(int(d.freq))
+	w.Int(int(d.noise))
+	w.Int(int(d.volume))
+	w.Bytes(d.wave[:])
+}
+
+// load reads the DMG channel registers written by save
+func (d *dmgRegs) load(r *snapshot.Reader) {
+	d.pending = r.Bool()
+	d.sweep = memmap.ToneSweep(r.Int())
+	d.envelope = memmap.SoundEnvelope(r.Int())
+	d.freq = memmap.SoundFreq(r.Int())
+	d.noise = memmap.NoiseFreq(r.Int())
+	d.volume = memmap.WaveVolume(r.Int())
+	r.Block(d.wave[:])
+}
+
+// save writes the shadow registers
+func (s *shadowRegs) save(w *snapshot.Writer) {
+	w.Int(int(s.displayControll))
+	snapshot.Ints(w, s.bgControll[:])
+	snapshot.Ints(w, s.bgHOffset[:])
+	snapshot.Ints(w, s.bgVOffset[:])
+	snapshot.Ints(w, s.winH[:])
+	snapshot.Ints(w, s.winV[:])
+	w.Int(int(s.winIn))
+	w.Int(int(s.winOut))
+	w.Int(int(s.blendControll))
+	w.Int(int(s.blendAlpha))
+	w.Int(int(s.blendY))
+	w.Int(int(s.mosaic))
+}
+
+// load reads the shadow registers written by save
+func (s *shadowRegs) load(r *snapshot.Reader) {
+	s.displayControll = memmap.DisplayControll(r.Int())
+	snapshot.ReadArray(r, s.bgControll[:])
+	snapshot.ReadArray(r, s.bgHOffset[:])
+	snapshot.ReadArray(r, s.bgVOffset[:])
+	snapshot.ReadArray(r, s.winH[:])
+	snapshot.ReadArray(r, s.winV[:])
+	s.winIn = memmap.WindowControll(r.Int())
+	s.winOut = memmap.WindowControll(r.Int())
+	s.blendControll = memmap.BlendControll(r.Int())
+	s.blendAlpha = memmap.BlendAlpha(r.Int())
+	s.blendY = memmap.BlendY(r.Int())
+	s.mosaic = memmap.MosaicSize(r.Int())
+}
+
+// save writes the values of the sprite that change while the game is running
+func (s *Sprite) save(w *snapshot.Writer) {
+	writeV2(w, s.Pos)
+	writeV2(w, s.Offset)
+	w.Int(s.TileIndex)
+	w.Bool(s.HFlip)
+	w.Bool(s.VFlip)
+	w.Int(int(s.Priority))
+	w.Int(s.Z)
+	w.Bool(s.SemiTransparent)
+	w.Bool(s.Window)
+	w.Bool(s.Mosaic)
+	w.Bool(s.Affine)
+	w.Int(int(s.Rotation))
+	writeV2(w, s.Scale)
+	w.Bool(s.DoubleSize)
+
+	w.Bool(s.animation != nil)
+	w.Int(len(s.animation))
+	for _, f := range s.animation {
+		w.Int(f.Index)
+		w.Bool(f.HFlip)
+		w.Bool(f.VFlip)
+		writeV2(w, f.Offset)
+		w.Int(f.Len)
+	}
+	w.Int(s.aniFrame)
+	w.Int(s.aniCounter)
+
+	w.Bool(s.active)
+	w.Int(s.seq)
+}
+
+// load reads the sprite values written by save
+func (s *Sprite) load(r *snapshot.Reader) {
+	s.Pos = readV2(r)
+	s.Offset = readV2(r)
+	s.TileIndex = r.Int()
+	s.HFlip = r.Bool()
+	s.VFlip = r.Bool()
+	s.Priority = hw_sprite.Attr2(r.Int())
+	s.Z = r.Int()
+	s.SemiTransparent = r.Bool()
+	s.Window = r.Bool()
+	s.Mosaic = r.Bool()
+	s.Affine = r.Bool()
+	s.Rotation = math.Fix8(r.Int())
+	s.Scale = readV2(r)
+	s.DoubleSize = r.Bool()
+
+	s.animation = nil
+	animated := r.Bool()
+	frames := r.Len(maxStateLen)
+	if animated {
+		s.animation = make([]Frame, 0, frames)
+	}
+	for i := 0; i < frames; i++ {
+		s.animation = append(s.animation, Frame{
+			Index:  r.Int(),
+			HFlip:  r.Bool(),
+			VFlip:  r.Bool(),
+			Offset: readV2(r),
+			Len:    r.Int(),
+		})
+	}
+	s.aniFrame = r.Int()
+	s.aniCounter = r.Int()
+	if animated && (s.aniFrame < 0 || s.aniFrame >= frames) {
+		r.Fail()
+	}
+
+	s.active = r.Bool()
+	s.seq = r.Int()
+}
+
+// save writes the values of the background that change while the game is running
+func (b *Background) save(w *snapshot.Writer) {
+	w.Bool(b.added)
+	w.Int(int(b.HScroll))
+	w.Int(int(b.VScroll))
+	w.Bool(b.Mosaic)
+}
+
+// load reads the background values written by save
+func (b *Background) load(r *snapshot.Reader) {
+	b.added = r.Bool()
+	b.HScroll = math.Fix8(r.Int())
+	b.VScroll = math.Fix8(r.Int())
+	b.Mosaic = r.Bool()
+}
+
+// save writes the values of the raster that change while the game is running
+func (r *Raster) save(w *snapshot.Writer) {
+	writeReg(w, r.target)
+	snapshot.Ints(w, r.table[:])
+	writeReg(w, r.nextTarget)
+	snapshot.Ints(w, r.next[:])
+	snapshot.Ints(w, r.Lines[:])
+}
+
+// load reads the raster values written by save
+func (r *Raster) load(sr *snapshot.Reader) {
+	r.target = readReg(sr)
+	snapshot.ReadArray(sr, r.table[:])
+	r.nextTarget = readReg(sr)
+	snapshot.ReadArray(sr, r.next[:])
+	snapshot.ReadArray(sr, r.Lines[:])
+}
+
+// save writes the values of the window that change while the game is running
+func (win *Window) save(w *snapshot.Writer) {
+	w.Int(win.Bounds.X1)
+	w.Int(win.Bounds.Y1)
+	w.Int(win.Bounds.X2)
+	w.Int(win.Bounds.Y2)
+	w.Int(int(win.Layers))
+	w.Bool(win.Effects)
+}
+
+// load reads the window values written by save
+func (win *Window) load(r *snapshot.Reader) {
+	win.Bounds.X1 = r.Int()
+	win.Bounds.Y1 = r.Int()
+	win.Bounds.X2 = r.Int()
+	win.Bounds.Y2 = r.Int()
+	win.Layers = Layer(r.Int())
+	win.Effects = r.Bool()
+}
+
+// writeObject writes the index of an engine object, -1 is written if obj is nil
+func writeObject[T any](w *snapshot.Writer, objs []*T, obj *T) {
+	for i := range objs {
+		if objs[i] == obj {
+			w.Int(i)
+			return
+		}
+	}
+
+	w.Int(-1)
+}
+
+// readObject reads an engine object written by writeObject
+func readObject[T any](r *snapshot.Reader, objs []*T) *T {
+	i := r.Int()
+	if i < -1 || i >= len(objs) {
+		r.Fail()
+		return nil
+	}
+	if i == -1 {
+		return nil
+	}
+
+	return objs[i]
+}
+
+// writeReg writes a register as it's offset into the IO registers. Palette raster targets are the only
+// registers outside of the IO registers so they're written as an offset past the end of the IO registers.
+// The memory blocks are at a different address each time the game is run so pointers can't be saved directly
+func writeReg(w *snapshot.Writer, reg *uint16) {
+	if reg == nil {
+		w.Int(-1)
+		return
+	}
+
+	addr := uintptr(unsafe.Pointer(reg))
+	if addr >= memmap.IOAddr && addr < memmap.IOAddr+uintptr(len(memmap.IORegBlock)) {
+		w.Int(int(addr - memmap.IOAddr))
+		return
+	}
+	w.Int(len(memmap.IORegBlock) + int(addr-memmap.PaletteAddr))
+}
+
+// readReg reads a register written by writeReg
+func readReg(r *snapshot.Reader) *uint16 {
+	offset := r.Int()
+	switch {
+	case offset == -1:
+		return nil
+	case offset < 0 || offset%2 != 0 || offset >= len(memmap.IORegBlock)+len(memmap.PaletteBlock):
+		r.Fail()
+		return nil
+	case offset < len(memmap.IORegBlock):
+		return (*uint16)(unsafe.Pointer(&memmap.IORegBlock[offset]))
+	default:
+		return (*uint16)(unsafe.Pointer(&memmap.PaletteBlock[offset-len(memmap.IORegBlock)]))
+	}
+}
+
+// writeV2 writes a vector
+func writeV2(w *snapshot.Writer, v math.V2) {
+	w.Int(int(v.X))
+	w.Int(int(v.Y))
+}
+
+// readV2 reads a vector written by writeV2
+func readV2(r *snapshot.Reader) math.V2 {
+	return math.V2{X: math.Fix8(r.Int()), Y: math.Fix8(r.Int())}
+}
+
+// writeReplay writes a replay in the replay file format, it's used for the engines recording and replay
+func writeReplay(w *snapshot.Writer, replay *Replay) {
+	var data []byte
+	if replay != nil {
+		// a replay can only fail to marshal if it's longer than 24 hours
+		data, _ = replay.MarshalBinary()
+	}
+	w.Bool(data != nil)
+	w.Bytes(data)
+}
+
+// readReplay reads a replay written by writeReplay
+func readReplay(r *snapshot.Reader) *Replay {
+	ok := r.Bool()
+	data := r.Bytes(maxStateLen)
+	if !ok {
+		return nil
+	}
+
+	replay := &Replay{}
+	if err := replay.UnmarshalBinary(data); err != nil {
+		r.Fail()
+		return nil
+	}
+	return replay
+}
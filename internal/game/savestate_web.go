@@ -0,0 +1,30 @@
+//go:build standalone && !headless && web
+
+package game
+
+import (
+	"encoding/base64"
+	"errors"
+	"syscall/js"
+)
+
+// errNoStateFile is returned when a state is loaded before one has been saved
+var errNoStateFile = errors.New("no state has been saved")
+
+// writeStateFile writes the save state to local storage, path is used as the key
+func writeStateFile(path string, data []byte) error {
+	localStorage := js.Global().Get("localStorage")
+	_ = localStorage.Call("setItem", path, base64.StdEncoding.EncodeToString(data))
+	return nil
+}
+
+// readStateFile reads the save state from local storage, path is used as the key
+func readStateFile(path string) ([]byte, error) {
+	localStorage := js.Global().Get("localStorage")
+	data := localStorage.Call("getItem", path)
+	if data.IsNull() {
+		return nil, errNoStateFile
+	}
+
+	return base64.StdEncoding.DecodeString(data.String())
+}
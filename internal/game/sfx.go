@@ -0,0 +1,305 @@
+package game
+
+import (
+	"github.com/bjatkin/flappy_boot/internal/hardware/audio"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+// DMGChannel is one of the 4 DMG sound channels
+type DMGChannel int
+
+const (
+	// ChannelSweep is sound 1, a square wave with a frequency sweep
+	ChannelSweep DMGChannel = iota
+
+	// ChannelSquare is sound 2, a square wave
+	ChannelSquare
+
+	// ChannelWave is sound 3, it plays a custom 32 sample wave
+	ChannelWave
+
+	// ChannelNoise is sound 4, it plays noise
+	ChannelNoise
+)
+
+// Duty is the fraction of a square wave that is high, it changes the tone of the square wave channels
+type Duty int
+
+const (
+	// Duty50 is a 50% square wave, it's the default
+	Duty50 Duty = iota
+
+	// Duty12 is a 12.5% square wave
+	Duty12
+
+	// Duty25 is a 25% square wave
+	Duty25
+
+	// Duty75 is a 75% square wave
+	Duty75
+)
+
+// Sweep changes the frequency of a note while it plays, it's only used by ChannelSweep
+type Sweep struct {
+	// Time is the time between each sweep step in 1/128ths of a second (0 - 7), at 0 the sweep is off
+	Time int
+
+	// Shift sets how much the frequency changes at each step, the frequency changes by freq/2^Shift (0 - 7)
+	Shift int
+
+	// Down sweeps the frequency down instead of up
+	Down bool
+}
+
+// Envelope changes the volume of a note while it plays
+type Envelope struct {
+	// Volume is the starting volume of each note (0 - 15)
+	Volume int
+
+	// Step is the time between each volume step in 1/64ths of a second (0 - 7), at 0 the volume does not change.
+	// The wave channel does not support envelopes so this is ignored by ChannelWave
+	Step int
+
+	// Up increases the volume at each step instead of decreasing it
+	Up bool
+}
+
+// SFX is a sound effect that is played on one of the DMG channels. It's a list of notes that are played
+// one after another, the engine starts each new note at the start of a frame
+type SFX struct {
+	// Channel is the DMG channel the sound effect is played on
+	Channel DMGChannel
+
+	// Notes are the frequencies of each note in Hz, 0 is a rest. For the noise channel this is the frequency
+	// of the noise generator, higher frequencies sound more like a hiss and lower frequencies more like a rumble
+	Notes []int
+
+	// NoteFrames is the number of frames each note plays before the next note starts
+	NoteFrames int
+
+	// Length is how long each note plays in 1/256ths of a second, at 0 the note plays until the next note starts
+	Length int
+
+	// Duty is the duty of the square wave, it's only used by ChannelSweep and ChannelSquare
+	Duty Duty
+
+	// Envelope is the volume envelope for each note
+	Envelope Envelope
+
+	// Sweep is the frequency sweep for each note, it's only used by ChannelSweep
+	Sweep Sweep
+
+	// Wave is the 32 4 bit samples played by ChannelWave, the high nibble of each byte is played first
+	Wave [16]byte
+
+	// Metallic uses 7 bit noise which sounds more metallic than the default 15 bit noise. It's only used by ChannelNoise
+	Metallic bool
+}
+
+// sfxPlayer is the sound effect playing on a DMG channel
+type sfxPlayer struct {
+	sfx   *SFX
+	frame int
+}
+
+// dmgRegs are the register values for a DMG channel that are written on the next flush
+type dmgRegs struct {
+	// pending is true if the registers need to be written on the next flush
+	pending bool
+
+	sweep    memmap.ToneSweep
+	envelope memmap.SoundEnvelope
+	freq     memmap.SoundFreq
+	noise    memmap.NoiseFreq
+	volume   memmap.WaveVolume
+	wave     [16]byte
+}
+
+// dmgDuty maps each Duty to its register value
+var dmgDuty = [4]memmap.SoundEnvelope{
+	Duty50: audio.Duty50,
+	Duty12: audio.Duty12,
+	Duty25: audio.Duty25,
+	Duty75: audio.Duty75,
+}
+
+// PlaySFX starts playing the sound effect on its channel, any sound effect that's already playing
+// on the channel is stopped
+func (e *Engine) PlaySFX(sfx *SFX) {
+	e.sfx[sfx.Channel] = sfxPlayer{sfx: sfx}
+}
+
+// StopSFX stops the sound effect that's playing on the channel
+func (e *Engine) StopSFX(channel DMGChannel) {
+	e.sfx[channel] = sfxPlayer{}
+	e.dmg[channel] = dmgRegs{pending: true}
+}
+
+// drawSFX advances the sound effect on each channel and sets the channels registers when a new note starts
+func (e *Engine) drawSFX() {
+	for i := range e.sfx {
+		p := &e.sfx[i]
+		if p.sfx == nil {
+			continue
+		}
+
+		frames := p.sfx.NoteFrames
+		if frames < 1 {
+			frames = 1
+		}
+
+		if p.frame%frames == 0 {
+			note := p.frame / frames
+			if note >= len(p.sfx.Notes) {
+				e.StopSFX(p.sfx.Channel)
+				continue
+			}
+
+			e.dmg[i] = noteRegs(p.sfx, p.sfx.Notes[note])
+		}
+
+		p.frame++
+	}
+}
+
+// flushSFX writes the pending registers for each DMG channel, restarting the channel so the new note plays
+func (e *Engine) flushSFX() {
+	for i := range e.dmg {
+		regs := &e.dmg[i]
+		if !regs.pending {
+			continue
+		}
+		regs.pending = false
+
+		switch DMGChannel(i) {
+		case ChannelSweep:
+			memmap.SetReg(audio.Sound1Sweep, regs.sweep)
+			memmap.SetReg(audio.Sound1Envelope, regs.envelope)
+			memmap.SetReg(audio.Sound1Freq, regs.freq|audio.SoundRestart)
+		case ChannelSquare:
+			memmap.SetReg(audio.Sound2Envelope, regs.envelope)
+			memmap.SetReg(audio.Sound2Freq, regs.freq|audio.SoundRestart)
+		case ChannelWave:
+			// wave ram writes go to the bank that is not playing, so play bank 1 while bank 0 is written
+			memmap.SetReg(audio.Sound3Controll, audio.WaveOff|audio.WaveBank1)
+			for j := range audio.WaveRAM {
+				audio.WaveRAM[j] = uint16(regs.wave[j*2]) | uint16(regs.wave[j*2+1])<<8
+			}
+
+			if regs.volume == audio.WaveVolume0 {
+				continue
+			}
+			memmap.SetReg(audio.Sound3Controll, audio.WaveOn|audio.WaveBank0)
+			memmap.SetReg(audio.Sound3Volume, regs.volume)
+			memmap.SetReg(audio.Sound3Freq, regs.freq|audio.SoundRestart)
+		case ChannelNoise:
+			memmap.SetReg(audio.Sound4Envelope, regs.envelope)
+			memmap.SetReg(audio.Sound4Freq, regs.noise|audio.NoiseRestart)
+		}
+	}
+}
+
+// noteRegs returns the register values that play a single note of the sound effect
+func noteRegs(sfx *SFX, hz int) dmgRegs {
+	regs := dmgRegs{pending: true}
+	if hz <= 0 {
+		// a rest is played as a silent note so the previous note is stopped
+		return regs
+	}
+
+	env := memmap.SoundEnvelope(clampInt(sfx.Envelope.Volume, 0, 15)) << audio.EnvelopeVolumeShift
+	env |= memmap.SoundEnvelope(clampInt(sfx.Envelope.Step, 0, 7)) << audio.EnvelopeStepShift
+	if sfx.Envelope.Up {
+		env |= audio.EnvelopeUp
+	}
+
+	var timed memmap.SoundFreq
+	if sfx.Length > 0 {
+		timed = audio.SoundTimed
+		env |= memmap.SoundEnvelope(64-clampInt(sfx.Length, 1, 64)) & audio.LengthMask
+	}
+
+	switch sfx.Channel {
+	case ChannelSweep, ChannelSquare:
+		regs.envelope = env | dmgDuty[sfx.Duty&3]
+		regs.freq = toneFreq(hz) | timed
+
+		regs.sweep = memmap.ToneSweep(clampInt(sfx.Sweep.Time, 0, 7)) << audio.SweepTimeShift
+		regs.sweep |= memmap.ToneSweep(clampInt(sfx.Sweep.Shift, 0, 7))
+		if sfx.Sweep.Down {
+			regs.sweep |= audio.SweepDown
+		}
+	case ChannelWave:
+		regs.wave = sfx.Wave
+		regs.freq = waveFreq(hz) | timed
+		regs.volume = waveVolume(sfx.Envelope.Volume)
+		if sfx.Length > 0 {
+			regs.volume |= memmap.WaveVolume(256-clampInt(sfx.Length, 1, 256)) & audio.WaveLengthMask
+		}
+	case ChannelNoise:
+		regs.envelope = env
+		regs.noise = noiseFreq(hz)
+		if sfx.Metallic {
+			regs.noise |= audio.Noise7
+		}
+		if sfx.Length > 0 {
+			regs.noise |= audio.NoiseTimed
+		}
+	}
+
+	return regs
+}
+
+// toneFreq converts a frequency in Hz into a square wave channel frequency register value
+func toneFreq(hz int) memmap.SoundFreq {
+	return memmap.SoundFreq(clampInt(2048-131072/hz, 0, 2047))
+}
+
+// waveFreq converts a frequency in Hz into a wave channel frequency register value,
+// the wave channel plays all 32 samples once per wave
+func waveFreq(hz int) memmap.SoundFreq {
+	return memmap.SoundFreq(clampInt(2048-65536/hz, 0, 2047))
+}
+
+// waveVolume converts an envelope volume (0 - 15) into the closest wave channel volume
+func waveVolume(volume int) memmap.WaveVolume {
+	switch {
+	case volume <= 0:
+		return audio.WaveVolume0
+	case volume < 6:
+		return audio.WaveVolume25
+	case volume < 10:
+		return audio.WaveVolume50
+	case volume < 13:
+		return audio.WaveVolume75
+	default:
+		return audio.WaveVolume100
+	}
+}
+
+// noiseFreq finds the noise channel dividing ratio and shift that generates noise closest to hz
+func noiseFreq(hz int) memmap.NoiseFreq {
+	var best memmap.NoiseFreq
+	bestDiff := -1
+	for shift := 0; shift < 14; shift++ {
+		for ratio := 0; ratio < 8; ratio++ {
+			// a ratio of 0 is treated as 0.5 by the hardware
+			freq := 1048576 >> (shift + 1)
+			if ratio > 0 {
+				freq = 524288 / ratio >> (shift + 1)
+			}
+
+			diff := freq - hz
+			if diff < 0 {
+				diff = -diff
+			}
+
+			if bestDiff < 0 || diff < bestDiff {
+				bestDiff = diff
+				best = memmap.NoiseFreq(shift)<<audio.NoiseShiftShift | memmap.NoiseFreq(ratio)
+			}
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,181 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/audio"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+func Test_toneFreq(t *testing.T) {
+	tests := []struct {
+		name string
+		hz   int
+		want memmap.SoundFreq
+	}{
+		{"A4", 440, 1751},
+		{"A5", 880, 1900},
+		{"lowest note", 64, 0},
+		{"too low", 32, 0},
+		{"too high", 262144, 2047},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toneFreq(tt.hz); got != tt.want {
+				t.Errorf("toneFreq() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_waveFreq(t *testing.T) {
+	tests := []struct {
+		name string
+		hz   int
+		want memmap.SoundFreq
+	}{
+		{"A4", 440, 1900},
+		{"lowest note", 32, 0},
+		{"too low", 16, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := waveFreq(tt.hz); got != tt.want {
+				t.Errorf("waveFreq() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_noiseFreq(t *testing.T) {
+	tests := []struct {
+		name string
+		hz   int
+		want memmap.NoiseFreq
+	}{
+		{"highest", 1048576, 0x00},
+		{"ratio 1", 262144, 0x01},
+		{"ratio 3", 87381, 0x03},
+		{"ratio 4", 16384, 0x24},
+		{"lowest", 1, 0xD7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := noiseFreq(tt.hz); got != tt.want {
+				t.Errorf("noiseFreq() = %#x, want %#x", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_noteRegs(t *testing.T) {
+	tests := []struct {
+		name string
+		sfx  *SFX
+		hz   int
+		want dmgRegs
+	}{
+		{
+			"rest",
+			&SFX{Channel: ChannelSquare, Envelope: Envelope{Volume: 15}},
+			0,
+			dmgRegs{pending: true},
+		},
+		{
+			"square",
+			&SFX{Channel: ChannelSquare, Duty: Duty25, Envelope: Envelope{Volume: 10, Step: 2, Up: true}},
+			440,
+			dmgRegs{
+				pending:  true,
+				envelope: 10<<audio.EnvelopeVolumeShift | 2<<audio.EnvelopeStepShift | audio.EnvelopeUp | audio.Duty25,
+				freq:     1751,
+			},
+		},
+		{
+			"sweep",
+			&SFX{Channel: ChannelSweep, Length: 16, Envelope: Envelope{Volume: 8}, Sweep: Sweep{Time: 3, Shift: 2, Down: true}},
+			440,
+			dmgRegs{
+				pending:  true,
+				sweep:    3<<audio.SweepTimeShift | 2 | audio.SweepDown,
+				envelope: 8<<audio.EnvelopeVolumeShift | audio.Duty50 | 48,
+				freq:     1751 | audio.SoundTimed,
+			},
+		},
+		{
+			"wave",
+			&SFX{Channel: ChannelWave, Length: 64, Envelope: Envelope{Volume: 7}, Wave: [16]byte{0x01, 0x23}},
+			440,
+			dmgRegs{
+				pending: true,
+				freq:    1900 | audio.SoundTimed,
+				volume:  audio.WaveVolume50 | 192,
+				wave:    [16]byte{0x01, 0x23},
+			},
+		},
+		{
+			"noise",
+			&SFX{Channel: ChannelNoise, Length: 1, Metallic: true, Envelope: Envelope{Volume: 15, Step: 1}},
+			16384,
+			dmgRegs{
+				pending:  true,
+				envelope: 15<<audio.EnvelopeVolumeShift | 1<<audio.EnvelopeStepShift | 63,
+				noise:    0x24 | audio.Noise7 | audio.NoiseTimed,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := noteRegs(tt.sfx, tt.hz); got != tt.want {
+				t.Errorf("noteRegs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_drawSFX(t *testing.T) {
+	e := &Engine{}
+	sfx := &SFX{Channel: ChannelSquare, Notes: []int{440, 880}, NoteFrames: 2, Envelope: Envelope{Volume: 15}}
+	e.PlaySFX(sfx)
+
+	// the last frame silences the channel once the sfx is over
+	tests := []struct {
+		pending bool
+		freq    memmap.SoundFreq
+	}{
+		{true, 1751},
+		{false, 0},
+		{true, 1900},
+		{false, 0},
+		{true, 0},
+	}
+	for frame, tt := range tests {
+		e.drawSFX()
+
+		regs := e.dmg[ChannelSquare]
+		if regs.pending != tt.pending || (regs.pending && regs.freq != tt.freq) {
+			t.Errorf("Engine.drawSFX() frame %d = %+v, want pending %v freq %v", frame, regs, tt.pending, tt.freq)
+		}
+
+		// clear pending like flushSFX would
+		e.dmg[ChannelSquare].pending = false
+	}
+
+	if e.sfx[ChannelSquare].sfx != nil {
+		t.Errorf("Engine.drawSFX() sfx is still playing after its last note")
+	}
+}
+
+func TestEngine_StopSFX(t *testing.T) {
+	e := &Engine{}
+	e.PlaySFX(&SFX{Channel: ChannelNoise, Notes: []int{1000}, Envelope: Envelope{Volume: 15}})
+	e.drawSFX()
+	e.StopSFX(ChannelNoise)
+
+	if e.sfx[ChannelNoise].sfx != nil {
+		t.Errorf("Engine.StopSFX() sfx is still playing")
+	}
+	if got := e.dmg[ChannelNoise]; got != (dmgRegs{pending: true}) {
+		t.Errorf("Engine.StopSFX() = %+v, want silent pending registers", got)
+	}
+}
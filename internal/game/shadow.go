@@ -0,0 +1,117 @@
+package game
+
+import (
+	"unsafe"
+
+	hw_display "github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/dma"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+// shadowRegs are the display registers the engine draws into each frame. They're coppied into the hardware
+// registers by flush so the display registers only change durring VBlank
+type shadowRegs struct {
+	displayControll memmap.DisplayControll
+
+	bgControll [4]memmap.BGControll
+	bgHOffset  [4]uint16
+	bgVOffset  [4]uint16
+
+	winH   [2]memmap.WindowH
+	winV   [2]memmap.WindowV
+	winIn  memmap.WindowControll
+	winOut memmap.WindowControll
+
+	blendControll memmap.BlendControll
+	blendAlpha    memmap.BlendAlpha
+	blendY        memmap.BlendY
+
+	mosaic memmap.MosaicSize
+}
+
+// bgRegs are the hardware registers for each background
+var bgRegs = [4]struct {
+	controll         *memmap.BGControll
+	hOffset, vOffset *uint16
+}{
+	{hw_display.BG0Controll, hw_display.BG0HOffset, hw_display.BG0VOffset},
+	{hw_display.BG1Controll, hw_display.BG1HOffset, hw_display.BG1VOffset},
+	{hw_display.BG2Controll, hw_display.BG2HOffset, hw_display.BG2VOffset},
+	{hw_display.BG3Controll, hw_display.BG3HOffset, hw_display.BG3VOffset},
+}
+
+// FlushMisses returns the number of frames where the engine finished flushing after VBlank had ended.
+// Each miss is a frame that may have been drawn with tearing
+func (e *Engine) FlushMisses() int {
+	return e.flushMisses
+}
+
+// flush restarts the sound stream, starts any new DMG notes and copies the shadow palette, OAM and display registers into the hardware in a single burst.
+// It must be called as soon as the screen enters VBlank
+func (e *Engine) flush() {
+	e.profiler.begin(ProfileFlush, clock())
+
+	// the sound is flushed first so direct sound never runs out of samples
+	e.flushSound()
+	e.flushSFX()
+
+	e.profiler.begin(ProfilePalette, clock())
+	e.flushPalette()
+	e.profiler.end(clock())
+
+	e.profiler.begin(ProfileSprites, clock())
+	e.flushSprites()
+	e.profiler.end(clock())
+
+	e.flushRegs()
+
+	// the rasters must be flushed last since they overwrite the registers for the first scan line
+	e.flushRasters()
+
+	if memmap.GetReg(hw_display.VCount) < hw_display.Height {
+		e.flushMisses++
+	}
+
+	e.profiler.end(clock())
+	e.profiler.finish()
+}
+
+// flushPalette copies the shadow palette into palette memory if it has changed since the last flush
+func (e *Engine) flushPalette() {
+	if !e.palDirty {
+		return
+	}
+
+	dma.Copy32(memmap.Palette, e.palShadow[:])
+	e.palDirty = false
+}
+
+// flushSprites copies the OAM buffer into OAM memory
+func (e *Engine) flushSprites() {
+	oam := unsafe.Slice((*memmap.OAMValue)(unsafe.Pointer(&e.oamBuff[0])), len(memmap.OAM))
+	dma.Copy32(memmap.OAM, oam)
+}
+
+// flushRegs copies the shadow display registers into the hardware registers
+func (e *Engine) flushRegs() {
+	for i, regs := range bgRegs {
+		memmap.SetReg(regs.controll, e.regs.bgControll[i])
+		memmap.SetReg(regs.hOffset, e.regs.bgHOffset[i])
+		memmap.SetReg(regs.vOffset, e.regs.bgVOffset[i])
+	}
+
+	memmap.SetReg(hw_display.Win0H, e.regs.winH[0])
+	memmap.SetReg(hw_display.Win0V, e.regs.winV[0])
+	memmap.SetReg(hw_display.Win1H, e.regs.winH[1])
+	memmap.SetReg(hw_display.Win1V, e.regs.winV[1])
+	memmap.SetReg(hw_display.WinIn, e.regs.winIn)
+	memmap.SetReg(hw_display.WinOut, e.regs.winOut)
+
+	memmap.SetReg(hw_display.BlendControll, e.regs.blendControll)
+	memmap.SetReg(hw_display.BlendAlpha, e.regs.blendAlpha)
+	memmap.SetReg(hw_display.BlendY, e.regs.blendY)
+
+	memmap.SetReg(hw_display.MosaicSize, e.regs.mosaic)
+
+	memmap.SetReg(hw_display.Controll, e.regs.displayControll)
+}
@@ -0,0 +1,77 @@
+//go:build standalone
+
+package game
+
+import (
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/alloc"
+	hw_display "github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+func TestEngine_flush(t *testing.T) {
+	tests := []struct {
+		name       string
+		vCount     memmap.DisplayVCount
+		wantMisses int
+	}{
+		{
+			"start of VBlank",
+			hw_display.Height,
+			0,
+		},
+		{
+			"end of VBlank",
+			227,
+			0,
+		},
+		{
+			"missed VBlank",
+			10,
+			1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memmap.SetReg(hw_display.BlendControll, 0)
+			memmap.SetReg(hw_display.MosaicSize, 0)
+			memmap.Palette[1] = 0
+
+			e := &Engine{}
+			e.bgPalAlloc = alloc.NewPal(e.palBuff[:256])
+			e.sprPalAlloc = alloc.NewPal(e.palBuff[256:])
+			e.blendControll = hw_display.BlendModeAlpha
+			e.mosaic = 0x0011
+			e.palBuff[1] = White
+			e.doFade = true
+
+			e.Draw()
+			if got := memmap.GetReg(hw_display.BlendControll); got != 0 {
+				t.Errorf("Engine.Draw() changed the blend register to %#04x before the flush", got)
+			}
+			if got := memmap.GetReg(hw_display.MosaicSize); got != 0 {
+				t.Errorf("Engine.Draw() changed the mosaic register to %#04x before the flush", got)
+			}
+			if got := memmap.Palette[1]; got != 0 {
+				t.Errorf("Engine.Draw() changed the palette to %#04x before the flush", got)
+			}
+
+			memmap.SetReg(hw_display.VCount, tt.vCount)
+			e.flush()
+
+			if got := memmap.GetReg(hw_display.BlendControll); got != hw_display.BlendModeAlpha {
+				t.Errorf("Engine.flush() blend register = %#04x, want %#04x", got, hw_display.BlendModeAlpha)
+			}
+			if got := memmap.GetReg(hw_display.MosaicSize); got != 0x0011 {
+				t.Errorf("Engine.flush() mosaic register = %#04x, want %#04x", got, 0x0011)
+			}
+			if got := memmap.Palette[1]; got != White {
+				t.Errorf("Engine.flush() palette = %#04x, want %#04x", got, White)
+			}
+			if got := e.FlushMisses(); got != tt.wantMisses {
+				t.Errorf("Engine.FlushMisses() = %d, want %d", got, tt.wantMisses)
+			}
+		})
+	}
+}
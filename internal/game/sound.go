@@ -0,0 +1,158 @@
+package game
+
+import (
+	"unsafe"
+
+	"github.com/bjatkin/flappy_boot/internal/assets"
+	"github.com/bjatkin/flappy_boot/internal/hardware/audio"
+	"github.com/bjatkin/flappy_boot/internal/hardware/dma"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	"github.com/bjatkin/flappy_boot/internal/hardware/timer"
+	"github.com/bjatkin/flappy_boot/internal/math"
+)
+
+const (
+	// SampleRate is the number of sound samples played each second
+	SampleRate = 18157
+
+	// samplesPerFrame is the number of samples played each frame. At SampleRate exactly 304 samples are played
+	// between each VBlank so the sound buffers line up with the frames
+	samplesPerFrame = 304
+
+	// sampleCycles is the number of cpu cycles between each sample
+	sampleCycles = dma.ScreenRefresh / samplesPerFrame
+
+	// maxVoices is the number of sounds that can be mixed together at once
+	maxVoices = 4
+
+	// soundDMA is the DMA channel that streams the mixed sound into the direct sound A fifo
+	soundDMA = 1
+)
+
+// Voice plays a single sound, all the engines voices are mixed together each frame
+type Voice struct {
+	sound *assets.Sound
+
+	// pos is the position of the next sample, it's fractional so sounds can be played at different rates
+	pos math.Fix8
+	seq int
+
+	// Volume is the volume of the voice, at 0 the voice is silent and at 1 the sound is played unchanged
+	Volume math.Fix8
+
+	// Rate is the playback rate of the voice, at 1 the sound is played at SampleRate and at 2 the sound is
+	// played an octave higher
+	Rate math.Fix8
+}
+
+// PlaySound plays the sound on a free voice and returns the voice so it's volume and rate can be changed.
+// The voices rate starts at the rate that plays the sound at it's own sample rate.
+// If every voice is already playing, the voice that started playing first is stopped and reused
+func (e *Engine) PlaySound(sound *assets.Sound) *Voice {
+	voice := &e.voices[0]
+	for i := range e.voices[:maxVoices] {
+		if !e.voices[i].Playing() {
+			voice = &e.voices[i]
+			break
+		}
+
+		if e.voices[i].seq < voice.seq {
+			voice = &e.voices[i]
+		}
+	}
+
+	// sounds with their own sample rate are resampled as they're mixed
+	rate := math.FixOne
+	if sound.Rate() > 0 {
+		rate = math.Fix8(sound.Rate() * int(math.FixOne) / SampleRate)
+	}
+
+	e.voiceSeq++
+	*voice = Voice{
+		sound:  sound,
+		seq:    e.voiceSeq,
+		Volume: math.FixOne,
+		Rate:   rate,
+	}
+
+	return voice
+}
+
+// Stop stops the voice from playing
+func (v *Voice) Stop() {
+	v.sound = nil
+}
+
+// Playing returns true if the voice is still playing a sound
+func (v *Voice) Playing() bool {
+	return v.sound != nil
+}
+
+// initSound turns on direct sound A and the DMG channels and starts timer 0 ticking once per sample.
+// Direct sound A plays a new sample from it's fifo every time timer 0 overflows
+func (e *Engine) initSound() {
+	memmap.SetReg(audio.Stat, audio.MasterSoundEnable)
+	memmap.SetReg(audio.DSControll, audio.Dmg100|audio.A100|audio.AREnable|audio.ALEnable|audio.ATimer0|audio.AReset)
+	memmap.SetReg(audio.DMGControll, 7<<audio.RightVolumeShift|7<<audio.LeftVolumeShift|
+		audio.Sound1R|audio.Sound2R|audio.Sound3R|audio.Sound4R|
+		audio.Sound1L|audio.Sound2L|audio.Sound3L|audio.Sound4L,
+	)
+
+	memmap.SetReg(timer.Counter0, memmap.TimerCounter(0x1_0000-sampleCycles))
+	memmap.SetReg(timer.Controll0, timer.Freq1|timer.TimerStart)
+}
+
+// stopSound stops the sound DMA and timer 0 and turns off master sound so nothing keeps playing once
+// the game stops mixing new samples
+func (e *Engine) stopSound() {
+	dma.StopStream(soundDMA)
+	memmap.SetReg(timer.Controll0, timer.TimerStop)
+	memmap.SetReg(audio.DSControll, audio.AReset)
+	memmap.SetReg(audio.Stat, audio.MasterSoundDisable)
+}
+
+// mixSound mixes all the playing voices into the back sound buffer, the buffer starts playing on the next flush
+func (e *Engine) mixSound() {
+	buff := unsafe.Slice((*int8)(unsafe.Pointer(&e.soundBuff[e.soundPage][0])), samplesPerFrame)
+	mix(buff, e.voices[:])
+}
+
+// flushSound restarts the sound DMA with the back sound buffer and swaps the sound buffers.
+// The fifo is reset first so the samples the DMA read past the end of the last buffer are not played
+func (e *Engine) flushSound() {
+	memmap.SetReg(audio.DSControll, memmap.GetReg(audio.DSControll)|audio.AReset)
+	dma.Stream(soundDMA, audio.FIFOA, e.soundBuff[e.soundPage][:])
+	e.soundPage ^= 1
+}
+
+// mix mixes the voices together into buff, voices that reach the end of their sound are stopped
+func mix(buff []int8, voices []Voice) {
+	var acc [samplesPerFrame]int
+	for i := range voices {
+		v := &voices[i]
+		if !v.Playing() {
+			continue
+		}
+
+		samples := v.sound.Samples()
+		loop := v.sound.Loop()
+		for j := range buff {
+			if v.pos.Int() >= len(samples) {
+				if loop < 0 || loop >= len(samples) {
+					v.Stop()
+					break
+				}
+				// the rate can be longer than the loop so the position is wrapped into the loop and not just moved back
+				start := math.NewFix8(loop, 0)
+				v.pos = start + (v.pos-start)%math.NewFix8(len(samples)-loop, 0)
+			}
+
+			acc[j] += int(samples[v.pos.Int()]) * int(v.Volume) >> 8
+			v.pos += v.Rate
+		}
+	}
+
+	for i := range buff {
+		buff[i] = int8(clampInt(acc[i], -128, 127))
+	}
+}
@@ -0,0 +1,111 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/assets"
+	"github.com/bjatkin/flappy_boot/internal/math"
+)
+
+func Test_mix(t *testing.T) {
+	ramp := assets.NewSound([]int8{10, 20, 30, 40}, -1)
+	loud := assets.NewSound([]int8{100, -100, 100, -100}, -1)
+	loop := assets.NewSound([]int8{1, 2, 3}, 1)
+	short := assets.NewSound([]int8{1, 2, 3, 4, 5}, 3)
+
+	tests := []struct {
+		name        string
+		voices      []Voice
+		want        []int8
+		wantPlaying []bool
+	}{
+		{
+			"silence",
+			[]Voice{{}},
+			[]int8{0, 0, 0, 0, 0, 0},
+			[]bool{false},
+		},
+		{
+			"single voice",
+			[]Voice{{sound: ramp, Volume: math.FixOne, Rate: math.FixOne}},
+			[]int8{10, 20, 30, 40, 0, 0},
+			[]bool{false},
+		},
+		{
+			"half volume",
+			[]Voice{{sound: ramp, Volume: math.FixHalf, Rate: math.FixOne}},
+			[]int8{5, 10, 15, 20, 0, 0},
+			[]bool{false},
+		},
+		{
+			"double rate",
+			[]Voice{{sound: ramp, Volume: math.FixOne, Rate: math.FixOne * 2}},
+			[]int8{10, 30, 0, 0, 0, 0},
+			[]bool{false},
+		},
+		{
+			"voices are clamped",
+			[]Voice{
+				{sound: loud, Volume: math.FixOne, Rate: math.FixOne},
+				{sound: loud, Volume: math.FixOne, Rate: math.FixOne},
+			},
+			[]int8{127, -128, 127, -128, 0, 0},
+			[]bool{false, false},
+		},
+		{
+			"loop",
+			[]Voice{{sound: loop, Volume: math.FixOne, Rate: math.FixOne}},
+			[]int8{1, 2, 3, 2, 3, 2},
+			[]bool{true},
+		},
+		{
+			"rate longer than the loop",
+			[]Voice{{sound: short, Volume: math.FixOne, Rate: math.FixOne * 5}},
+			[]int8{1, 4, 5, 4, 5, 4},
+			[]bool{true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]int8, len(tt.want))
+			mix(got, tt.voices)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mix() = %v, want %v", got, tt.want)
+			}
+
+			// mix a second buffer so voices that ended exactly at the end of the first buffer are stopped
+			mix(make([]int8, len(tt.want)), tt.voices)
+			for i, v := range tt.voices {
+				if v.Playing() != tt.wantPlaying[i] {
+					t.Errorf("mix() voice %d playing = %v, want %v", i, v.Playing(), tt.wantPlaying[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEngine_PlaySound(t *testing.T) {
+	e := &Engine{}
+	sound := assets.NewSound([]int8{1}, -1)
+
+	var voices []*Voice
+	for i := 0; i < maxVoices; i++ {
+		voices = append(voices, e.PlaySound(sound))
+	}
+
+	for i := 1; i < len(voices); i++ {
+		if voices[i] == voices[0] {
+			t.Fatalf("Engine.PlaySound() reused voice 0 while there were free voices")
+		}
+	}
+
+	voices[2].Stop()
+	if got := e.PlaySound(sound); got != voices[2] {
+		t.Errorf("Engine.PlaySound() did not use the stopped voice")
+	}
+
+	if got := e.PlaySound(sound); got != voices[0] {
+		t.Errorf("Engine.PlaySound() did not reuse the oldest voice when all the voices were playing")
+	}
+}
@@ -26,8 +26,32 @@ type Sprite struct {
 	HFlip     bool
 	VFlip     bool
 	Priority  hw_sprite.Attr2
-	size      hw_sprite.Attr1
-	shape     hw_sprite.Attr0
+
+	// Z orders sprites with the same Priority, sprites with a higher Z are drawn on top.
+	// sprites with the same Priority and Z are drawn in the order they were shown with the most recent on top
+	Z int
+
+	// SemiTransparent alpha blends the sprite onto the layers below it, see Engine.Blend
+	SemiTransparent bool
+
+	// Window uses the sprite as part of the sprite window instead of drawing it, see Engine.NewSpriteWindow
+	Window bool
+
+	// Mosaic enables the mosaic effect for the sprite, see Engine.SetMosaic
+	Mosaic bool
+
+	// Affine enables rotation and scaling for the sprite. HFlip and VFlip are ignored by affine sprites
+	Affine bool
+	// Rotation is the clockwise rotation of an affine sprite in turns, math.FixOne is a full turn
+	Rotation math.Fix8
+	// Scale is the scale of an affine sprite, a scale of math.FixOne is the sprites normal size
+	Scale math.V2
+	// DoubleSize doubles the area an affine sprite is drawn in so it is not clipped when rotated or scaled up.
+	// the sprite stays centered at the same location on screen
+	DoubleSize bool
+
+	size  hw_sprite.Attr1
+	shape hw_sprite.Attr0
 
 	animation  []Frame
 	aniFrame   int
@@ -35,21 +59,43 @@ type Sprite struct {
 
 	tileSet *assets.TileSet
 	hwAttrs *hw_sprite.Attrs
+
+	// active is true if the sprite is in the engines list of active sprites
+	active bool
+	// seq is the order the sprite was shown in, it's used to keep the sprite order stable
+	seq int
 }
 
 // NewSprite returns a new Sprite
 func (e *Engine) NewSprite(tileSet *assets.TileSet) *Sprite {
-	return &Sprite{
+	s := &Sprite{
 		engine:  e,
 		tileSet: tileSet,
 		size:    tileSet.Size(),
 		shape:   tileSet.Shape(),
 		hwAttrs: &hw_sprite.Attrs{},
+		Scale:   math.V2{X: math.FixOne, Y: math.FixOne},
 	}
+	e.objects.track(s)
+
+	return s
 }
 
-func (s *Sprite) attrs() *hw_sprite.Attrs {
+// attrs converts the sprite into OAM attributes. affineIndex is the index of the sprites affine matrix,
+// if it's -1 the sprite is drawn as a regular sprite even if it's affine
+func (s *Sprite) attrs(affineIndex int) *hw_sprite.Attrs {
 	var hideAttr hw_sprite.Attr0
+	var effectAttr hw_sprite.Attr0
+	if s.SemiTransparent {
+		effectAttr = hw_sprite.Blend
+	}
+	if s.Window {
+		effectAttr = hw_sprite.Win
+	}
+	var mosaicAttr hw_sprite.Attr0
+	if s.Mosaic {
+		mosaicAttr = hw_sprite.Mosaic
+	}
 	var vFlipAttr hw_sprite.Attr1
 	if s.VFlip {
 		vFlipAttr = hw_sprite.HMirrior
@@ -60,14 +106,35 @@ func (s *Sprite) attrs() *hw_sprite.Attrs {
 	}
 
 	dest := math.AddV2(s.Pos, s.Offset)
+
+	var affineAttr0 hw_sprite.Attr0
+	var affineAttr1 hw_sprite.Attr1
+	if affineIndex >= 0 {
+		affineAttr0 = hw_sprite.Affine
+		affineAttr1 = hw_sprite.Attr1(affineIndex) << hw_sprite.AffineIndexShift
+
+		// flip bits are part of the affine index for affine sprites
+		vFlipAttr = 0
+		hFlipAttr = 0
+
+		if s.DoubleSize {
+			affineAttr0 = hw_sprite.AffineDBL
+
+			// move the sprite so it stays centered in the larger draw area
+			w, h := hw_sprite.Dimensions(s.shape, s.size)
+			dest.X -= math.FixOne * math.Fix8(w/2)
+			dest.Y -= math.FixOne * math.Fix8(h/2)
+		}
+	}
+
 	if dest.X < 0 {
 		dest.X += math.FixOne * 512
 	}
 	if dest.Y < 0 {
 		dest.Y += math.FixOne * 256
 	}
-	s.hwAttrs.Attr0 = hw_sprite.Attr0(dest.Y.Int()%256) | s.shape | hideAttr
-	s.hwAttrs.Attr1 = hw_sprite.Attr1(dest.X.Int()%512) | vFlipAttr | hFlipAttr | s.size
+	s.hwAttrs.Attr0 = hw_sprite.Attr0(dest.Y.Int()%256) | s.shape | hideAttr | effectAttr | mosaicAttr | affineAttr0
+	s.hwAttrs.Attr1 = hw_sprite.Attr1(dest.X.Int()%512) | vFlipAttr | hFlipAttr | affineAttr1 | s.size
 	s.hwAttrs.Attr2 = (hw_sprite.Attr2(s.TileIndex) + s.tileSet.Offset()) |
 		s.Priority |
 		s.tileSet.SprPalette()
@@ -89,16 +156,14 @@ func (s *Sprite) StopAnimation() {
 
 // Show adds the sprite to the list of active sprites.
 // if the sprites associated assets have not been loaded yet, Show will automatically attempt to load them.
-// all active sprites are drawn every frame, if more than 128 sprites are active at a time all active
-// sprites will be randomly flickered to ensure all sprites continue to be drawn
+// all active sprites are drawn every frame, if 128 sprites are already active an error will be returned
 func (s *Sprite) Show() error {
 	err := s.Load()
 	if err != nil {
 		return err
 	}
 
-	s.engine.addSprite(s)
-	return nil
+	return s.engine.addSprite(s)
 }
 
 // Hide removes the sprites from the list of active sprites.
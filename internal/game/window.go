@@ -0,0 +1,181 @@
+package game
+
+import (
+	"github.com/bjatkin/flappy_boot/internal/alloc"
+	hw_display "github.com/bjatkin/flappy_boot/internal/hardware/display"
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+	"github.com/bjatkin/flappy_boot/internal/math"
+)
+
+// Window masks the layers that are visible in an area of the screen. Regular windows cover the rectangle set by Bounds.
+// the sprite window covers every pixel of the active sprites that have Window set
+type Window struct {
+	// engine is a reference to the windows parent engine
+	engine *Engine
+	sprite bool
+
+	// Bounds is the area of the screen the window covers, the bottom and right edges are exclusive.
+	// Bounds is ignored by the sprite window
+	Bounds math.Rect
+
+	// Layers are the layers that are visible inside the window, the backdrop is always visible
+	Layers Layer
+
+	// Effects enables color effects inside the window, see Engine.Blend
+	Effects bool
+}
+
+// NewWindow returns a new rectangular Window. Only 2 rectangular windows can be shown at a time
+func (e *Engine) NewWindow(bounds math.Rect, layers Layer) *Window {
+	w := &Window{
+		engine: e,
+		Bounds: bounds,
+		Layers: layers,
+	}
+	e.objects.track(w)
+
+	return w
+}
+
+// NewSpriteWindow returns a new sprite Window. The shape of the window is made up of all the
+// active sprites with Window set. Only 1 sprite window can be shown at a time
+func (e *Engine) NewSpriteWindow(layers Layer) *Window {
+	w := &Window{
+		engine: e,
+		sprite: true,
+		Layers: layers,
+	}
+	e.objects.track(w)
+
+	return w
+}
+
+// Show adds the window to the list of active windows. If all the hardware windows are
+// already active an error will be returned
+func (w *Window) Show() error {
+	return w.engine.addWindow(w)
+}
+
+// Hide removes the window from the list of active windows
+func (w *Window) Hide() {
+	w.engine.removeWindow(w)
+}
+
+// controll returns the window controll bits for the window
+func (w *Window) controll() memmap.WindowControll {
+	return windowControll(w.Layers, w.Effects)
+}
+
+// SetWindowOutside sets the layers that are visible outside of all the active windows.
+// effects enables color effects outside of the windows, see Engine.Blend
+func (e *Engine) SetWindowOutside(layers Layer, effects bool) {
+	e.windowOutside = windowControll(layers, effects)
+}
+
+// windowControll converts layers into window controll bits
+func windowControll(layers Layer, effects bool) memmap.WindowControll {
+	// the backdrop is always visible so the backdrop bit is used by the effects flag
+	controll := memmap.WindowControll(layers &^ LayerBackdrop)
+	if effects {
+		controll |= hw_display.WinEffects
+	}
+
+	return controll
+}
+
+// addWindow adds a new window to the list of active windows
+func (e *Engine) addWindow(w *Window) error {
+	if w.sprite {
+		if e.spriteWindow != nil && e.spriteWindow != w {
+			return alloc.ErrOOM
+		}
+
+		e.spriteWindow = w
+		return nil
+	}
+
+	for i := range e.activeWindows {
+		if e.activeWindows[i] == w {
+			return nil
+		}
+	}
+
+	for i := range e.activeWindows {
+		if e.activeWindows[i] == nil {
+			e.activeWindows[i] = w
+			return nil
+		}
+	}
+
+	return alloc.ErrOOM
+}
+
+// removeWindow removes a window from the list of active windows
+func (e *Engine) removeWindow(w *Window) {
+	if e.spriteWindow == w {
+		e.spriteWindow = nil
+		return
+	}
+
+	for i := range e.activeWindows {
+		if e.activeWindows[i] == w {
+			e.activeWindows[i] = nil
+			return
+		}
+	}
+}
+
+// drawWindows updates the shadow window registers and the window bits in the shadow display controll register
+// based on the engines active windows
+func (e *Engine) drawWindows() {
+	controll := e.regs.displayControll &^ (hw_display.Win1 | hw_display.Win2 | hw_display.WinSpr)
+
+	var winIn memmap.WindowControll
+	for i, w := range e.activeWindows {
+		if w == nil {
+			continue
+		}
+
+		x1 := clampInt(w.Bounds.X1, 0, hw_display.Width)
+		x2 := clampInt(w.Bounds.X2, 0, hw_display.Width)
+		y1 := clampInt(w.Bounds.Y1, 0, hw_display.Height)
+		y2 := clampInt(w.Bounds.Y2, 0, hw_display.Height)
+		h := memmap.WindowH(x1)<<hw_display.WinLeftShift | memmap.WindowH(x2)
+		v := memmap.WindowV(y1)<<hw_display.WinTopShift | memmap.WindowV(y2)
+
+		switch i {
+		case 0:
+			controll |= hw_display.Win1
+			e.regs.winH[0] = h
+			e.regs.winV[0] = v
+			winIn |= w.controll()
+		case 1:
+			controll |= hw_display.Win2
+			e.regs.winH[1] = h
+			e.regs.winV[1] = v
+			winIn |= w.controll() << hw_display.WinShift
+		}
+	}
+
+	winOut := e.windowOutside
+	if e.spriteWindow != nil {
+		controll |= hw_display.WinSpr
+		winOut |= e.spriteWindow.controll() << hw_display.WinShift
+	}
+
+	e.regs.winIn = winIn
+	e.regs.winOut = winOut
+	e.regs.displayControll = controll
+}
+
+// clampInt clamps i between min and max
+func clampInt(i, min, max int) int {
+	if i < min {
+		return min
+	}
+	if i > max {
+		return max
+	}
+
+	return i
+}
@@ -0,0 +1,47 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+func Test_windowControll(t *testing.T) {
+	type args struct {
+		layers  Layer
+		effects bool
+	}
+	tests := []struct {
+		name string
+		args args
+		want memmap.WindowControll
+	}{
+		{
+			"no layers",
+			args{layers: 0, effects: false},
+			0x0000,
+		},
+		{
+			"backgrounds and sprites",
+			args{layers: LayerBG0 | LayerBG2 | LayerSprites, effects: false},
+			0x0015,
+		},
+		{
+			"backdrop is ignored",
+			args{layers: LayerAll, effects: false},
+			0x001F,
+		},
+		{
+			"effects",
+			args{layers: LayerBG1, effects: true},
+			0x0022,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := windowControll(tt.args.layers, tt.args.effects); got != tt.want {
+				t.Errorf("windowControll() = %#04x, want %#04x", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,17 +6,15 @@ import (
 	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
 )
 
-const (
-	// FIFOA is the 4 bit FIFO register for DMA sound data. It is used for direct sound channel A
-	// The data is treated as 8bit samples and is played in FIFO order with the least significant
-	// byte played first
-	FIFOA uintptr = 0x0400_00A0
-
-	// FIFOB is the 4 bit FIFO register for DMA sound data. It is used for direct sound channel B
-	// The data is treated as 8bit samples and is played in FIFO order with the least significant
-	// byte played first
-	FIFOB uintptr = 0x0400_00A4
-)
+// FIFOA is the 4 byte FIFO register for DMA sound data. It is used for direct sound channel A
+// The data is treated as signed 8bit samples and is played in FIFO order with the least significant
+// byte played first
+var FIFOA = (*memmap.SoundFIFO)(unsafe.Pointer(memmap.IOAddr + 0x00A0))
+
+// FIFOB is the 4 byte FIFO register for DMA sound data. It is used for direct sound channel B
+// The data is treated as signed 8bit samples and is played in FIFO order with the least significant
+// byte played first
+var FIFOB = (*memmap.SoundFIFO)(unsafe.Pointer(memmap.IOAddr + 0x00A4))
 
 // Stat is the controll register for enabling master sound. It also shows the status of the DMG
 // channels. Notes that bits 0 - 3 are read only
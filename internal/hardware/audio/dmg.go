@@ -0,0 +1,282 @@
+package audio
+
+import (
+	"unsafe"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+// DMGControll is the master controll register for the 4 DMG sound channels. The overall DMG volume is also
+// scaled by the DMG volume ratio in DSControll. It has the following layout
+//
+// [0 - 2] Right Volume - the volume of the DMG channels on the right speaker (0 - 7)
+//
+// [4 - 6] Left Volume - the volume of the DMG channels on the left speaker (0 - 7)
+//
+// [8 - B] Right Enable - turns on each DMG channel on the right speaker
+//   - Sound1R - enable sound 1 on the right speaker
+//   - Sound2R - enable sound 2 on the right speaker
+//   - Sound3R - enable sound 3 on the right speaker
+//   - Sound4R - enable sound 4 on the right speaker
+//
+// [C - F] Left Enable - turns on each DMG channel on the left speaker
+//   - Sound1L - enable sound 1 on the left speaker
+//   - Sound2L - enable sound 2 on the left speaker
+//   - Sound3L - enable sound 3 on the left speaker
+//   - Sound4L - enable sound 4 on the left speaker
+var DMGControll = (*memmap.DMGControll)(unsafe.Pointer(memmap.IOAddr + 0x0080))
+
+const (
+	// RightVolumeShift is the shift for the right volume in the DMGControll register
+	RightVolumeShift = 0
+
+	// LeftVolumeShift is the shift for the left volume in the DMGControll register
+	LeftVolumeShift = 4
+
+	// Sound1R enables sound 1 on the right speaker
+	Sound1R memmap.DMGControll = 0x0100
+
+	// Sound2R enables sound 2 on the right speaker
+	Sound2R memmap.DMGControll = 0x0200
+
+	// Sound3R enables sound 3 on the right speaker
+	Sound3R memmap.DMGControll = 0x0400
+
+	// Sound4R enables sound 4 on the right speaker
+	Sound4R memmap.DMGControll = 0x0800
+
+	// Sound1L enables sound 1 on the left speaker
+	Sound1L memmap.DMGControll = 0x1000
+
+	// Sound2L enables sound 2 on the left speaker
+	Sound2L memmap.DMGControll = 0x2000
+
+	// Sound3L enables sound 3 on the left speaker
+	Sound3L memmap.DMGControll = 0x4000
+
+	// Sound4L enables sound 4 on the left speaker
+	Sound4L memmap.DMGControll = 0x8000
+)
+
+// Sound1Sweep is the frequency sweep register for sound 1, the square wave channel with a sweep.
+// It has the following layout
+//
+// [0 - 2] Sweep Shift - the frequency changes by freq/2^shift at each sweep step. 0 turns the sweep off
+//
+// [3] Sweep Direction - sets the direction of the sweep
+//   - SweepUp - the frequency increases at each step
+//   - SweepDown - the frequency decreases at each step
+//
+// [4 - 6] Sweep Time - the time between each sweep step in 1/128ths of a second. 0 turns the sweep off
+var Sound1Sweep = (*memmap.ToneSweep)(unsafe.Pointer(memmap.IOAddr + 0x0060))
+
+// Sound1Envelope is the length, duty and envelope register for sound 1, see Sound2Envelope for the layout
+var Sound1Envelope = (*memmap.SoundEnvelope)(unsafe.Pointer(memmap.IOAddr + 0x0062))
+
+// Sound1Freq is the frequency and controll register for sound 1, see Sound2Freq for the layout
+var Sound1Freq = (*memmap.SoundFreq)(unsafe.Pointer(memmap.IOAddr + 0x0064))
+
+// Sound2Envelope is the length, duty and envelope register for sound 2, the square wave channel.
+// It has the following layout
+//
+// [0 - 5] Sound Length - the sound plays for (64 - length)/256 seconds if SoundTimed is set in the frequency register
+//
+// [6 - 7] Wave Duty - the fraction of the square wave that is high
+//   - Duty12 - 12.5%
+//   - Duty25 - 25%
+//   - Duty50 - 50%
+//   - Duty75 - 75%
+//
+// [8 - A] Envelope Step Time - the time between each volume step in 1/64ths of a second. 0 turns the envelope off
+//
+// [B] Envelope Direction - sets the direction of the volume envelope
+//   - EnvelopeUp - the volume increases at each step
+//   - EnvelopeDown - the volume decreases at each step
+//
+// [C - F] Initial Volume - the starting volume of the envelope (0 - 15)
+var Sound2Envelope = (*memmap.SoundEnvelope)(unsafe.Pointer(memmap.IOAddr + 0x0068))
+
+// Sound2Freq is the frequency and controll register for sound 2. It has the following layout
+//
+// [0 - A] Frequency - the sound plays at 131072/(2048 - freq) Hz. The wave channel plays at 65536/(2048 - freq) Hz
+//
+// [E] Length Flag - sets whether the sound stops once its length runs out
+//   - SoundTimed - stop the sound once its length runs out
+//   - SoundContinuous - play the sound until it's stopped
+//
+// [F] Restart - restarts the sound with the current settings
+//   - SoundRestart - restart the sound
+var Sound2Freq = (*memmap.SoundFreq)(unsafe.Pointer(memmap.IOAddr + 0x006C))
+
+// Sound3Controll is the controll register for sound 3, the wave channel. It has the following layout
+//
+// [5] Wave Bank Size - sets how much wave ram is played
+//   - WaveBank32 - play one 32 sample bank
+//   - WaveBank64 - play both banks as one 64 sample wave
+//
+// [6] Wave Bank Select - selects the bank that is played, the other bank can be written to through WaveRAM
+//   - WaveBank0 - play bank 0
+//   - WaveBank1 - play bank 1
+//
+// [7] Sound Enable - turns the wave channel on and off
+//   - WaveOn - turn the wave channel on
+//   - WaveOff - turn the wave channel off
+var Sound3Controll = (*memmap.WaveControll)(unsafe.Pointer(memmap.IOAddr + 0x0070))
+
+// Sound3Volume is the length and volume register for sound 3. It has the following layout
+//
+// [0 - 7] Sound Length - the sound plays for (256 - length)/256 seconds if SoundTimed is set in the frequency register
+//
+// [D - F] Volume - the volume the wave is played at
+//   - WaveVolume0 - mute
+//   - WaveVolume25 - 25% volume
+//   - WaveVolume50 - 50% volume
+//   - WaveVolume75 - 75% volume
+//   - WaveVolume100 - 100% volume
+var Sound3Volume = (*memmap.WaveVolume)(unsafe.Pointer(memmap.IOAddr + 0x0072))
+
+// Sound3Freq is the frequency and controll register for sound 3, see Sound2Freq for the layout
+var Sound3Freq = (*memmap.SoundFreq)(unsafe.Pointer(memmap.IOAddr + 0x0074))
+
+// Sound4Envelope is the length and envelope register for sound 4, the noise channel. It has the same layout as
+// Sound2Envelope except the wave duty bits are unused
+var Sound4Envelope = (*memmap.SoundEnvelope)(unsafe.Pointer(memmap.IOAddr + 0x0078))
+
+// Sound4Freq is the frequency and controll register for sound 4. It has the following layout
+//
+// [0 - 2] Dividing Ratio - the noise is generated at 524288/ratio/2^(shift+1) Hz, a ratio of 0 is treated as 0.5
+//
+// [3] Counter Width - sets the width of the noise generator
+//   - Noise15 - 15 bit noise, this sounds like white noise
+//   - Noise7 - 7 bit noise, this sounds more metallic
+//
+// [4 - 7] Shift Clock Frequency - see Dividing Ratio
+//
+// [E] Length Flag - see Sound2Freq
+//
+// [F] Restart - see Sound2Freq
+var Sound4Freq = (*memmap.NoiseFreq)(unsafe.Pointer(memmap.IOAddr + 0x007C))
+
+// waveRAMStart is needed to prevent tinygo from failing
+var waveRAMStart = (*uint16)(unsafe.Pointer(memmap.IOAddr + 0x0090))
+
+// WaveRAM is the wave pattern ram for sound 3, it holds 32 4 bit samples with the high nibble of each byte played first.
+// Writes go to the bank that is not selected in Sound3Controll
+var WaveRAM = unsafe.Slice(waveRAMStart, 8)
+
+const (
+	// SweepShiftMask masks out the sweep shift in the Sound1Sweep register
+	SweepShiftMask memmap.ToneSweep = 0x0007
+
+	// SweepUp sets the sweep to increase the frequency
+	SweepUp memmap.ToneSweep = 0x0000
+
+	// SweepDown sets the sweep to decrease the frequency
+	SweepDown memmap.ToneSweep = 0x0008
+
+	// SweepTimeShift is the shift for the sweep time in the Sound1Sweep register
+	SweepTimeShift = 4
+)
+
+const (
+	// LengthMask masks out the sound length in the envelope registers
+	LengthMask memmap.SoundEnvelope = 0x003F
+
+	// Duty12 sets the square wave duty to 12.5%
+	Duty12 memmap.SoundEnvelope = 0x0000
+
+	// Duty25 sets the square wave duty to 25%
+	Duty25 memmap.SoundEnvelope = 0x0040
+
+	// Duty50 sets the square wave duty to 50%
+	Duty50 memmap.SoundEnvelope = 0x0080
+
+	// Duty75 sets the square wave duty to 75%
+	Duty75 memmap.SoundEnvelope = 0x00C0
+
+	// EnvelopeStepShift is the shift for the envelope step time in the envelope registers
+	EnvelopeStepShift = 8
+
+	// EnvelopeDown sets the envelope to decrease the volume
+	EnvelopeDown memmap.SoundEnvelope = 0x0000
+
+	// EnvelopeUp sets the envelope to increase the volume
+	EnvelopeUp memmap.SoundEnvelope = 0x0800
+
+	// EnvelopeVolumeShift is the shift for the initial volume in the envelope registers
+	EnvelopeVolumeShift = 12
+)
+
+const (
+	// FreqMask masks out the frequency in the frequency registers
+	FreqMask memmap.SoundFreq = 0x07FF
+
+	// SoundContinuous plays the sound until it's stopped
+	SoundContinuous memmap.SoundFreq = 0x0000
+
+	// SoundTimed stops the sound once its length runs out
+	SoundTimed memmap.SoundFreq = 0x4000
+
+	// SoundRestart restarts the sound
+	SoundRestart memmap.SoundFreq = 0x8000
+)
+
+const (
+	// WaveBank32 plays a single 32 sample bank of wave ram
+	WaveBank32 memmap.WaveControll = 0x0000
+
+	// WaveBank64 plays both banks of wave ram as a single 64 sample wave
+	WaveBank64 memmap.WaveControll = 0x0020
+
+	// WaveBank0 plays bank 0 of wave ram, wave ram writes go to bank 1
+	WaveBank0 memmap.WaveControll = 0x0000
+
+	// WaveBank1 plays bank 1 of wave ram, wave ram writes go to bank 0
+	WaveBank1 memmap.WaveControll = 0x0040
+
+	// WaveOff turns the wave channel off
+	WaveOff memmap.WaveControll = 0x0000
+
+	// WaveOn turns the wave channel on
+	WaveOn memmap.WaveControll = 0x0080
+)
+
+const (
+	// WaveLengthMask masks out the sound length in the Sound3Volume register
+	WaveLengthMask memmap.WaveVolume = 0x00FF
+
+	// WaveVolume0 mutes the wave channel
+	WaveVolume0 memmap.WaveVolume = 0x0000
+
+	// WaveVolume100 plays the wave channel at 100% volume
+	WaveVolume100 memmap.WaveVolume = 0x2000
+
+	// WaveVolume50 plays the wave channel at 50% volume
+	WaveVolume50 memmap.WaveVolume = 0x4000
+
+	// WaveVolume25 plays the wave channel at 25% volume
+	WaveVolume25 memmap.WaveVolume = 0x6000
+
+	// WaveVolume75 plays the wave channel at 75% volume
+	WaveVolume75 memmap.WaveVolume = 0x8000
+)
+
+const (
+	// NoiseRatioMask masks out the dividing ratio in the Sound4Freq register
+	NoiseRatioMask memmap.NoiseFreq = 0x0007
+
+	// Noise15 uses 15 bit noise
+	Noise15 memmap.NoiseFreq = 0x0000
+
+	// Noise7 uses 7 bit noise
+	Noise7 memmap.NoiseFreq = 0x0008
+
+	// NoiseShiftShift is the shift for the shift clock frequency in the Sound4Freq register
+	NoiseShiftShift = 4
+
+	// NoiseTimed stops the noise once its length runs out
+	NoiseTimed memmap.NoiseFreq = 0x4000
+
+	// NoiseRestart restarts the noise
+	NoiseRestart memmap.NoiseFreq = 0x8000
+)
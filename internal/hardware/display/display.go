@@ -93,12 +93,18 @@ const (
 	// Page Flipping:  Yes
 	Mode5 memmap.DisplayControll = 0x0005
 
+	// ModeMask masks out all the bits that are not part of the display mode
+	ModeMask memmap.DisplayControll = 0x0007
+
 	// PageA is the default video page used by Mode4 and Mode5
 	PageA memmap.DisplayControll = 0x0000
 
 	// PageB is the alternative video page used by Mode4 and Mode5
 	PageB memmap.DisplayControll = 0x0010
 
+	// PageOffset is the offset of PageB in VRAM in VRAMValues
+	PageOffset = 0x5000
+
 	// OAMHBlank allows the OAM(object attribute memory or sprite memory) to be updated
 	// durring an HBlank, the normal behavior of the GBA prevents any updates to this
 	// section of memory unless the screen is in the VBlank period.
@@ -130,10 +136,10 @@ const (
 	// Sprites enables the sprite layer (somtimes refered to as the object layer)
 	Sprites memmap.DisplayControll = 0x1000
 
-	// Win1 enables the first background masking window
+	// Win1 enables the first background masking window (window 0)
 	Win1 memmap.DisplayControll = 0x2000
 
-	// Win2 enables the second background masking window
+	// Win2 enables the second background masking window (window 1)
 	Win2 memmap.DisplayControll = 0x4000
 
 	// WinSpr enables the sprite masking window
@@ -347,13 +353,13 @@ const (
 	PriorityMask memmap.BGControll = 0x0003
 
 	// Mosaic enables the mosaic background effect
-	Mosaic memmap.BGControll = 0x0020
+	Mosaic memmap.BGControll = 0x0040
 
 	// Color16 sets the background to use 16 x 16 color palettes
 	Color16 memmap.BGControll = 0x0000
 
 	// Color256 sets the background to use 1 x256 color palettes
-	Color256 memmap.BGControll = 0x0040
+	Color256 memmap.BGControll = 0x0080
 
 	// BGSizeSmall sets the background size to 256 x 256 pixels
 	BGSizeSmall memmap.BGControll = 0x0000
@@ -387,3 +393,247 @@ const (
 	// PaletteShift shifts a number into the correct bits to set the palette id for an SBB tile
 	PaletteShift memmap.VRAMValue = 0x000C
 )
+
+// BlendControll is the color special effects controll register. It selects which layers are blended and how
+// they are blended. The first target layers are the top layers and the second target layers are the layers
+// directly below them. It is a R/W register with the following layout.
+//
+// [0 - 5] First Target Layers - the layers used as the first (top) target of the effect
+//   - BlendBG0 - background 0
+//   - BlendBG1 - background 1
+//   - BlendBG2 - background 2
+//   - BlendBG3 - background 3
+//   - BlendSprites - the sprite layer
+//   - BlendBackdrop - the backdrop color
+//
+// [6 - 7] Blend Mode - the color special effect to use
+//   - BlendModeOff - no special effect (default)
+//   - BlendModeAlpha - alpha blend the first and second targets together using the BlendAlpha register
+//   - BlendModeWhite - brighten the first targets towards white using the BlendY register
+//   - BlendModeBlack - darken the first targets towards black using the BlendY register
+//
+// [8 - D] Second Target Layers - the layers used as the second (bottom) target of the effect.
+// these use the same layer bits as the first target layers shifted up by BlendSecondShift
+var BlendControll = (*memmap.BlendControll)(unsafe.Pointer(memmap.IOAddr + 0x0050))
+
+const (
+	// BlendBG0 selects background 0 as a blend target
+	BlendBG0 memmap.BlendControll = 0x0001
+
+	// BlendBG1 selects background 1 as a blend target
+	BlendBG1 memmap.BlendControll = 0x0002
+
+	// BlendBG2 selects background 2 as a blend target
+	BlendBG2 memmap.BlendControll = 0x0004
+
+	// BlendBG3 selects background 3 as a blend target
+	BlendBG3 memmap.BlendControll = 0x0008
+
+	// BlendSprites selects the sprite layer as a blend target
+	BlendSprites memmap.BlendControll = 0x0010
+
+	// BlendBackdrop selects the backdrop color as a blend target
+	BlendBackdrop memmap.BlendControll = 0x0020
+
+	// BlendModeOff disables all color special effects
+	BlendModeOff memmap.BlendControll = 0x0000
+
+	// BlendModeAlpha alpha blends the first and second target layers
+	BlendModeAlpha memmap.BlendControll = 0x0040
+
+	// BlendModeWhite brightens the first target layers towards white
+	BlendModeWhite memmap.BlendControll = 0x0080
+
+	// BlendModeBlack darkens the first target layers towards black
+	BlendModeBlack memmap.BlendControll = 0x00C0
+
+	// BlendModeMask masks out all the bits that are not part of the blend mode
+	BlendModeMask memmap.BlendControll = 0x00C0
+
+	// BlendFirstMask masks out all the bits that are not part of the first target layers
+	BlendFirstMask memmap.BlendControll = 0x003F
+
+	// BlendSecondMask masks out all the bits that are not part of the second target layers
+	BlendSecondMask memmap.BlendControll = 0x3F00
+
+	// BlendSecondShift shifts the target layer bits into the second target layer bits
+	BlendSecondShift memmap.BlendControll = 0x0008
+)
+
+// BlendAlpha is the alpha blending coefficient register. It sets the weight of the first and second target
+// layers when alpha blending. Weights are in 1/16ths and values above 16 are treated as 16.
+// It is a write only register with the following layout.
+//
+// [0 - 4] EVA - the weight of the first target layers (0 - 16)
+//
+// [8 - C] EVB - the weight of the second target layers (0 - 16)
+var BlendAlpha = (*memmap.BlendAlpha)(unsafe.Pointer(memmap.IOAddr + 0x0052))
+
+const (
+	// EVAMask masks out all the bits that are not part of the first target weight
+	EVAMask memmap.BlendAlpha = 0x001F
+
+	// EVBMask masks out all the bits that are not part of the second target weight
+	EVBMask memmap.BlendAlpha = 0x1F00
+
+	// EVBShift shifts a number into the second target weight bits
+	EVBShift memmap.BlendAlpha = 0x0008
+)
+
+// BlendY is the brightness coefficient register. It sets how far the first target layers are brightened or
+// darkened. The value is in 1/16ths and values above 16 are treated as 16. It is a write only register
+// with the following layout.
+//
+// [0 - 4] EVY - the brightness coefficient (0 - 16)
+var BlendY = (*memmap.BlendY)(unsafe.Pointer(memmap.IOAddr + 0x0054))
+
+const (
+	// EVYMask masks out all the bits that are not part of the brightness coefficient
+	EVYMask memmap.BlendY = 0x001F
+)
+
+var (
+	// Win0H is the horizontal bounds register for window 0. The right edge is exclusive and if
+	// the left edge is larger than the right edge the window wraps around the screen. It is write only
+	//
+	// [0 - 7] Right - the right edge of the window plus 1 (0 - 240)
+	//
+	// [8 - F] Left - the left edge of the window (0 - 240)
+	Win0H = (*memmap.WindowH)(unsafe.Pointer(memmap.IOAddr + 0x0040))
+
+	// Win1H is the horizontal bounds register for window 1. The right edge is exclusive and if
+	// the left edge is larger than the right edge the window wraps around the screen. It is write only
+	//
+	// [0 - 7] Right - the right edge of the window plus 1 (0 - 240)
+	//
+	// [8 - F] Left - the left edge of the window (0 - 240)
+	Win1H = (*memmap.WindowH)(unsafe.Pointer(memmap.IOAddr + 0x0042))
+
+	// Win0V is the vertical bounds register for window 0. The bottom edge is exclusive and if
+	// the top edge is larger than the bottom edge the window wraps around the screen. It is write only
+	//
+	// [0 - 7] Bottom - the bottom edge of the window plus 1 (0 - 160)
+	//
+	// [8 - F] Top - the top edge of the window (0 - 160)
+	Win0V = (*memmap.WindowV)(unsafe.Pointer(memmap.IOAddr + 0x0044))
+
+	// Win1V is the vertical bounds register for window 1. The bottom edge is exclusive and if
+	// the top edge is larger than the bottom edge the window wraps around the screen. It is write only
+	//
+	// [0 - 7] Bottom - the bottom edge of the window plus 1 (0 - 160)
+	//
+	// [8 - F] Top - the top edge of the window (0 - 160)
+	Win1V = (*memmap.WindowV)(unsafe.Pointer(memmap.IOAddr + 0x0046))
+)
+
+const (
+	// WinRightMask masks out all the bits that are not part of the right edge of the window
+	WinRightMask memmap.WindowH = 0x00FF
+
+	// WinLeftMask masks out all the bits that are not part of the left edge of the window
+	WinLeftMask memmap.WindowH = 0xFF00
+
+	// WinLeftShift shifts a number into the left edge bits
+	WinLeftShift memmap.WindowH = 0x0008
+
+	// WinBottomMask masks out all the bits that are not part of the bottom edge of the window
+	WinBottomMask memmap.WindowV = 0x00FF
+
+	// WinTopMask masks out all the bits that are not part of the top edge of the window
+	WinTopMask memmap.WindowV = 0xFF00
+
+	// WinTopShift shifts a number into the top edge bits
+	WinTopShift memmap.WindowV = 0x0008
+)
+
+var (
+	// WinIn is the controll register for the inside of window 0 and window 1. It selects which layers
+	// are visible inside each window and if color effects are applied inside each window. It is R/W
+	//
+	// [0 - 5] Window 0 - the layers that are visible inside window 0
+	//   - WinBG0 - background 0 is visible
+	//   - WinBG1 - background 1 is visible
+	//   - WinBG2 - background 2 is visible
+	//   - WinBG3 - background 3 is visible
+	//   - WinSprites - sprites are visible
+	//   - WinEffects - color effects are applied
+	//
+	// [8 - D] Window 1 - the layers that are visible inside window 1, uses the same bits as window 0 shifted up by WinShift
+	WinIn = (*memmap.WindowControll)(unsafe.Pointer(memmap.IOAddr + 0x0048))
+
+	// WinOut is the controll register for the area outside all the windows and the inside of the sprite window.
+	// It selects which layers are visible in each area and if color effects are applied. It is R/W
+	//
+	// [0 - 5] Outside - the layers that are visible outside all the windows
+	//   - WinBG0 - background 0 is visible
+	//   - WinBG1 - background 1 is visible
+	//   - WinBG2 - background 2 is visible
+	//   - WinBG3 - background 3 is visible
+	//   - WinSprites - sprites are visible
+	//   - WinEffects - color effects are applied
+	//
+	// [8 - D] Sprite Window - the layers that are visible inside the sprite window, uses the same bits as outside shifted up by WinShift
+	WinOut = (*memmap.WindowControll)(unsafe.Pointer(memmap.IOAddr + 0x004A))
+)
+
+const (
+	// WinBG0 makes background 0 visible inside a window
+	WinBG0 memmap.WindowControll = 0x0001
+
+	// WinBG1 makes background 1 visible inside a window
+	WinBG1 memmap.WindowControll = 0x0002
+
+	// WinBG2 makes background 2 visible inside a window
+	WinBG2 memmap.WindowControll = 0x0004
+
+	// WinBG3 makes background 3 visible inside a window
+	WinBG3 memmap.WindowControll = 0x0008
+
+	// WinSprites makes sprites visible inside a window
+	WinSprites memmap.WindowControll = 0x0010
+
+	// WinEffects enables color effects inside a window
+	WinEffects memmap.WindowControll = 0x0020
+
+	// WinMask masks out all the bits that are not part of the first window
+	WinMask memmap.WindowControll = 0x003F
+
+	// WinShift shifts the window bits into the second window (window 1 for WinIn and the sprite window for WinOut)
+	WinShift memmap.WindowControll = 0x0008
+)
+
+// MosaicSize is the mosaic size register. It sets the size of the mosaic blocks for backgrounds and sprites
+// that have the mosaic effect enabled. Each size is stored as the block size minus 1 so a value of 0 has
+// no visible effect. It is a write only register with the following layout.
+//
+// [0 - 3] BG Horizontal Size - the width of background mosaic blocks minus 1 (0 - 15)
+//
+// [4 - 7] BG Vertical Size - the height of background mosaic blocks minus 1 (0 - 15)
+//
+// [8 - B] Sprite Horizontal Size - the width of sprite mosaic blocks minus 1 (0 - 15)
+//
+// [C - F] Sprite Vertical Size - the height of sprite mosaic blocks minus 1 (0 - 15)
+var MosaicSize = (*memmap.MosaicSize)(unsafe.Pointer(memmap.IOAddr + 0x004C))
+
+const (
+	// MosaicBGHMask masks out all the bits that are not part of the background mosaic width
+	MosaicBGHMask memmap.MosaicSize = 0x000F
+
+	// MosaicBGVMask masks out all the bits that are not part of the background mosaic height
+	MosaicBGVMask memmap.MosaicSize = 0x00F0
+
+	// MosaicBGVShift shifts a number into the background mosaic height bits
+	MosaicBGVShift memmap.MosaicSize = 0x0004
+
+	// MosaicSprHMask masks out all the bits that are not part of the sprite mosaic width
+	MosaicSprHMask memmap.MosaicSize = 0x0F00
+
+	// MosaicSprHShift shifts a number into the sprite mosaic width bits
+	MosaicSprHShift memmap.MosaicSize = 0x0008
+
+	// MosaicSprVMask masks out all the bits that are not part of the sprite mosaic height
+	MosaicSprVMask memmap.MosaicSize = 0xF000
+
+	// MosaicSprVShift shifts a number into the sprite mosaic height bits
+	MosaicSprVShift memmap.MosaicSize = 0x000C
+)
@@ -1,5 +1,3 @@
-//go:build !standalone
-
 package display
 
 import "github.com/bjatkin/flappy_boot/internal/hardware/memmap"
@@ -1,53 +1,110 @@
 package dma
 
-// TODO: update this to be in line with new standards
-type Register uint
+import (
+	"unsafe"
 
-const (
-	// RegDMA1SAD is the DMA Source Address for the DMA1 transfer channel
-	// it is a 32 bit register
-	RegDMA1SAD Register = 0x0400_00BE
-
-	// RegDMA1DAD is the DMA Destination address for the DMA1 transfer channel
-	// it is a 32 bit register
-	RegDMA1DAD Register = 0x0400_00C0
-
-	// RegDMA1Cnt is the controll register for the DMA1 transfer channel.
-	// It can be used to start the DMA trasfer and has the following layout.
-	//
-	// [0h - Fh] Count - the number of transfers to make. This is an unsigned 16 bit value
-	//
-	// [15h - 16h] Destination Address Controll - controlls how the destination address changes after each transfer
-	//     * DestAddrInc - sets the destination address to increment as each data segment is coppied
-	//     * DestAddrDec - sets the destination address to decrement as each data segment is coppied
-	//     * DestAddrFix - fixes the destination address. It will not change
-	//     * DestAddrRe  - sets the destination address to increment as each data segment is coppied
-	//                     resets the address each time the DMA repeats
-	//
-	// [17h - 18h] Source Address Controll - controlls how the source address changes after each transfer
-	//     * SrcAddrInc - sets the source address to increment as each data segment is coppied
-	//     * SrcAddrDec - sets the source address to decrement as each data segment is coppied
-	//
-	// [19h] Repeat Mode - changes how the Enable bit behaves after the DMA transfer has completed
-	//     * RepeatOn - set the DMA transfer to repeat at each HBlank, VBlank, or empty FIFO buffer
-	// [1Ah] Transfer Size - tels the DMA how many bits of data to transfer at a time
-	//     * Transfer16 - transfers 16 bits of data at a time
-	//     * Transfer32 - transfers 32 bits of data at a time
-	// [1Dh - Ch] Start Mode - sets the start time for the DMA transfer
-	//     * StartNow - start transfering data immediately
-	//     * StartVBlank - start transfering data at the next VBlank
-	//     * StartHBlank - start transfering data at the next HBlank
-	//     * StartFIFO - start transfering data when the configured FIFO buffer is emptied
-	//                   transfer count should be set to 1 and Transfer Size should be 32 bits
-	// [1Eh] DMA Interrupt - can be use to enable DMA interrupts when the DMA transfer is finished
-	//     * IRQEnable - raise an interrupt when finished
-	//     * IRQDisable - do not rais and interrupt when finished
-	// [1Fh] DMA Enable - turns on/ off the DMA channel
-	//     * DMAOn - enable the DMA transfer
-	//     * DMAOff - disable the DMA transfer
-	RegDMA1Cnt Register = 0x0400_00C4
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
 )
 
+// Source0 is the source address register for DMA channel 0, it is a 32 bit register
+var Source0 = (*memmap.DMAAddress)(unsafe.Pointer(memmap.IOAddr + 0x00B0))
+
+// Dest0 is the destination address register for DMA channel 0, it is a 32 bit register
+var Dest0 = (*memmap.DMAAddress)(unsafe.Pointer(memmap.IOAddr + 0x00B4))
+
+// Count0 is the transfer count register for DMA channel 0, see Count3 for more information
+var Count0 = (*memmap.DMACount)(unsafe.Pointer(memmap.IOAddr + 0x00B8))
+
+// Controll0 is the controll register for DMA channel 0, see Controll3 for more information
+var Controll0 = (*memmap.DMAControll)(unsafe.Pointer(memmap.IOAddr + 0x00BA))
+
+// Source1 is the source address register for DMA channel 1, it is a 32 bit register
+var Source1 = (*memmap.DMAAddress)(unsafe.Pointer(memmap.IOAddr + 0x00BC))
+
+// Dest1 is the destination address register for DMA channel 1, it is a 32 bit register
+var Dest1 = (*memmap.DMAAddress)(unsafe.Pointer(memmap.IOAddr + 0x00C0))
+
+// Count1 is the transfer count register for DMA channel 1, see Count3 for more information
+var Count1 = (*memmap.DMACount)(unsafe.Pointer(memmap.IOAddr + 0x00C4))
+
+// Controll1 is the controll register for DMA channel 1, see Controll3 for more information
+var Controll1 = (*memmap.DMAControll)(unsafe.Pointer(memmap.IOAddr + 0x00C6))
+
+// Source2 is the source address register for DMA channel 2, it is a 32 bit register
+var Source2 = (*memmap.DMAAddress)(unsafe.Pointer(memmap.IOAddr + 0x00C8))
+
+// Dest2 is the destination address register for DMA channel 2, it is a 32 bit register
+var Dest2 = (*memmap.DMAAddress)(unsafe.Pointer(memmap.IOAddr + 0x00CC))
+
+// Count2 is the transfer count register for DMA channel 2, see Count3 for more information
+var Count2 = (*memmap.DMACount)(unsafe.Pointer(memmap.IOAddr + 0x00D0))
+
+// Controll2 is the controll register for DMA channel 2, see Controll3 for more information
+var Controll2 = (*memmap.DMAControll)(unsafe.Pointer(memmap.IOAddr + 0x00D2))
+
+// Source3 is the source address register for DMA channel 3, it is a 32 bit register.
+// The address must be 16 bit aligned for 16 bit transfers, and 32 bit aligned for 32 bit transfers
+var Source3 = (*memmap.DMAAddress)(unsafe.Pointer(memmap.IOAddr + 0x00D4))
+
+// Dest3 is the destination address register for DMA channel 3, it is a 32 bit register.
+// The address must be 16 bit aligned for 16 bit transfers, and 32 bit aligned for 32 bit transfers
+var Dest3 = (*memmap.DMAAddress)(unsafe.Pointer(memmap.IOAddr + 0x00D8))
+
+// Count3 is the number of transfers DMA channel 3 will make. Each transfer is either 16 or 32 bits
+// depending on the transfer size set in Controll3. A count of 0 is treated as the maximum count
+var Count3 = (*memmap.DMACount)(unsafe.Pointer(memmap.IOAddr + 0x00DC))
+
+// Controll3 is the controll register for DMA channel 3.
+// It can be used to start the DMA trasfer and has the following layout.
+//
+// [5 - 6] Destination Address Controll - controlls how the destination address changes after each transfer
+//   - DestAddrInc - sets the destination address to increment as each data segment is coppied
+//   - DestAddrDec - sets the destination address to decrement as each data segment is coppied
+//   - DestAddrFix - fixes the destination address. It will not change
+//   - DestAddrRe  - sets the destination address to increment as each data segment is coppied
+//     resets the address each time the DMA repeats
+//
+// [7 - 8] Source Address Controll - controlls how the source address changes after each transfer
+//   - SrcAddrInc - sets the source address to increment as each data segment is coppied
+//   - SrcAddrDec - sets the source address to decrement as each data segment is coppied
+//   - SrcAddrFix - fixes the source address. It will not change
+//
+// [9] Repeat Mode - changes how the Enable bit behaves after the DMA transfer has completed
+//   - RepeatOn - set the DMA transfer to repeat at each HBlank, VBlank, or empty FIFO buffer
+//
+// [A] Transfer Size - tels the DMA how many bits of data to transfer at a time
+//   - Transfer16 - transfers 16 bits of data at a time
+//   - Transfer32 - transfers 32 bits of data at a time
+//
+// [C - D] Start Mode - sets the start time for the DMA transfer
+//   - StartNow - start transfering data immediately
+//   - StartVBlank - start transfering data at the next VBlank
+//   - StartHBlank - start transfering data at the next HBlank
+//   - StartFIFO - start transfering data when the configured FIFO buffer is emptied
+//     transfer count should be set to 1 and Transfer Size should be 32 bits. Only DMA 1 and 2 support this mode
+//
+// [E] DMA Interrupt - can be use to enable DMA interrupts when the DMA transfer is finished
+//   - IRQEnable - raise an interrupt when finished
+//   - IRQDisable - do not rais and interrupt when finished
+//
+// [F] DMA Enable - turns on/ off the DMA channel
+//   - DMAOn - enable the DMA transfer
+//   - DMAOff - disable the DMA transfer
+var Controll3 = (*memmap.DMAControll)(unsafe.Pointer(memmap.IOAddr + 0x00DE))
+
+// channels are the registers for each DMA channel
+var channels = [4]struct {
+	source   *memmap.DMAAddress
+	dest     *memmap.DMAAddress
+	count    *memmap.DMACount
+	controll *memmap.DMAControll
+}{
+	{Source0, Dest0, Count0, Controll0},
+	{Source1, Dest1, Count1, Controll1},
+	{Source2, Dest2, Count2, Controll2},
+	{Source3, Dest3, Count3, Controll3},
+}
+
 const (
 	// SystemClock is the exact number of CPU ticks per cycle (16.78MHz)
 	SystemClock = 16_777_216
@@ -55,73 +112,193 @@ const (
 	// ScreenRefresh is the exact number of cycles between each screen refresh
 	ScreenRefresh = 280_896
 
+	// MaxCount is the largest number of transfers DMA channel 3 can make at once
+	MaxCount = 0x1_0000
+
+	// FIFOWords is the number of 32 bit words a streaming DMA channel copies each time the fifo runs low
+	FIFOWords = 4
+)
+
+const (
 	// DestAddrInc sets the destination address to increase after transfering each data segment
-	// should be used with the RegDMA1CntH register
-	DestAddrInc = 0x0000
+	DestAddrInc memmap.DMAControll = 0x0000
 
 	// DestAddrDec sets the destination address to decrease after transfering each data segment
-	// should be used with the RegDMA1CntH register
-	DestAddrDec = 0x0020
+	DestAddrDec memmap.DMAControll = 0x0020
 
 	// DestAddrFix sets the destination address to be fixed
-	// should be used with the RegDMA1CntH register
-	DestAddrFix = 0x0040
+	DestAddrFix memmap.DMAControll = 0x0040
 
 	// DestAddrRe sets the destination address to increase after transfering each data segment
 	// and then reset each time the DMA repeats so each copy starts at the same destination
-	// should be used with the RegDMA1CntH register
-	DestAddrRe = 0x0060
+	DestAddrRe memmap.DMAControll = 0x0060
 
 	// SrcAddrInc sets the source address to increase after transfering each data segment
-	// should be used with the RegDMA1CntH register
-	SrcAddrInc = 0x0000
+	SrcAddrInc memmap.DMAControll = 0x0000
 
 	// SrcAddrDec sets the source address to decrease after transfering each data segment
-	// should be used with the RegDMA1CntH register
-	SrcAddrDec = 0x0080
+	SrcAddrDec memmap.DMAControll = 0x0080
+
+	// SrcAddrFix sets the source address to be fixed
+	SrcAddrFix memmap.DMAControll = 0x0100
 
 	// RepeatOn sets the DMA to repeat copying data at each VBlank HBlank or FIFO buffer empty
 	// depending on the start time
-	// should be used with the RegDMA1CntH register
-	RepeatOn = 0x0200
+	RepeatOn memmap.DMAControll = 0x0200
 
 	// Transfer16 sets the DMA to transfer 16 bits at a time
-	// should be used with the RegDMA1CntH register
-	Transfer16 = 0x0000
+	Transfer16 memmap.DMAControll = 0x0000
 
 	// Transfer32 sets the DMA to transfer 32 bits at a time
-	// should be used with the RegDMA1CntH register
-	Transfer32 = 0x0400
+	Transfer32 memmap.DMAControll = 0x0400
 
 	// StartNow sest the DMA transfer to start immediately
-	// should be used with the RegDMA1CntH register
-	StartNow = 0x0000
+	StartNow memmap.DMAControll = 0x0000
 
 	// StartVBlank sets the DMA transfer to start on the next vertical blank
-	// should be used with the RegDMA1CntH register
-	StartVBlank = 0x1000
+	StartVBlank memmap.DMAControll = 0x1000
 
 	// StartHBlank sets the DMA transfer to start horizontal blank
-	// should be used with the RegDMA1CntH register
-	StartHBlank = 0x2000
+	StartHBlank memmap.DMAControll = 0x2000
 
 	// StartFIFO sest the DMA transfer to start when the configured FIFO buffer is empty
-	// should be used with the RegDMA1CntH register
-	StartFIFO = 0x3000
+	StartFIFO memmap.DMAControll = 0x3000
 
 	// IRQEnable turns on interrupts when the DMA tranfer is complete
-	// should be used with the RegDMA1CntH register
-	IRQEnable = 0x4000
+	IRQEnable memmap.DMAControll = 0x4000
+
+	// IRQDisable turns off interrupts when the DMA transfer is complete
+	IRQDisable memmap.DMAControll = 0x0000
+
+	// DMAOn starts the DMA transfer
+	DMAOn memmap.DMAControll = 0x8000
 
-	// IRQDisable turns on interrupts when the DMA transfer is complete
-	// should be used with the RegDMA1CntH register
-	IRQDisable = 0x0000
+	// DMAOff stops the DMA transfer
+	DMAOff memmap.DMAControll = 0x0000
 
-	// start the DMA transfer
-	// should be used with the RegDMA1CntH register
-	DMAOn = 0x8000
+	// streamControll is the controll value for a DMA channel that is streaming data into a sound fifo
+	streamControll = DestAddrFix | SrcAddrInc | RepeatOn | Transfer32 | StartFIFO | DMAOn
 
-	// stop the DMA transfer
-	// should be used with the RegDMA1CntH register
-	DMAOff = 0x0000
+	// destMask masks out the destination address controll bits
+	destMask memmap.DMAControll = 0x0060
+
+	// srcMask masks out the source address controll bits
+	srcMask memmap.DMAControll = 0x0180
 )
+
+// halfword is any 16 bit memory value such as memmap.VRAMValue or memmap.PaletteValue
+type halfword interface {
+	~uint16
+}
+
+// fillValue holds the value for the current fill, DMA can only copy from memory so the
+// value needs to live somewhere it won't move durring the transfer
+var fillValue uint32
+
+// Copy16 copies src into dest 16 bits at a time using DMA channel 3. If the slices are different lengths
+// only the length of the shorter slice is coppied
+func Copy16[T halfword](dest, src []T) {
+	n := len(src)
+	if len(dest) < n {
+		n = len(dest)
+	}
+	if n == 0 {
+		return
+	}
+
+	run(unsafe.Pointer(&dest[0]), unsafe.Pointer(&src[0]), n, SrcAddrInc|Transfer16)
+}
+
+// Copy32 copies src into dest 32 bits at a time using DMA channel 3, this is roughly twice as fast as Copy16.
+// If the slices are different lengths only the length of the shorter slice is coppied. If either slice is
+// not 32 bit aligned, or the length is odd, the remaining data is coppied 16 bits at a time
+func Copy32[T halfword](dest, src []T) {
+	n := len(src)
+	if len(dest) < n {
+		n = len(dest)
+	}
+	if n == 0 {
+		return
+	}
+
+	d, s := unsafe.Pointer(&dest[0]), unsafe.Pointer(&src[0])
+	if !aligned(d) || !aligned(s) {
+		run(d, s, n, SrcAddrInc|Transfer16)
+		return
+	}
+
+	run(d, s, n/2, SrcAddrInc|Transfer32)
+	if n%2 == 1 {
+		run(unsafe.Pointer(&dest[n-1]), unsafe.Pointer(&src[n-1]), 1, SrcAddrInc|Transfer16)
+	}
+}
+
+// Fill16 sets every value in dest to value 16 bits at a time using DMA channel 3
+func Fill16[T halfword](dest []T, value T) {
+	if len(dest) == 0 {
+		return
+	}
+
+	fillValue = uint32(value)
+	run(unsafe.Pointer(&dest[0]), unsafe.Pointer(&fillValue), len(dest), SrcAddrFix|Transfer16)
+}
+
+// Fill32 sets every value in dest to value 32 bits at a time using DMA channel 3, this is roughly twice as fast
+// as Fill16. If dest is not 32 bit aligned, or the length is odd, the remaining data is filled 16 bits at a time
+func Fill32[T halfword](dest []T, value T) {
+	if len(dest) == 0 {
+		return
+	}
+
+	fillValue = uint32(value) | uint32(value)<<16
+	d := unsafe.Pointer(&dest[0])
+	if !aligned(d) {
+		run(d, unsafe.Pointer(&fillValue), len(dest), SrcAddrFix|Transfer16)
+		return
+	}
+
+	n := len(dest)
+	run(d, unsafe.Pointer(&fillValue), n/2, SrcAddrFix|Transfer32)
+	if n%2 == 1 {
+		run(unsafe.Pointer(&dest[n-1]), unsafe.Pointer(&fillValue), 1, SrcAddrFix|Transfer16)
+	}
+}
+
+// aligned returns true if ptr is 32 bit aligned
+func aligned(ptr unsafe.Pointer) bool {
+	return uintptr(ptr)%4 == 0
+}
+
+// run splits the transfer into chunks that fit in the count register and transfers each chunk
+func run(dest, src unsafe.Pointer, count int, controll memmap.DMAControll) {
+	size := 2
+	if controll&Transfer32 != 0 {
+		size = 4
+	}
+
+	for count > 0 {
+		n := count
+		if n > MaxCount {
+			n = MaxCount
+		}
+
+		transfer(dest, src, n, controll)
+		count -= n
+
+		// the source address controll bits are shifted down so they line up with the destination address controll bits
+		dest = step(dest, controll&destMask, n*size)
+		src = step(src, (controll&srcMask)>>2, n*size)
+	}
+}
+
+// step moves the address ptr by offset bytes in the direction set by the destination address controll mode
+func step(ptr unsafe.Pointer, mode memmap.DMAControll, offset int) unsafe.Pointer {
+	switch mode {
+	case DestAddrDec:
+		return unsafe.Add(ptr, -offset)
+	case DestAddrFix:
+		return ptr
+	default:
+		return unsafe.Add(ptr, offset)
+	}
+}
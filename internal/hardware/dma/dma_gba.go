@@ -0,0 +1,36 @@
+//go:build !standalone
+
+package dma
+
+import (
+	"unsafe"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+// transfer starts an immediate DMA transfer on channel 3. The cpu is halted until the transfer is complete
+// so the transfer is finished by the time this function returns
+func transfer(dest, src unsafe.Pointer, count int, controll memmap.DMAControll) {
+	memmap.SetReg32(Source3, memmap.DMAAddress(uintptr(src)))
+	memmap.SetReg32(Dest3, memmap.DMAAddress(uintptr(dest)))
+	memmap.SetReg(Count3, memmap.DMACount(count))
+	memmap.SetReg(Controll3, controll|StartNow|DMAOn)
+}
+
+// Stream starts DMA channel 1 or 2 streaming src into the fifo register. The channel copies FIFOWords words
+// into the fifo each time it runs low, reading further into src each time. The channel keeps reading past
+// the end of src so it should be restarted with new data before src runs out
+func Stream(channel int, fifo *memmap.SoundFIFO, src []uint32) {
+	regs := channels[channel]
+
+	// the channel must be turned off before the new source address is used
+	memmap.SetReg(regs.controll, DMAOff)
+	memmap.SetReg32(regs.source, memmap.DMAAddress(uintptr(unsafe.Pointer(&src[0]))))
+	memmap.SetReg32(regs.dest, memmap.DMAAddress(uintptr(unsafe.Pointer(fifo))))
+	memmap.SetReg(regs.controll, streamControll)
+}
+
+// StopStream stops the DMA channel from streaming data into its fifo
+func StopStream(channel int) {
+	memmap.SetReg(channels[channel].controll, DMAOff)
+}
@@ -0,0 +1,98 @@
+//go:build standalone
+
+package dma
+
+import (
+	"unsafe"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+// transfer replaces transfer from dma_gba.go, the source and destination are normal go memory
+// so the transfer is done one 16 or 32 bit value at a time the same way the DMA hardware would copy it
+func transfer(dest, src unsafe.Pointer, count int, controll memmap.DMAControll) {
+	size := 2
+	if controll&Transfer32 != 0 {
+		size = 4
+	}
+
+	for i := 0; i < count; i++ {
+		if size == 4 {
+			*(*uint32)(dest) = *(*uint32)(src)
+		} else {
+			*(*uint16)(dest) = *(*uint16)(src)
+		}
+
+		dest = step(dest, controll&destMask, size)
+		src = step(src, (controll&srcMask)>>2, size)
+	}
+}
+
+// stream is the data a DMA channel is streaming into a sound fifo
+type stream struct {
+	fifo *memmap.SoundFIFO
+	src  []uint32
+	pos  int
+}
+
+// streams are the active streams for each DMA channel
+var streams [4]stream
+
+// Stream replaces Stream from dma_gba.go. A go slice can not be stored in the 32 bit source register
+// so the stream is kept here until the emulated sound hardware asks for more data using Refill
+func Stream(channel int, fifo *memmap.SoundFIFO, src []uint32) {
+	streams[channel] = stream{fifo: fifo, src: src}
+	memmap.SetReg(channels[channel].controll, streamControll)
+}
+
+// StopStream replaces StopStream from dma_gba.go
+func StopStream(channel int) {
+	streams[channel] = stream{}
+	memmap.SetReg(channels[channel].controll, DMAOff)
+}
+
+// StreamState returns the data the DMA channel is streaming and how many words have been streamed so far,
+// src is nil if the channel is not streaming. It's used to save the stream in a save state
+func StreamState(channel int) (fifo *memmap.SoundFIFO, src []uint32, pos int) {
+	s := streams[channel]
+	return s.fifo, s.src, s.pos
+}
+
+// RestoreStream restores a stream returned by StreamState. Unlike Stream the channels controll register is not
+// changed since it's restored along with the rest of the IO registers
+func RestoreStream(channel int, fifo *memmap.SoundFIFO, src []uint32, pos int) {
+	streams[channel] = stream{fifo: fifo, src: src, pos: pos}
+}
+
+// Refill returns the next FIFOWords words of the channels stream, the same words the DMA hardware would copy into
+// the fifo when it runs low. A memory register can't queue writes like the fifo so the emulated sound hardware needs
+// to push the words into its own fifo. Words past the end of the stream are silent, nil is returned if the channel
+// is not streaming
+func Refill(channel int) []uint32 {
+	if memmap.GetReg(channels[channel].controll)&DMAOn == 0 {
+		return nil
+	}
+
+	s := &streams[channel]
+	words := make([]uint32, FIFOWords)
+	for i := range words {
+		if s.pos < len(s.src) {
+			words[i] = s.src[s.pos]
+		}
+		s.pos++
+	}
+
+	return words
+}
+
+// RefillFIFO refills the fifo from the DMA channel that is streaming into it. Only DMA 1 and 2 can stream
+// into the sound fifos, nil is returned if neither channel is streaming into the fifo
+func RefillFIFO(fifo *memmap.SoundFIFO) []uint32 {
+	for _, channel := range []int{1, 2} {
+		if streams[channel].fifo == fifo {
+			return Refill(channel)
+		}
+	}
+
+	return nil
+}
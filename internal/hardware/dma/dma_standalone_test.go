@@ -0,0 +1,276 @@
+//go:build standalone
+
+package dma
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+// testData returns n values that are all different so misplaced copies are easy to spot
+func testData(n int) []memmap.VRAMValue {
+	data := make([]memmap.VRAMValue, n)
+	for i := range data {
+		data[i] = memmap.VRAMValue(i*0x0101 + 1)
+	}
+	return data
+}
+
+// loopCopy is the plain loop copy the dma copies should match
+func loopCopy(dest, src []memmap.VRAMValue) {
+	for i := range src {
+		if i >= len(dest) {
+			return
+		}
+		dest[i] = src[i]
+	}
+}
+
+// loopFill is the plain loop fill the dma fills should match
+func loopFill(dest []memmap.VRAMValue, value memmap.VRAMValue) {
+	for i := range dest {
+		dest[i] = value
+	}
+}
+
+func TestCopy(t *testing.T) {
+	type args struct {
+		destLen   int
+		destStart int
+		srcLen    int
+		srcStart  int
+	}
+	tests := []struct {
+		name string
+		args args
+	}{
+		{
+			"empty",
+			args{destLen: 0, srcLen: 0},
+		},
+		{
+			"same length",
+			args{destLen: 64, srcLen: 64},
+		},
+		{
+			"short dest",
+			args{destLen: 10, srcLen: 64},
+		},
+		{
+			"short src",
+			args{destLen: 64, srcLen: 10},
+		},
+		{
+			"odd length",
+			args{destLen: 33, srcLen: 33},
+		},
+		{
+			"unaligned dest",
+			args{destLen: 65, destStart: 1, srcLen: 64},
+		},
+		{
+			"unaligned src",
+			args{destLen: 64, srcLen: 65, srcStart: 1},
+		},
+		{
+			"larger than max count",
+			args{destLen: MaxCount + 3, srcLen: MaxCount + 3},
+		},
+	}
+
+	copies := []struct {
+		name string
+		copy func(dest, src []memmap.VRAMValue)
+	}{
+		{"Copy16", Copy16[memmap.VRAMValue]},
+		{"Copy32", Copy32[memmap.VRAMValue]},
+	}
+
+	for _, tt := range tests {
+		for _, c := range copies {
+			t.Run(c.name+" "+tt.name, func(t *testing.T) {
+				src := testData(tt.args.srcLen)[tt.args.srcStart:]
+
+				want := make([]memmap.VRAMValue, tt.args.destLen)
+				loopCopy(want[tt.args.destStart:], src)
+
+				got := make([]memmap.VRAMValue, tt.args.destLen)
+				c.copy(got[tt.args.destStart:], src)
+
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s() does not match a loop copy", c.name)
+				}
+			})
+		}
+	}
+}
+
+func TestFill(t *testing.T) {
+	type args struct {
+		destLen   int
+		destStart int
+		value     memmap.VRAMValue
+	}
+	tests := []struct {
+		name string
+		args args
+	}{
+		{
+			"empty",
+			args{destLen: 0, value: 0x7FFF},
+		},
+		{
+			"even length",
+			args{destLen: 64, value: 0x1234},
+		},
+		{
+			"odd length",
+			args{destLen: 33, value: 0x1234},
+		},
+		{
+			"unaligned dest",
+			args{destLen: 65, destStart: 1, value: 0xABCD},
+		},
+		{
+			"larger than max count",
+			args{destLen: MaxCount*2 + 5, value: 0x0F0F},
+		},
+	}
+
+	fills := []struct {
+		name string
+		fill func(dest []memmap.VRAMValue, value memmap.VRAMValue)
+	}{
+		{"Fill16", Fill16[memmap.VRAMValue]},
+		{"Fill32", Fill32[memmap.VRAMValue]},
+	}
+
+	for _, tt := range tests {
+		for _, f := range fills {
+			t.Run(f.name+" "+tt.name, func(t *testing.T) {
+				want := testData(tt.args.destLen)
+				loopFill(want[tt.args.destStart:], tt.args.value)
+
+				got := testData(tt.args.destLen)
+				f.fill(got[tt.args.destStart:], tt.args.value)
+
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s() does not match a loop fill", f.name)
+				}
+			})
+		}
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	type args struct {
+		destStart int
+		srcStart  int
+		count     int
+		controll  memmap.DMAControll
+	}
+	tests := []struct {
+		name string
+		args args
+		want []memmap.VRAMValue
+	}{
+		{
+			"increment 16",
+			args{count: 3, controll: DestAddrInc | SrcAddrInc | Transfer16},
+			[]memmap.VRAMValue{0x0001, 0x0102, 0x0203, 0, 0, 0, 0, 0},
+		},
+		{
+			"increment 32",
+			args{count: 2, controll: DestAddrInc | SrcAddrInc | Transfer32},
+			[]memmap.VRAMValue{0x0001, 0x0102, 0x0203, 0x0304, 0, 0, 0, 0},
+		},
+		{
+			"fixed source",
+			args{count: 4, controll: DestAddrInc | SrcAddrFix | Transfer16},
+			[]memmap.VRAMValue{0x0001, 0x0001, 0x0001, 0x0001, 0, 0, 0, 0},
+		},
+		{
+			"fixed dest",
+			args{count: 4, controll: DestAddrFix | SrcAddrInc | Transfer16},
+			[]memmap.VRAMValue{0x0304, 0, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			"decrement source",
+			args{srcStart: 7, count: 3, controll: DestAddrInc | SrcAddrDec | Transfer16},
+			[]memmap.VRAMValue{0x0708, 0x0607, 0x0506, 0, 0, 0, 0, 0},
+		},
+		{
+			"decrement dest",
+			args{destStart: 7, count: 3, controll: DestAddrDec | SrcAddrInc | Transfer16},
+			[]memmap.VRAMValue{0, 0, 0, 0, 0, 0x0203, 0x0102, 0x0001},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := testData(8)
+			got := make([]memmap.VRAMValue, 8)
+
+			dest := unsafe.Pointer(&got[tt.args.destStart])
+			transfer(dest, unsafe.Pointer(&src[tt.args.srcStart]), tt.args.count, tt.args.controll)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("transfer() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefill(t *testing.T) {
+	src := []uint32{1, 2, 3, 4, 5, 6}
+	fifo := new(memmap.SoundFIFO)
+
+	if got := Refill(1); got != nil {
+		t.Errorf("Refill() = %v before the stream started, want nil", got)
+	}
+
+	Stream(1, fifo, src)
+
+	tests := []struct {
+		name string
+		want []uint32
+	}{
+		{"first refill", []uint32{1, 2, 3, 4}},
+		{"past the end is silent", []uint32{5, 6, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Refill(1); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Refill() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	StopStream(1)
+	if got := Refill(1); got != nil {
+		t.Errorf("Refill() = %v after the stream stopped, want nil", got)
+	}
+}
+
+func TestRefillFIFO(t *testing.T) {
+	fifoA := new(memmap.SoundFIFO)
+	fifoB := new(memmap.SoundFIFO)
+	Stream(2, fifoB, []uint32{1, 2, 3, 4})
+
+	if got := RefillFIFO(fifoA); got != nil {
+		t.Errorf("RefillFIFO() = %v for a fifo with no stream, want nil", got)
+	}
+
+	want := []uint32{1, 2, 3, 4}
+	if got := RefillFIFO(fifoB); !reflect.DeepEqual(got, want) {
+		t.Errorf("RefillFIFO() = %v, want %v", got, want)
+	}
+
+	StopStream(2)
+	if got := RefillFIFO(fifoB); got != nil {
+		t.Errorf("RefillFIFO() = %v after the stream stopped, want nil", got)
+	}
+}
@@ -0,0 +1,9 @@
+// VBlankIntrWait halts the cpu until the next VBlank interrupt using the BIOS VBlankIntrWait call.
+// the BIOS call is only available when building for the GBA's arm cpu
+static inline void VBlankIntrWait(void) {
+#if defined(__thumb__)
+    __asm__ volatile("swi 0x05" ::: "r0", "r1", "r2", "r3", "memory");
+#elif defined(__arm__)
+    __asm__ volatile("swi 0x050000" ::: "r0", "r1", "r2", "r3", "memory");
+#endif
+}
@@ -0,0 +1,130 @@
+package interrupt
+
+import (
+	"unsafe"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+// Enable is the register used to enable each of the hardware interrupts. An interrupt is only raised if
+// it's bit is set in this register, the Master register is enabled, and the interrupt is enabled by the
+// hardware that raises it (e.g. display.VBlankIRQ). It has the following layout
+//
+// [0] VBlank - raised when the screen enters the vertical blank
+// [1] HBlank - raised when the screen enters a horizontal blank
+// [2] VCount - raised when the vertical line matches the VCount setting in display.Stat
+// [3] Timer0 - raised when timer 0 overflows
+// [4] Timer1 - raised when timer 1 overflows
+// [5] Timer2 - raised when timer 2 overflows
+// [6] Timer3 - raised when timer 3 overflows
+// [7] Serial - raised by the serial port
+// [8] DMA0 - raised when DMA 0 finishes
+// [9] DMA1 - raised when DMA 1 finishes
+// [A] DMA2 - raised when DMA 2 finishes
+// [B] DMA3 - raised when DMA 3 finishes
+// [C] Keypad - raised by the keys set in key.Controll
+// [D] GamePak - raised when the game pak is removed
+var Enable = (*memmap.Interrupt)(unsafe.Pointer(memmap.IOAddr + 0x0200))
+
+// Request is the register that contains the interrupts that have been raised. It has the same layout as
+// the Enable register. Writing a 1 to a bit in this register acknowledges the interrupt and clears the bit
+var Request = (*memmap.Interrupt)(unsafe.Pointer(memmap.IOAddr + 0x0202))
+
+// Master is the master interrupt enable register, no interrupts are raised unless it's enabled.
+// It has the following layout
+//
+// [0] Master Enable - enables all the interrupts set in the Enable register
+//   - MasterEnable - enables interrupts
+//   - MasterDisable - disables all interrupts
+var Master = (*memmap.InterruptMaster)(unsafe.Pointer(memmap.IOAddr + 0x0208))
+
+const (
+	// VBlank is the vertical blank interrupt
+	VBlank memmap.Interrupt = 0x0001
+
+	// HBlank is the horizontal blank interrupt
+	HBlank memmap.Interrupt = 0x0002
+
+	// VCount is the vertical line counter match interrupt
+	VCount memmap.Interrupt = 0x0004
+
+	// Timer0 is the timer 0 overflow interrupt
+	Timer0 memmap.Interrupt = 0x0008
+
+	// Timer1 is the timer 1 overflow interrupt
+	Timer1 memmap.Interrupt = 0x0010
+
+	// Timer2 is the timer 2 overflow interrupt
+	Timer2 memmap.Interrupt = 0x0020
+
+	// Timer3 is the timer 3 overflow interrupt
+	Timer3 memmap.Interrupt = 0x0040
+
+	// Serial is the serial communication interrupt
+	Serial memmap.Interrupt = 0x0080
+
+	// DMA0 is the DMA 0 transfer complete interrupt
+	DMA0 memmap.Interrupt = 0x0100
+
+	// DMA1 is the DMA 1 transfer complete interrupt
+	DMA1 memmap.Interrupt = 0x0200
+
+	// DMA2 is the DMA 2 transfer complete interrupt
+	DMA2 memmap.Interrupt = 0x0400
+
+	// DMA3 is the DMA 3 transfer complete interrupt
+	DMA3 memmap.Interrupt = 0x0800
+
+	// Keypad is the keypad interrupt
+	Keypad memmap.Interrupt = 0x1000
+
+	// GamePak is the game pak removed interrupt
+	GamePak memmap.Interrupt = 0x2000
+
+	// Count is the number of hardware interrupts
+	Count = 14
+)
+
+const (
+	// MasterEnable enables all the interrupts in the Enable register
+	MasterEnable memmap.InterruptMaster = 0x0001
+
+	// MasterDisable disables all interrupts
+	MasterDisable memmap.InterruptMaster = 0x0000
+)
+
+// handlers is the dispatch table for the hardware interrupts, the index is the interrupts bit
+var handlers [Count]func()
+
+// Handle sets the handler for each of the interrupts in irq and enables them in the Enable register.
+// handler may be nil if the interrupt is only used to wake the cpu, e.g. by WaitVBlank.
+// The hardware that raises the interrupt must also be configured to raise it
+func Handle(irq memmap.Interrupt, handler func()) {
+	for i := range handlers {
+		if irq&(1<<i) > 0 {
+			handlers[i] = handler
+		}
+	}
+
+	memmap.SetReg(Enable, memmap.GetReg(Enable)|irq)
+}
+
+// Disable removes the handlers for each of the interrupts in irq and disables them in the Enable register
+func Disable(irq memmap.Interrupt) {
+	for i := range handlers {
+		if irq&(1<<i) > 0 {
+			handlers[i] = nil
+		}
+	}
+
+	memmap.SetReg(Enable, memmap.GetReg(Enable)&^irq)
+}
+
+// dispatch calls the handlers for each of the interrupts in irq
+func dispatch(irq memmap.Interrupt) {
+	for i, handler := range handlers {
+		if irq&(1<<i) > 0 && handler != nil {
+			handler()
+		}
+	}
+}
@@ -0,0 +1,28 @@
+//go:build !standalone
+
+package interrupt
+
+// #include "bios.h"
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+// biosRequest is the BIOS copy of the Request register, the BIOS wait functions check it to see
+// which interrupts have been handled. It's not updated by the hardware so it must be set by the handlers
+var biosRequest = (*memmap.Interrupt)(unsafe.Pointer(uintptr(0x0300_7FF8)))
+
+// handle marks the interrupt as handled for the BIOS and then calls the interrupt handler
+func handle(irq memmap.Interrupt) {
+	memmap.SetReg(biosRequest, memmap.GetReg(biosRequest)|irq)
+	dispatch(irq)
+}
+
+// WaitVBlank halts the cpu until the next VBlank interrupt. The VBlank interrupt must be enabled
+// using Handle and display.VBlankIRQ, and the Master register must be enabled or WaitVBlank will never return
+func WaitVBlank() {
+	C.VBlankIntrWait()
+}
@@ -0,0 +1,21 @@
+//go:build standalone
+
+package interrupt
+
+import "github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+
+// WaitVBlank does nothing in standalone mode, the harness controlls the frame timing
+func WaitVBlank() {}
+
+// Raise raises the interrupts in irq the same way the GBA hardware would. Interrupts that are enabled
+// are acknowledged and their handlers are called, all other interrupts are left in the Request register
+func Raise(irq memmap.Interrupt) {
+	memmap.SetReg(Request, memmap.GetReg(Request)|irq)
+	if memmap.GetReg(Master)&MasterEnable == 0 {
+		return
+	}
+
+	active := memmap.GetReg(Request) & memmap.GetReg(Enable)
+	memmap.SetReg(Request, memmap.GetReg(Request)&^active)
+	dispatch(active)
+}
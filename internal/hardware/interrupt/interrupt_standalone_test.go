@@ -0,0 +1,72 @@
+//go:build standalone
+
+package interrupt
+
+import (
+	"testing"
+
+	"github.com/bjatkin/flappy_boot/internal/hardware/memmap"
+)
+
+func TestRaise(t *testing.T) {
+	tests := []struct {
+		name        string
+		master      memmap.InterruptMaster
+		handle      memmap.Interrupt
+		raise       memmap.Interrupt
+		wantCalls   int
+		wantRequest memmap.Interrupt
+	}{
+		{
+			"enabled interrupt",
+			MasterEnable,
+			VBlank,
+			VBlank,
+			1,
+			0,
+		},
+		{
+			"master disabled",
+			MasterDisable,
+			VBlank,
+			VBlank,
+			0,
+			VBlank,
+		},
+		{
+			"interrupt not enabled",
+			MasterEnable,
+			HBlank,
+			VBlank,
+			0,
+			VBlank,
+		},
+		{
+			"multiple interrupts",
+			MasterEnable,
+			VBlank | Timer2,
+			VBlank | Timer2 | DMA1,
+			2,
+			DMA1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*Enable = 0
+			*Request = 0
+			*Master = tt.master
+			Disable(0x3FFF)
+
+			var calls int
+			Handle(tt.handle, func() { calls++ })
+			Raise(tt.raise)
+
+			if calls != tt.wantCalls {
+				t.Errorf("Raise() called handlers %d times, want %d", calls, tt.wantCalls)
+			}
+			if *Request != tt.wantRequest {
+				t.Errorf("Raise() Request = %#04x, want %#04x", *Request, tt.wantRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !standalone && gameboyadvance
+
+package interrupt
+
+import (
+	"machine"
+	"runtime/interrupt"
+)
+
+func init() {
+	// the tinygo runtime acknowledges the interrupt in the Request register before it calls the handler,
+	// each interrupt must be registered seperately since the interrupt number needs to be a constant
+	interrupt.New(machine.IRQ_VBLANK, func(interrupt.Interrupt) { handle(VBlank) })
+	interrupt.New(machine.IRQ_HBLANK, func(interrupt.Interrupt) { handle(HBlank) })
+	interrupt.New(machine.IRQ_VCOUNT, func(interrupt.Interrupt) { handle(VCount) })
+	interrupt.New(machine.IRQ_TIMER0, func(interrupt.Interrupt) { handle(Timer0) })
+	interrupt.New(machine.IRQ_TIMER1, func(interrupt.Interrupt) { handle(Timer1) })
+	interrupt.New(machine.IRQ_TIMER2, func(interrupt.Interrupt) { handle(Timer2) })
+	interrupt.New(machine.IRQ_TIMER3, func(interrupt.Interrupt) { handle(Timer3) })
+	interrupt.New(machine.IRQ_COM, func(interrupt.Interrupt) { handle(Serial) })
+	interrupt.New(machine.IRQ_DMA0, func(interrupt.Interrupt) { handle(DMA0) })
+	interrupt.New(machine.IRQ_DMA1, func(interrupt.Interrupt) { handle(DMA1) })
+	interrupt.New(machine.IRQ_DMA2, func(interrupt.Interrupt) { handle(DMA2) })
+	interrupt.New(machine.IRQ_DMA3, func(interrupt.Interrupt) { handle(DMA3) })
+	interrupt.New(machine.IRQ_KEYPAD, func(interrupt.Interrupt) { handle(Keypad) })
+	interrupt.New(machine.IRQ_GAMEPAK, func(interrupt.Interrupt) { handle(GamePak) })
+}
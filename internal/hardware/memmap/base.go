@@ -37,3 +37,14 @@ func GetReg[T reg](reg *T) T {
 func SetReg[T reg](reg *T, value T) {
 	C.SetReg((*C.ushort)(unsafe.Pointer(reg)), C.ushort(value))
 }
+
+// GetReg32 returns the volatile value of a 32 bit register
+func GetReg32[T reg32](reg *T) T {
+	v := C.GetReg32((*C.uint)(unsafe.Pointer(reg)))
+	return T(v)
+}
+
+// SetReg32 sets the value of a 32 bit volitile register
+func SetReg32[T reg32](reg *T, value T) {
+	C.SetReg32((*C.uint)(unsafe.Pointer(reg)), C.uint(value))
+}
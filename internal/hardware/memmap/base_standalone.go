@@ -49,3 +49,15 @@ func GetReg[T reg](reg *T) T {
 func SetReg[T reg](reg *T, value T) {
 	*reg = value
 }
+
+// GetReg32 replaces GetReg32 from base.go, this is because durring emulation,
+// volitile memory access is not nessiary
+func GetReg32[T reg32](reg *T) T {
+	return *reg
+}
+
+// SetReg32 replaces SetReg32 form base.go this is because durring emulation,
+// volitile memory access is not nessisary
+func SetReg32[T reg32](reg *T, value T) {
+	*reg = value
+}
@@ -60,19 +60,6 @@ type values interface {
 	PaletteValue | VRAMValue | OAMValue
 }
 
-// Copy16 coppies data from the source to the destination in 16 bit chunks
-func Copy16[T values](dest []T, src []byte) {
-	ptr := (*T)(unsafe.Pointer(&src[0]))
-	src16 := unsafe.Slice(ptr, len(src)/2)
-
-	for i := range src16 {
-		if len(dest) <= i {
-			return
-		}
-		dest[i] = src16[i]
-	}
-}
-
 // Loads16 loads data from an embedded file into memory using the provided buffer
 // the buffer should be less than 256kb to prevent compilation failures due to overflowing
 // internal ram. Sizes less thean 32kb may lead to faster loading times as the buffer will fit
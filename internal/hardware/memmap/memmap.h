@@ -9,3 +9,15 @@ volatile unsigned short GetReg(unsigned short* reg) {
 void SetReg(unsigned short* reg, unsigned short value) {
     REG(reg) = value;
 }
+
+#define REG32(reg) *((volatile unsigned int*) (reg))
+
+// GetReg32 returns the volitile value of a 32 bit register
+volatile unsigned int GetReg32(unsigned int* reg) {
+    return REG32(reg);
+}
+
+// SetReg32 sets the value of a 32 bit volitile register
+void SetReg32(unsigned int* reg, unsigned int value) {
+    REG32(reg) = value;
+}
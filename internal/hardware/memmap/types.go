@@ -5,17 +5,51 @@ package memmap
 type reg interface {
 	uint16 |
 		AudioStat | DSControll |
+		DMGControll | ToneSweep | SoundEnvelope | SoundFreq | WaveControll | WaveVolume | NoiseFreq |
 		DisplayControll | DisplayStat | BGControll | DisplayVCount |
+		BlendControll | BlendAlpha | BlendY |
+		WindowH | WindowV | WindowControll | MosaicSize |
 		Input | InputControll |
+		Interrupt | InterruptMaster |
+		DMACount | DMAControll |
+		TimerCounter | TimerControll |
 		WaitControll
 }
 
+type reg32 interface {
+	uint32 | DMAAddress | SoundFIFO
+}
+
 // AudioStat is the type used for the audio stats register. See audio.Stat for more information on using this type
 type AudioStat uint16
 
 // DSControll is the type used for the direct sound controll register. See audio.DSControll for more infromation on using this type
 type DSControll uint16
 
+// DMGControll is the type used for the DMG master controll register. See audio.DMGControll for more information on using this type
+type DMGControll uint16
+
+// ToneSweep is the type used for the sound 1 sweep register. See audio.Sound1Sweep for more information on using this type
+type ToneSweep uint16
+
+// SoundEnvelope is the type used for the DMG length, duty and envelope registers. See audio.Sound2Envelope for more information on using this type
+type SoundEnvelope uint16
+
+// SoundFreq is the type used for the DMG frequency registers. See audio.Sound2Freq for more information on using this type
+type SoundFreq uint16
+
+// WaveControll is the type used for the wave channel controll register. See audio.Sound3Controll for more information on using this type
+type WaveControll uint16
+
+// WaveVolume is the type used for the wave channel length and volume register. See audio.Sound3Volume for more information on using this type
+type WaveVolume uint16
+
+// NoiseFreq is the type used for the noise channel frequency register. See audio.Sound4Freq for more information on using this type
+type NoiseFreq uint16
+
+// SoundFIFO is the type used for the direct sound FIFO registers. See audio.FIFOA for more information on using this type
+type SoundFIFO uint32
+
 // DisplayControll is the type used for the display controll register, see display.Controll for more information on useing this type
 type DisplayControll uint16
 
@@ -28,11 +62,53 @@ type DisplayVCount uint16
 // BGControll is the type used for the background controll registers, see display.BG#Controll for more information on using this type
 type BGControll uint16
 
+// BlendControll is the type used for the color special effects controll register, see display.BlendControll for more information on using this type
+type BlendControll uint16
+
+// BlendAlpha is the type used for the alpha blending coefficient register, see display.BlendAlpha for more information on using this type
+type BlendAlpha uint16
+
+// BlendY is the type used for the brightness coefficient register, see display.BlendY for more information on using this type
+type BlendY uint16
+
+// WindowH is the type used for the horizontal window bounds registers, see display.Win0H for more information on using this type
+type WindowH uint16
+
+// WindowV is the type used for the vertical window bounds registers, see display.Win0V for more information on using this type
+type WindowV uint16
+
+// WindowControll is the type used for the window controll registers, see display.WinIn for more information on using this type
+type WindowControll uint16
+
+// MosaicSize is the type used for the mosaic size register, see display.MosaicSize for more information on using this type
+type MosaicSize uint16
+
 // Input is the type used for the input register, see key.Input for more information on using this type
 type Input uint16
 
 // InputControll is the type used for the input register, see key.Controll for more information on using this type
 type InputControll uint16
 
+// Interrupt is the type used for the interrupt enable and request registers, see interrupt.Enable for more information on using this type
+type Interrupt uint16
+
+// InterruptMaster is the type used for the interrupt master enable register, see interrupt.Master for more information on using this type
+type InterruptMaster uint16
+
+// DMAAddress is the type used for the DMA source and destination registers, see dma.Source3 for more information on using this type
+type DMAAddress uint32
+
+// DMACount is the type used for the DMA count registers, see dma.Count3 for more information on using this type
+type DMACount uint16
+
+// DMAControll is the type used for the DMA controll registers, see dma.Controll3 for more information on using this type
+type DMAControll uint16
+
+// TimerCounter is the type used for the timer counter registers, see timer.Counter0 for more information on using this type
+type TimerCounter uint16
+
+// TimerControll is the type used for the timer controll registers, see timer.Controll0 for more information on using this type
+type TimerControll uint16
+
 // WaitControll is the type used for the system controll wait state register
 type WaitControll uint16
@@ -78,7 +78,7 @@ var OAM = unsafe.Slice(oamStart, 128)
 // AffineOAM contains all the affine sprite data, it can hold up to 32 affine sprite attributes,
 // note that the affine sprite index must be set using the regular sprite data
 var affineOAMStart = (*AffineAttrs)(unsafe.Pointer(memmap.OAMAddr))
-var AffineOAM = unsafe.Slice(oamStart, 32)
+var AffineOAM = unsafe.Slice(affineOAMStart, 32)
 
 type (
 	// Attr0 is the type of the first attribute in the Attrs struct
@@ -236,6 +236,9 @@ const (
 
 	// SpriteModeMask masks out all the bits from Attr0 that are not part of the sprite mode
 	SpriteModeMask Attr0 = 0x0300
+
+	// EffectMask masks out all the bits from Attr0 that are not part of the sprite effect
+	EffectMask Attr0 = 0x0C00
 )
 
 const (
@@ -263,6 +266,9 @@ const (
 	// AffineIndexMask masks out all the bits from Attr1 that are not part of the affine index
 	AffineIndexMask Attr1 = 0x3E00
 
+	// AffineIndexShift is the offset of the affine index in Attr1
+	AffineIndexShift Attr1 = 0x0009
+
 	// SizeMask masks out all the bits from Attr1 that are not part of the sprite size
 	SizeMask Attr1 = 0xC000
 
@@ -302,6 +308,35 @@ const (
 	PriorityShift Attr2 = 0x000A
 )
 
+// Dimensions returns the width and height in pixels of a sprite with the given shape and size.
+// Shape 3 is prohibited on the GBA so 0, 0 is returned for it
+func Dimensions(shape Attr0, size Attr1) (int, int) {
+	if shape&ShapeMask == ShapeMask {
+		return 0, 0
+	}
+
+	dimensions := [...][2]int{
+		{8, 8},
+		{16, 8},
+		{8, 16},
+
+		{16, 16},
+		{32, 8},
+		{8, 32},
+
+		{32, 32},
+		{32, 16},
+		{16, 32},
+
+		{64, 64},
+		{64, 32},
+		{32, 64},
+	}
+
+	i := int(size&SizeMask)>>0xE*3 + int(shape&ShapeMask)>>0xE
+	return dimensions[i][0], dimensions[i][1]
+}
+
 // AffineAttrs is the structure sprite OAM affine attributes, it maps the sprites pixels from screen space to the sprites pixel space
 //
 // [ Pa, Pb ] = [ Sx*cos(alpha),  Sy*sin(alpha) ]